@@ -352,6 +352,12 @@ func initCommands(
 			}, nil
 		},
 
+		"workspace migrate-preview": func() (cli.Command, error) {
+			return &command.WorkspaceMigratePreviewCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		//-----------------------------------------------------------
 		// Plumbing
 		//-----------------------------------------------------------
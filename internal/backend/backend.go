@@ -104,3 +104,108 @@ type Backend interface {
 	// in this backend.
 	Workspaces() ([]string, error)
 }
+
+// StateSizeLimiter is an optional interface for backends that enforce an
+// upper limit on the serialized size of a state snapshot, for example due to
+// a request size limit on a remote service.
+//
+// Backends that don't implement this interface, or that return zero from
+// StateSizeLimit, are assumed to accept snapshots of any size.
+type StateSizeLimiter interface {
+	Backend
+
+	// StateSizeLimit returns the maximum serialized state size in bytes
+	// this backend will accept, or zero if there is no limit.
+	StateSizeLimit() int64
+}
+
+// WorkspaceCountLimiter is an optional interface for backends that can report
+// an upper limit on the number of workspaces they are able to store, for
+// example due to a plan or quota on a remote service.
+//
+// Backends that don't implement this interface, or that return zero from
+// WorkspaceLimit, are assumed to have no particular limit.
+type WorkspaceCountLimiter interface {
+	Backend
+
+	// WorkspaceLimit returns the maximum number of workspaces this backend
+	// can store, or zero if there is no limit.
+	WorkspaceLimit() int
+}
+
+// WorkspaceNameValidator is an optional interface for backends that enforce
+// naming rules on workspaces, for example to match the constraints of a
+// remote service. Callers can check a proposed name before attempting to
+// create or copy a workspace under it.
+//
+// Backends that don't implement this interface are assumed to accept any
+// workspace name.
+type WorkspaceNameValidator interface {
+	Backend
+
+	// ValidateWorkspaceName returns an error describing why the given name
+	// is not an acceptable workspace name, or nil if it is acceptable.
+	ValidateWorkspaceName(name string) error
+}
+
+// WorkspaceVariable describes one variable attached to a workspace in a
+// backend that stores variables alongside state, such as HCP Terraform.
+// Sensitive variables can never be read back from such a backend, so Value
+// is empty for them.
+type WorkspaceVariable struct {
+	Key         string
+	Value       string
+	Description string
+	Category    string // "terraform" or "env"
+	HCL         bool
+	Sensitive   bool
+}
+
+// WorkspaceVariableLister is an optional interface for backends that can
+// report the variables attached to a workspace.
+type WorkspaceVariableLister interface {
+	Backend
+
+	// ListWorkspaceVariables returns the variables attached to the named
+	// workspace. Sensitive variables are returned with an empty Value.
+	ListWorkspaceVariables(name string) ([]WorkspaceVariable, error)
+}
+
+// WorkspaceVariableWriter is an optional interface for backends that can
+// attach variables to a workspace.
+type WorkspaceVariableWriter interface {
+	Backend
+
+	// WriteWorkspaceVariables creates the given variables on the named
+	// workspace, which must already exist.
+	WriteWorkspaceVariables(name string, variables []WorkspaceVariable) error
+}
+
+// WorkspacesInfo describes a backend's workspace support as reported by
+// ListWorkspaces: either the backend holds exactly one unnamed state, or it
+// supports named workspaces and Workspaces lists the ones that exist.
+type WorkspacesInfo struct {
+	// SingleState is true if the backend doesn't support named workspaces
+	// and so holds only a single state. Workspaces is empty in that case.
+	SingleState bool
+
+	// Workspaces lists the names of the workspaces that exist in the
+	// backend, when SingleState is false.
+	Workspaces []string
+}
+
+// ListWorkspaces asks the given backend for its workspaces, normalizing the
+// common ErrWorkspacesNotSupported convention: a backend that holds only a
+// single state is reported as such rather than as an error, so callers
+// don't each need to compare against that sentinel themselves. Any other
+// error from the backend is returned as-is.
+func ListWorkspaces(b Backend) (WorkspacesInfo, error) {
+	workspaces, err := b.Workspaces()
+	if err == ErrWorkspacesNotSupported {
+		return WorkspacesInfo{SingleState: true}, nil
+	}
+	if err != nil {
+		return WorkspacesInfo{}, err
+	}
+	return WorkspacesInfo{Workspaces: workspaces}, nil
+}
@@ -608,6 +608,43 @@ func (b *Remote) WorkspaceNamePattern() string {
 	return ""
 }
 
+// ListWorkspaceVariables implements backend.WorkspaceVariableLister, so a
+// migration away from this backend can carry workspace variables along with
+// the state. The API never returns the value of a sensitive variable, so
+// those come back with an empty Value.
+func (b *Remote) ListWorkspaceVariables(name string) ([]backend.WorkspaceVariable, error) {
+	ctx := context.Background()
+	workspace, err := b.client.Workspaces.Read(ctx, b.organization, b.getRemoteWorkspaceName(name))
+	if err != nil {
+		return nil, fmt.Errorf("error reading workspace %s: %v", name, err)
+	}
+
+	options := &tfe.VariableListOptions{}
+	var variables []backend.WorkspaceVariable
+	for {
+		vl, err := b.client.Variables.List(ctx, workspace.ID, options)
+		if err != nil {
+			return nil, fmt.Errorf("error listing variables for workspace %s: %v", name, err)
+		}
+		for _, v := range vl.Items {
+			variables = append(variables, backend.WorkspaceVariable{
+				Key:         v.Key,
+				Value:       v.Value,
+				Description: v.Description,
+				Category:    string(v.Category),
+				HCL:         v.HCL,
+				Sensitive:   v.Sensitive,
+			})
+		}
+		if vl.CurrentPage >= vl.TotalPages {
+			break
+		}
+		options.PageNumber = vl.NextPage
+	}
+
+	return variables, nil
+}
+
 // DeleteWorkspace implements backend.Enhanced.
 func (b *Remote) DeleteWorkspace(name string, _ bool) error {
 	if b.workspace == "" && name == backend.DefaultStateName {
@@ -1019,6 +1056,23 @@ func (b *Remote) VerifyWorkspaceTerraformVersion(workspaceName string) tfdiags.D
 	return diags
 }
 
+// WorkspaceTerraformVersion returns the Terraform version the named
+// workspace is pinned to, or an empty string when the workspace doesn't
+// exist or uses the "latest" pseudo-version.
+func (b *Remote) WorkspaceTerraformVersion(workspaceName string) (string, error) {
+	workspace, err := b.getRemoteWorkspace(context.Background(), workspaceName)
+	if err != nil {
+		if err == tfe.ErrResourceNotFound {
+			return "", nil
+		}
+		return "", err
+	}
+	if workspace.TerraformVersion == "latest" {
+		return "", nil
+	}
+	return workspace.TerraformVersion, nil
+}
+
 func (b *Remote) IsLocalOperations() bool {
 	return b.forceLocal
 }
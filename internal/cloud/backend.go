@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
@@ -119,6 +120,9 @@ type Cloud struct {
 }
 
 var _ backend.Backend = (*Cloud)(nil)
+var _ backend.WorkspaceNameValidator = (*Cloud)(nil)
+var _ backend.WorkspaceVariableLister = (*Cloud)(nil)
+var _ backend.WorkspaceVariableWriter = (*Cloud)(nil)
 var _ backendrun.OperationsBackend = (*Cloud)(nil)
 var _ backendrun.Local = (*Cloud)(nil)
 
@@ -430,6 +434,20 @@ func (b *Cloud) Configure(obj cty.Value) tfdiags.Diagnostics {
 	return diags
 }
 
+// validWorkspaceName matches the workspace names HCP Terraform and Terraform
+// Enterprise will accept.
+var validWorkspaceName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ValidateWorkspaceName implements backend.WorkspaceNameValidator, enforcing
+// the naming rules of HCP Terraform and Terraform Enterprise so that callers
+// can reject a bad name before trying to create a workspace under it.
+func (b *Cloud) ValidateWorkspaceName(name string) error {
+	if !validWorkspaceName.MatchString(name) {
+		return fmt.Errorf("workspace names may contain only letters, numbers, dashes, and underscores")
+	}
+	return nil
+}
+
 func (b *Cloud) AppName() string {
 	if isValidAppName(b.appName) {
 		return b.appName
@@ -1086,6 +1104,23 @@ func (b *Cloud) VerifyWorkspaceTerraformVersion(workspaceName string) tfdiags.Di
 	return diags
 }
 
+// WorkspaceTerraformVersion returns the Terraform version the named
+// workspace is pinned to, or an empty string when the workspace doesn't
+// exist or uses the "latest" pseudo-version.
+func (b *Cloud) WorkspaceTerraformVersion(workspaceName string) (string, error) {
+	workspace, err := b.getRemoteWorkspace(context.Background(), workspaceName)
+	if err != nil {
+		if err == tfe.ErrResourceNotFound {
+			return "", nil
+		}
+		return "", err
+	}
+	if workspace.TerraformVersion == "latest" {
+		return "", nil
+	}
+	return workspace.TerraformVersion, nil
+}
+
 func (b *Cloud) IsLocalOperations() bool {
 	return b.forceLocal
 }
@@ -1127,6 +1162,99 @@ func (b *Cloud) workspaceTagsRequireUpdate(workspace *tfe.Workspace, workspaceMa
 	return false
 }
 
+// EnsureWorkspaceTags makes sure the named workspace carries every tag from
+// the configured workspace mapping, attaching any that are missing, and
+// returns the tags the mapping requires. When the mapping doesn't use the
+// tags strategy there is nothing to attach and the returned list is empty.
+func (b *Cloud) EnsureWorkspaceTags(name string) ([]string, error) {
+	if b.WorkspaceMapping.Strategy() != WorkspaceTagsStrategy {
+		return nil, nil
+	}
+
+	workspace, err := b.client.Workspaces.Read(context.Background(), b.Organization, name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading workspace %s: %v", name, err)
+	}
+
+	if b.workspaceTagsRequireUpdate(workspace, b.WorkspaceMapping) {
+		options := tfe.WorkspaceAddTagsOptions{
+			Tags: b.WorkspaceMapping.tfeTags(),
+		}
+		log.Printf("[TRACE] cloud: Adding tags for %s workspace %s/%s", b.appName, b.Organization, name)
+		if err := b.client.Workspaces.AddTags(context.Background(), workspace.ID, options); err != nil {
+			return nil, fmt.Errorf("error tagging workspace %s: %v", name, err)
+		}
+	}
+
+	return b.WorkspaceMapping.Tags, nil
+}
+
+// ListWorkspaceVariables implements backend.WorkspaceVariableLister,
+// returning the variables attached to the named workspace. The API never
+// returns the value of a sensitive variable, so those come back with an
+// empty Value.
+func (b *Cloud) ListWorkspaceVariables(name string) ([]backend.WorkspaceVariable, error) {
+	ctx := context.Background()
+	workspace, err := b.client.Workspaces.Read(ctx, b.Organization, name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading workspace %s: %v", name, err)
+	}
+
+	options := &tfe.VariableListOptions{}
+	var variables []backend.WorkspaceVariable
+	for {
+		vl, err := b.client.Variables.List(ctx, workspace.ID, options)
+		if err != nil {
+			return nil, fmt.Errorf("error listing variables for workspace %s: %v", name, err)
+		}
+		for _, v := range vl.Items {
+			variables = append(variables, backend.WorkspaceVariable{
+				Key:         v.Key,
+				Value:       v.Value,
+				Description: v.Description,
+				Category:    string(v.Category),
+				HCL:         v.HCL,
+				Sensitive:   v.Sensitive,
+			})
+		}
+		if vl.CurrentPage >= vl.TotalPages {
+			break
+		}
+		options.PageNumber = vl.NextPage
+	}
+
+	return variables, nil
+}
+
+// WriteWorkspaceVariables implements backend.WorkspaceVariableWriter,
+// creating the given variables on the named workspace, which must already
+// exist.
+func (b *Cloud) WriteWorkspaceVariables(name string, variables []backend.WorkspaceVariable) error {
+	ctx := context.Background()
+	workspace, err := b.client.Workspaces.Read(ctx, b.Organization, name)
+	if err != nil {
+		return fmt.Errorf("error reading workspace %s: %v", name, err)
+	}
+
+	for _, v := range variables {
+		options := tfe.VariableCreateOptions{
+			Key:       tfe.String(v.Key),
+			Value:     tfe.String(v.Value),
+			Category:  tfe.Category(tfe.CategoryType(v.Category)),
+			HCL:       tfe.Bool(v.HCL),
+			Sensitive: tfe.Bool(v.Sensitive),
+		}
+		if v.Description != "" {
+			options.Description = tfe.String(v.Description)
+		}
+		if _, err := b.client.Variables.Create(ctx, workspace.ID, options); err != nil {
+			return fmt.Errorf("error creating variable %s on workspace %s: %v", v.Key, name, err)
+		}
+	}
+
+	return nil
+}
+
 type WorkspaceMapping struct {
 	Name    string
 	Project string
@@ -4,6 +4,9 @@
 package arguments
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform/internal/tfdiags"
@@ -37,6 +40,11 @@ type Init struct {
 	// ForceInitCopy specifies whether to suppress prompts about copying state data.
 	ForceInitCopy bool
 
+	// ForceInitCopyEmpty suppresses the copy prompt only when the destination
+	// workspace has no existing state, so overwrites still require
+	// confirmation.
+	ForceInitCopyEmpty bool
+
 	// StateLock specifies whether hold a state lock during backend migration.
 	StateLock bool
 
@@ -49,6 +57,203 @@ type Init struct {
 	// MigrateState specifies whether to attempt to copy existing state to the new backend
 	MigrateState bool
 
+	// MigrateProvenance specifies whether to record a provenance annotation
+	// in the destination state when migrating, where the destination supports
+	// state metadata
+	MigrateProvenance bool
+
+	// MigrateMinFormatVersion, if non-zero, aborts state migration when any
+	// source workspace holds a state snapshot with a format version below
+	// the given threshold
+	MigrateMinFormatVersion int
+
+	// MigrateDebug specifies whether to print a one-shot report of the
+	// decisions made while preparing a state migration
+	MigrateDebug bool
+
+	// MigratePruneDeposed specifies whether to drop deposed resource instance
+	// objects from the destination state while migrating, rather than
+	// carrying them forward
+	MigratePruneDeposed bool
+
+	// MigrateWorkspaceSuffix, if non-empty, is appended to destination
+	// workspace names that would otherwise overwrite an existing destination
+	// workspace during a multi-workspace migration
+	MigrateWorkspaceSuffix string
+
+	// MigrateWorkspaceLimit, if non-zero, aborts a multi-workspace migration
+	// that would leave the destination with more than the given number of
+	// workspaces
+	MigrateWorkspaceLimit int
+
+	// MigrateInteractiveSelect specifies whether to explain the detected
+	// migration path and let the user choose the migration mode explicitly
+	// when more than one mode is possible
+	MigrateInteractiveSelect bool
+
+	// MigrateDiff is retained for compatibility now that the plan-style diff
+	// of the source and destination states renders by default before asking
+	// whether to overwrite a non-empty destination during state migration
+	MigrateDiff bool
+
+	// MigrateNoDiff suppresses that diff, leaving only the temporary state
+	// files to inspect by hand
+	MigrateNoDiff bool
+
+	// MigrateMaxStateSize, if non-zero, aborts state migration when any
+	// source workspace's state serializes to more than the given number of
+	// bytes
+	MigrateMaxStateSize int64
+
+	// MigrateWorkspaceRenameCmd, if non-empty, names an external program that
+	// computes the destination workspace name for each source workspace
+	// during a multi-workspace migration
+	MigrateWorkspaceRenameCmd string
+
+	// MigrateCurrentOnly specifies whether a multi-workspace migration should
+	// copy only the currently selected workspace, leaving the other source
+	// workspaces behind
+	MigrateCurrentOnly bool
+
+	// MigrateSkipIfSynced specifies whether to skip state migration entirely
+	// when every source workspace already has matching state in the
+	// destination, judged cheaply by state snapshot metadata
+	MigrateSkipIfSynced bool
+
+	// MigrateProviderRenames holds old=new provider source address mappings
+	// applied to each state while migrating, so that states referencing a
+	// renamed provider come out referencing the new address
+	MigrateProviderRenames []string
+
+	// MigrateConfirmAll specifies whether a multi-workspace migration should
+	// present every pending action as a single numbered list and ask for one
+	// approval covering all of them, instead of per-scenario prompts
+	MigrateConfirmAll bool
+
+	// MigrateWorkspaces, if non-empty, restricts a multi-workspace migration
+	// to copying only the named workspaces
+	MigrateWorkspaces []string
+
+	// MigrateDryRun specifies whether to report what a state migration would
+	// do without writing anything to the destination
+	MigrateDryRun bool
+
+	// MigrateSummaryPath specifies where to write a machine-readable JSON
+	// summary of the migration; "-" means stdout
+	MigrateSummaryPath string
+
+	// MigrateTempDir specifies the directory in which to create the
+	// temporary state copies written for migration confirmation, instead of
+	// the system default temporary directory
+	MigrateTempDir string
+
+	// MigrateRedactTemp masks values recorded as sensitive in the temporary
+	// state copies written for migration confirmation
+	MigrateRedactTemp bool
+
+	// MigrateNewLineage assigns a freshly generated lineage to each migrated
+	// destination state instead of inheriting the source's, so the
+	// destination's history is decoupled from the source; re-running the
+	// same migration will then no longer be recognized as identical
+	MigrateNewLineage bool
+
+	// MigrateParallelism specifies how many workspaces a multi-workspace
+	// migration may copy concurrently
+	MigrateParallelism int
+
+	// MigrateContinueOnError specifies whether a multi-workspace migration
+	// keeps copying the remaining workspaces when one of them fails
+	MigrateContinueOnError bool
+
+	// MigrateVerify specifies whether to re-read each workspace from the
+	// destination after it is persisted and check the copy round-tripped
+	MigrateVerify bool
+
+	// MigrateWorkspaceFilter is a regular expression that source workspace
+	// names must match to be migrated; nil migrates all of them
+	MigrateWorkspaceFilter *regexp.Regexp
+
+	// MigrateWorkspaceMapPath names a JSON file mapping source workspace
+	// names to explicit destination names for a migration to HCP Terraform
+	MigrateWorkspaceMapPath string
+
+	// MigrateManifestPath names a JSON manifest enumerating every source
+	// workspace with its destination and an optional per-workspace conflict
+	// policy, suppressing all interactive migration prompts
+	MigrateManifestPath string
+
+	// MigrateBackupDir names a local directory that receives a backup of each
+	// destination workspace's pre-existing state before it is overwritten
+	MigrateBackupDir string
+
+	// MigrateVariables specifies whether to also copy workspace variables
+	// during a migration between backends that store variables remotely
+	MigrateVariables bool
+
+	// MigrateTimeout, when positive, bounds the wall-clock duration of the
+	// whole state migration, cancelling in-flight operations when exceeded
+	MigrateTimeout time.Duration
+
+	// MigrateSourceLockTimeout, when positive, overrides the state lock
+	// timeout for the migration source backend only
+	MigrateSourceLockTimeout time.Duration
+
+	// MigrateDestLockTimeout, when positive, overrides the state lock
+	// timeout for the migration destination backend only
+	MigrateDestLockTimeout time.Duration
+
+	// MigrateFromStatePath, if non-empty, names a loose local state file to
+	// migrate from instead of whatever backend the working directory was
+	// previously using
+	MigrateFromStatePath string
+
+	// MigratePreserveSerial specifies whether each destination workspace
+	// should end up with the same state serial as its source, rewriting the
+	// serial after the copy where the destination supports it
+	MigratePreserveSerial bool
+
+	// MigrateSkipEmpty specifies whether to scan all source workspaces for
+	// empty state up front and skip the empty ones as a single announced
+	// group, instead of discovering them one at a time while copying
+	MigrateSkipEmpty bool
+
+	// MigrateCheckpointPath, if non-empty, names a JSON file recording
+	// multi-workspace migration progress, so an interrupted migration can be
+	// resumed without recopying completed workspaces
+	MigrateCheckpointPath string
+
+	// MigrateCloudProject, if non-empty, names the HCP Terraform project
+	// that workspaces created during the migration are placed in, instead of
+	// the organization's default project
+	MigrateCloudProject string
+
+	// MigrateStrictVersion specifies whether to abort the migration, rather
+	// than warn, when a destination workspace requires a newer Terraform
+	// version than this CLI
+	MigrateStrictVersion bool
+
+	// MigrateDeleteSource specifies whether to delete each migrated workspace
+	// from the source backend once the whole migration has succeeded
+	MigrateDeleteSource bool
+
+	// MigrateConflict resolves a non-empty destination workspace without
+	// prompting: "newest-serial", "source-wins", or "abort". Empty keeps the
+	// interactive confirmation
+	MigrateConflict string
+
+	// MigrateWorkspacePattern is the destination workspace rename pattern for
+	// a migration to HCP Terraform, supplied instead of the interactive
+	// prompt; it must contain exactly one '*'
+	MigrateWorkspacePattern string
+
+	// MigrateDestinationWorkspace names the destination workspace for a
+	// single-state migration instead of the default workspace
+	MigrateDestinationWorkspace string
+
+	// MigrateRetries is how many extra attempts a transient destination
+	// error gets during state migration, with exponential backoff
+	MigrateRetries int
+
 	// Upgrade specifies whether to upgrade modules and plugins as part of their respective installation steps
 	Upgrade bool
 
@@ -58,6 +263,11 @@ type Init struct {
 	// IgnoreRemoteVersion specifies whether to ignore remote and local Terraform versions compatibility
 	IgnoreRemoteVersion bool
 
+	// IgnoreRemoteVersionFor lists destination workspace names whose remote
+	// Terraform version check should be skipped during state migration,
+	// while the check stays enforced for every other workspace.
+	IgnoreRemoteVersionFor []string
+
 	BackendConfig FlagNameValueSlice
 
 	Vars *Vars
@@ -95,10 +305,55 @@ func ParseInit(args []string) (*Init, tfdiags.Diagnostics) {
 	cmdFlags.StringVar(&init.FromModule, "from-module", "", "copy the source of the given module into the directory before init")
 	cmdFlags.BoolVar(&init.Get, "get", true, "")
 	cmdFlags.BoolVar(&init.ForceInitCopy, "force-copy", false, "suppress prompts about copying state data")
+	cmdFlags.BoolVar(&init.ForceInitCopyEmpty, "force-copy-empty", false, "suppress copy prompts only for destinations without existing state")
 	cmdFlags.BoolVar(&init.StateLock, "lock", true, "lock state")
 	cmdFlags.DurationVar(&init.StateLockTimeout, "lock-timeout", 0, "lock timeout")
 	cmdFlags.BoolVar(&init.Reconfigure, "reconfigure", false, "reconfigure")
 	cmdFlags.BoolVar(&init.MigrateState, "migrate-state", false, "migrate state")
+	cmdFlags.BoolVar(&init.MigrateProvenance, "migrate-provenance", false, "record provenance annotation during state migration")
+	cmdFlags.IntVar(&init.MigrateMinFormatVersion, "migrate-min-format-version", 0, "abort state migration if a source state is below this format version")
+	cmdFlags.BoolVar(&init.MigrateDebug, "migrate-debug", false, "print a report of state migration decisions")
+	cmdFlags.BoolVar(&init.MigratePruneDeposed, "migrate-prune-deposed", false, "drop deposed objects from the destination state during state migration")
+	cmdFlags.StringVar(&init.MigrateWorkspaceSuffix, "migrate-workspace-suffix", "", "suffix appended to colliding destination workspace names during state migration")
+	cmdFlags.IntVar(&init.MigrateWorkspaceLimit, "migrate-workspace-limit", 0, "abort state migration if the destination would exceed this many workspaces")
+	cmdFlags.BoolVar(&init.MigrateInteractiveSelect, "migrate-interactive-select", false, "choose the state migration mode interactively when more than one is possible")
+	cmdFlags.BoolVar(&init.MigrateDiff, "migrate-diff", false, "render a diff of the source and destination states before overwriting during state migration (now the default)")
+	cmdFlags.BoolVar(&init.MigrateNoDiff, "migrate-no-diff", false, "suppress the diff of the source and destination states before overwriting during state migration")
+	cmdFlags.Int64Var(&init.MigrateMaxStateSize, "migrate-max-state-size", 0, "abort state migration if a source state serializes to more than this many bytes")
+	cmdFlags.StringVar(&init.MigrateWorkspaceRenameCmd, "migrate-workspace-rename-cmd", "", "external program that maps source workspace names to destination workspace names during state migration")
+	cmdFlags.BoolVar(&init.MigrateCurrentOnly, "migrate-current-only", false, "migrate only the current workspace during a multi-workspace state migration")
+	cmdFlags.BoolVar(&init.MigrateSkipIfSynced, "migrate-skip-if-synced", false, "skip state migration when the destination already has matching state for every workspace")
+	cmdFlags.Var((*FlagStringSlice)(&init.MigrateProviderRenames), "migrate-provider-rename", "old=new provider source address mapping applied during state migration")
+	cmdFlags.BoolVar(&init.MigrateConfirmAll, "migrate-confirm-all", false, "list every pending state migration action and ask for a single approval")
+	migrateWorkspaces := cmdFlags.String("migrate-workspaces", "", "comma-separated workspace names to migrate, instead of all of them")
+	migrateWorkspaceFilter := cmdFlags.String("migrate-workspace-filter", "", "regular expression that source workspace names must match to be migrated")
+	cmdFlags.StringVar(&init.MigrateWorkspaceMapPath, "migrate-workspace-map", "", "JSON file mapping source workspace names to destination names")
+	cmdFlags.StringVar(&init.MigrateManifestPath, "migrate-manifest", "", "JSON manifest describing the full migration plan upfront")
+	cmdFlags.StringVar(&init.MigrateBackupDir, "migrate-backup-dir", "", "directory for backups of overwritten destination states")
+	cmdFlags.BoolVar(&init.MigrateVariables, "migrate-variables", false, "also copy workspace variables when both backends store them")
+	cmdFlags.DurationVar(&init.MigrateTimeout, "migrate-timeout", 0, "overall deadline for the state migration, e.g. 30m")
+	cmdFlags.DurationVar(&init.MigrateSourceLockTimeout, "migrate-source-lock-timeout", 0, "lock timeout for the migration source backend")
+	cmdFlags.DurationVar(&init.MigrateDestLockTimeout, "migrate-dest-lock-timeout", 0, "lock timeout for the migration destination backend")
+	cmdFlags.StringVar(&init.MigrateFromStatePath, "migrate-from-state", "", "migrate from a loose local state file instead of the previous backend")
+	cmdFlags.BoolVar(&init.MigratePreserveSerial, "migrate-preserve-serial", false, "preserve each source workspace's state serial in the destination")
+	cmdFlags.BoolVar(&init.MigrateSkipEmpty, "migrate-skip-empty", false, "scan for and skip empty source workspaces as a group before copying")
+	cmdFlags.StringVar(&init.MigrateCheckpointPath, "migrate-checkpoint", "", "JSON file recording migration progress, for resuming an interrupted migration")
+	cmdFlags.StringVar(&init.MigrateCloudProject, "migrate-cloud-project", "", "HCP Terraform project to place workspaces created during the migration in")
+	cmdFlags.BoolVar(&init.MigrateStrictVersion, "migrate-strict-version", false, "abort instead of warning when a destination workspace requires a newer Terraform version")
+	cmdFlags.BoolVar(&init.MigrateDeleteSource, "migrate-delete-source", false, "delete migrated workspaces from the source backend after a successful migration")
+	cmdFlags.BoolVar(&init.MigrateDryRun, "migrate-dry-run", false, "report what a state migration would do without writing anything")
+	cmdFlags.StringVar(&init.MigrateSummaryPath, "migrate-summary", "", "write a JSON summary of the migration to the given file, or stdout for \"-\"")
+	cmdFlags.StringVar(&init.MigrateTempDir, "migrate-temp-dir", "", "directory for the temporary state copies written for migration confirmation")
+	cmdFlags.BoolVar(&init.MigrateRedactTemp, "migrate-redact-temp", false, "mask sensitive values in the temporary state copies written for migration confirmation")
+	cmdFlags.BoolVar(&init.MigrateNewLineage, "migrate-new-lineage", false, "assign a new lineage to the migrated destination state instead of inheriting the source's")
+	cmdFlags.IntVar(&init.MigrateParallelism, "migrate-parallelism", 1, "number of workspaces to migrate concurrently")
+	cmdFlags.BoolVar(&init.MigrateContinueOnError, "migrate-continue-on-error", false, "keep migrating the remaining workspaces when one fails")
+	cmdFlags.BoolVar(&init.MigrateVerify, "migrate-verify", false, "re-read each migrated workspace from the destination to verify the copy")
+	cmdFlags.StringVar(&init.MigrateConflict, "migrate-conflict", "", "how to resolve a non-empty destination workspace without prompting")
+	cmdFlags.StringVar(&init.MigrateWorkspacePattern, "migrate-workspace-pattern", "", "destination workspace rename pattern for a migration to HCP Terraform")
+	cmdFlags.StringVar(&init.MigrateDestinationWorkspace, "migrate-destination-workspace", "", "destination workspace name for a single-state migration")
+	cmdFlags.IntVar(&init.MigrateRetries, "migrate-retries", 0, "number of retries for transient destination errors during state migration")
+	ignoreRemoteVersionFor := cmdFlags.String("ignore-remote-version-for", "", "comma-separated workspace names whose remote version check is skipped during state migration")
 	cmdFlags.BoolVar(&init.Upgrade, "upgrade", false, "")
 	cmdFlags.StringVar(&init.Lockfile, "lockfile", "", "Set a dependency lockfile mode")
 	cmdFlags.BoolVar(&init.IgnoreRemoteVersion, "ignore-remote-version", false, "continue even if remote and local Terraform versions are incompatible")
@@ -123,6 +378,543 @@ func ParseInit(args []string) (*Init, tfdiags.Diagnostics) {
 		))
 	}
 
+	if init.ForceInitCopy && init.ForceInitCopyEmpty {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -force-copy option already suppresses every copy prompt, so it cannot be combined with -force-copy-empty.",
+		))
+	}
+
+	if init.MigrateProvenance && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-provenance option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateMinFormatVersion < 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-min-format-version option must be a positive state format version number.",
+		))
+	}
+
+	if init.MigrateMinFormatVersion > 0 && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-min-format-version option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateDebug && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-debug option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigratePruneDeposed && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-prune-deposed option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateWorkspaceSuffix != "" && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-workspace-suffix option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateWorkspaceLimit < 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-workspace-limit option must be a positive number of workspaces.",
+		))
+	}
+
+	if init.MigrateWorkspaceLimit > 0 && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-workspace-limit option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateInteractiveSelect && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-interactive-select option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateDiff && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-diff option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateNoDiff && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-no-diff option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateDiff && init.MigrateNoDiff {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-diff and -migrate-no-diff options are mutually-exclusive.",
+		))
+	}
+
+	if init.MigrateMaxStateSize < 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-max-state-size option must be a positive number of bytes.",
+		))
+	}
+
+	if init.MigrateMaxStateSize > 0 && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-max-state-size option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateWorkspaceRenameCmd != "" && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-workspace-rename-cmd option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateCurrentOnly && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-current-only option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateSkipIfSynced && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-skip-if-synced option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	for _, mapping := range init.MigrateProviderRenames {
+		if eq := strings.Index(mapping, "="); eq < 1 || eq == len(mapping)-1 {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid init options",
+				fmt.Sprintf("The -migrate-provider-rename option %q is not valid: each mapping must have the form old=new, where both sides are provider source addresses.", mapping),
+			))
+		}
+	}
+
+	if len(init.MigrateProviderRenames) > 0 && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-provider-rename option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if *migrateWorkspaces != "" {
+		for _, name := range strings.Split(*migrateWorkspaces, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid init options",
+					"The -migrate-workspaces option must be a comma-separated list of workspace names, with no empty entries.",
+				))
+				continue
+			}
+			init.MigrateWorkspaces = append(init.MigrateWorkspaces, name)
+		}
+	}
+
+	if len(init.MigrateWorkspaces) > 0 && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-workspaces option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if *migrateWorkspaceFilter != "" {
+		filter, err := regexp.Compile(*migrateWorkspaceFilter)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid init options",
+				fmt.Sprintf("The -migrate-workspace-filter option must be a valid regular expression: %s.", err),
+			))
+		} else {
+			init.MigrateWorkspaceFilter = filter
+		}
+	}
+
+	if init.MigrateWorkspaceFilter != nil && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-workspace-filter option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateDryRun && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-dry-run option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateSummaryPath != "" && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-summary option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateTempDir != "" && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-temp-dir option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateRedactTemp && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-redact-temp option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateNewLineage && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-new-lineage option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateNewLineage && init.MigrateSkipIfSynced {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-skip-if-synced option recognizes already-migrated workspaces by their lineage, which -migrate-new-lineage deliberately rewrites, so the two options cannot be combined.",
+		))
+	}
+
+	if init.MigrateParallelism != 1 && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-parallelism option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateParallelism < 1 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-parallelism option must be at least 1.",
+		))
+	}
+
+	if init.MigrateContinueOnError && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-continue-on-error option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateVerify && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-verify option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateWorkspaceMapPath != "" && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-workspace-map option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateManifestPath != "" && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-manifest option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateBackupDir != "" && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-backup-dir option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateVariables && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-variables option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateTimeout < 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-timeout option must be a positive duration.",
+		))
+	}
+
+	if init.MigrateTimeout > 0 && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-timeout option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateSourceLockTimeout < 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-source-lock-timeout option must be a positive duration.",
+		))
+	}
+
+	if init.MigrateSourceLockTimeout > 0 && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-source-lock-timeout option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateDestLockTimeout < 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-dest-lock-timeout option must be a positive duration.",
+		))
+	}
+
+	if init.MigrateDestLockTimeout > 0 && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-dest-lock-timeout option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigratePreserveSerial && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-preserve-serial option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateFromStatePath != "" && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-from-state option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateSkipEmpty && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-skip-empty option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateCheckpointPath != "" && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-checkpoint option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateCloudProject != "" && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-cloud-project option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateStrictVersion && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-strict-version option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateManifestPath != "" && init.MigrateWorkspaceMapPath != "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-manifest option already names every destination workspace, so it cannot be combined with -migrate-workspace-map.",
+		))
+	}
+
+	if init.MigrateDeleteSource && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-delete-source option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateConflict != "" {
+		switch init.MigrateConflict {
+		case "newest-serial", "source-wins", "abort":
+			// Accepted.
+		default:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid init options",
+				fmt.Sprintf("The -migrate-conflict option does not accept %q: it must be one of \"newest-serial\", \"source-wins\", or \"abort\".", init.MigrateConflict),
+			))
+		}
+		if !init.MigrateState && !init.ForceInitCopy {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid init options",
+				"The -migrate-conflict option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+			))
+		}
+	}
+
+	if init.MigrateWorkspacePattern != "" && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-workspace-pattern option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateDestinationWorkspace != "" && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-destination-workspace option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateRetries < 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-retries option must not be negative.",
+		))
+	}
+
+	if init.MigrateRetries > 0 && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-retries option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if *ignoreRemoteVersionFor != "" {
+		for _, name := range strings.Split(*ignoreRemoteVersionFor, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid init options",
+					"The -ignore-remote-version-for option must be a comma-separated list of workspace names, with no empty entries.",
+				))
+				continue
+			}
+			init.IgnoreRemoteVersionFor = append(init.IgnoreRemoteVersionFor, name)
+		}
+	}
+
+	if len(init.IgnoreRemoteVersionFor) > 0 && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -ignore-remote-version-for option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if len(init.IgnoreRemoteVersionFor) > 0 && init.IgnoreRemoteVersion {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -ignore-remote-version option already disables the remote version check everywhere, so it cannot be combined with -ignore-remote-version-for.",
+		))
+	}
+
+	if init.MigrateConfirmAll && !init.MigrateState && !init.ForceInitCopy {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-confirm-all option is only meaningful when migrating state, so it requires either -migrate-state or -force-copy.",
+		))
+	}
+
+	if init.MigrateConfirmAll && !init.InputEnabled {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-confirm-all option requires interactive input, so it cannot be combined with -input=false.",
+		))
+	}
+
+	if init.MigrateInteractiveSelect && !init.InputEnabled {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid init options",
+			"The -migrate-interactive-select option requires interactive input, so it cannot be combined with -input=false.",
+		))
+	}
+
 	if init.MigrateState && init.Reconfigure {
 		diags = diags.Append(tfdiags.Sourceless(
 			tfdiags.Error,
@@ -33,6 +33,7 @@ func TestParseInit_basicValid(t *testing.T) {
 				StateLockTimeout:    0,
 				Reconfigure:         false,
 				MigrateState:        false,
+				MigrateParallelism:  1,
 				Upgrade:             false,
 				Json:                false,
 				IgnoreRemoteVersion: false,
@@ -65,6 +66,7 @@ func TestParseInit_basicValid(t *testing.T) {
 				StateLockTimeout:    time.Duration(10) * time.Second,
 				Reconfigure:         true,
 				MigrateState:        false,
+				MigrateParallelism:  1,
 				Upgrade:             true,
 				Json:                true,
 				IgnoreRemoteVersion: true,
@@ -94,6 +96,7 @@ func TestParseInit_basicValid(t *testing.T) {
 				StateLockTimeout:    0,
 				Reconfigure:         false,
 				MigrateState:        false,
+				MigrateParallelism:  1,
 				Upgrade:             false,
 				Json:                false,
 				IgnoreRemoteVersion: false,
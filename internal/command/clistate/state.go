@@ -121,7 +121,11 @@ func (l *locker) Lock(s statemgr.Locker, reason string) tfdiags.Diagnostics {
 	lockInfo.Operation = reason
 
 	err := slowmessage.Do(LockThreshold, func() error {
-		id, err := statemgr.LockWithContext(ctx, s, lockInfo)
+		id, err := statemgr.LockWithContextProgress(ctx, s, lockInfo, func(le *statemgr.LockError) {
+			// Tell the user who is holding the lock we're waiting on, so
+			// that a contended lock looks blocked rather than hung.
+			l.view.Waiting(le.Info.Who, le.Info.ID)
+		})
 		l.lockID = id
 		return err
 	}, l.view.Locking)
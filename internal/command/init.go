@@ -56,11 +56,56 @@ func (c *InitCommand) Run(args []string) int {
 	}
 
 	c.forceInitCopy = initArgs.ForceInitCopy
+	c.forceInitCopyEmpty = initArgs.ForceInitCopyEmpty
 	c.Meta.stateLock = initArgs.StateLock
 	c.Meta.stateLockTimeout = initArgs.StateLockTimeout
 	c.reconfigure = initArgs.Reconfigure
 	c.migrateState = initArgs.MigrateState
+	c.migrateProvenance = initArgs.MigrateProvenance
+	c.migrateMinFormatVersion = initArgs.MigrateMinFormatVersion
+	c.migrateDebug = initArgs.MigrateDebug
+	c.migratePruneDeposed = initArgs.MigratePruneDeposed
+	c.migrateWorkspaceSuffix = initArgs.MigrateWorkspaceSuffix
+	c.migrateWorkspaceLimit = initArgs.MigrateWorkspaceLimit
+	c.migrateInteractiveSelect = initArgs.MigrateInteractiveSelect
+	c.migrateDiff = initArgs.MigrateDiff
+	c.migrateNoDiff = initArgs.MigrateNoDiff
+	c.migrateMaxStateSize = initArgs.MigrateMaxStateSize
+	c.migrateWorkspaceRenameCmd = initArgs.MigrateWorkspaceRenameCmd
+	c.migrateCurrentOnly = initArgs.MigrateCurrentOnly
+	c.migrateSkipIfSynced = initArgs.MigrateSkipIfSynced
+	c.migrateProviderRenames = initArgs.MigrateProviderRenames
+	c.migrateConfirmAll = initArgs.MigrateConfirmAll
+	c.migrateWorkspaces = initArgs.MigrateWorkspaces
+	c.migrateDryRun = initArgs.MigrateDryRun
+	c.migrateSummaryPath = initArgs.MigrateSummaryPath
+	c.migrateTempDir = initArgs.MigrateTempDir
+	c.migrateRedactTemp = initArgs.MigrateRedactTemp
+	c.migrateNewLineage = initArgs.MigrateNewLineage
+	c.migrateParallelism = initArgs.MigrateParallelism
+	c.migrateContinueOnError = initArgs.MigrateContinueOnError
+	c.migrateVerify = initArgs.MigrateVerify
+	c.migrateWorkspaceFilter = initArgs.MigrateWorkspaceFilter
+	c.migrateWorkspaceMapPath = initArgs.MigrateWorkspaceMapPath
+	c.migrateManifestPath = initArgs.MigrateManifestPath
+	c.migrateBackupDir = initArgs.MigrateBackupDir
+	c.migrateVariables = initArgs.MigrateVariables
+	c.migrateStrictVersion = initArgs.MigrateStrictVersion
+	c.migrateCheckpointPath = initArgs.MigrateCheckpointPath
+	c.migrateCloudProject = initArgs.MigrateCloudProject
+	c.migratePreserveSerial = initArgs.MigratePreserveSerial
+	c.migrateFromStatePath = initArgs.MigrateFromStatePath
+	c.migrateSkipEmpty = initArgs.MigrateSkipEmpty
+	c.migrateTimeout = initArgs.MigrateTimeout
+	c.migrateSourceLockTimeout = initArgs.MigrateSourceLockTimeout
+	c.migrateDestLockTimeout = initArgs.MigrateDestLockTimeout
+	c.migrateDeleteSource = initArgs.MigrateDeleteSource
+	c.migrateConflict = initArgs.MigrateConflict
+	c.migrateWorkspacePattern = initArgs.MigrateWorkspacePattern
+	c.migrateDestinationWorkspace = initArgs.MigrateDestinationWorkspace
+	c.migrateRetries = initArgs.MigrateRetries
 	c.Meta.ignoreRemoteVersion = initArgs.IgnoreRemoteVersion
+	c.Meta.ignoreRemoteVersionFor = initArgs.IgnoreRemoteVersionFor
 	c.Meta.input = initArgs.InputEnabled
 	c.Meta.targetFlags = initArgs.TargetFlags
 	c.Meta.compactWarnings = initArgs.CompactWarnings
@@ -74,8 +119,9 @@ func (c *InitCommand) Run(args []string) int {
 	c.Meta.variableArgs = arguments.FlagNameValueSlice{Items: &items}
 
 	// Copying the state only happens during backend migration, so setting
-	// -force-copy implies -migrate-state
-	if c.forceInitCopy {
+	// -force-copy (or its empty-destination-only variant) implies
+	// -migrate-state
+	if c.forceInitCopy || c.forceInitCopyEmpty {
 		c.migrateState = true
 	}
 
@@ -13,6 +13,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -243,27 +244,228 @@ type Meta struct {
 	// forceInitCopy suppresses confirmation for copying state data during
 	// init.
 	//
+	// forceInitCopyEmpty suppresses that confirmation only when the
+	// destination workspace has no existing state, so overwrites still
+	// require interactive approval.
+	//
 	// reconfigure forces init to ignore any stored configuration.
 	//
 	// migrateState confirms the user wishes to migrate from the prior backend
 	// configuration to a new configuration.
 	//
+	// migrateProvenance requests that a provenance annotation be recorded in
+	// the destination state during state migration, where supported.
+	//
+	// migrateMinFormatVersion, if non-zero, aborts state migration when any
+	// source workspace's state snapshot has a format version below the
+	// threshold.
+	//
+	// migrateDebug prints a one-shot report of the decisions made while
+	// preparing a state migration.
+	//
+	// migratePruneDeposed drops deposed resource instance objects from the
+	// destination state while migrating, rather than carrying them forward.
+	//
+	// migrateWorkspaceSuffix, if non-empty, is appended to destination
+	// workspace names that would otherwise overwrite an existing destination
+	// workspace during a multi-workspace migration.
+	//
+	// migrateWorkspaceLimit, if non-zero, aborts a multi-workspace migration
+	// that would leave the destination with more than the given number of
+	// workspaces.
+	//
+	// migrateInteractiveSelect explains the detected migration path and lets
+	// the user choose the migration mode explicitly when more than one mode
+	// is possible.
+	//
+	// migrateDiff is retained for compatibility now that the plan-style diff
+	// of the source and destination states renders by default before asking
+	// whether to overwrite a non-empty destination during state migration;
+	// migrateNoDiff suppresses it, leaving only the temporary files to
+	// inspect by hand.
+	//
+	// migrateMaxStateSize, if non-zero, aborts state migration when any
+	// source workspace's state serializes to more than the given number of
+	// bytes.
+	//
+	// migrateWorkspaceRenameCmd, if non-empty, names an external program that
+	// computes the destination workspace name for each source workspace
+	// during a multi-workspace migration.
+	//
+	// migrateCurrentOnly restricts a multi-workspace migration to copying
+	// only the currently selected workspace, leaving the other source
+	// workspaces behind.
+	//
+	// migrateSkipIfSynced skips state migration entirely when every source
+	// workspace already has matching state in the destination, judged by
+	// state snapshot metadata.
+	//
+	// migrateProviderRenames holds old=new provider source address mappings
+	// applied to each state while migrating.
+	//
+	// migrateConfirmAll presents every pending action of a multi-workspace
+	// migration as a single numbered list and asks for one approval covering
+	// all of them.
+	//
+	// migrateWorkspaces, if non-empty, restricts a multi-workspace migration
+	// to copying only the named workspaces.
+	//
+	// migrateDryRun reports what a state migration would do without writing
+	// anything to the destination.
+	//
+	// migrateSummaryPath, if non-empty, is where a machine-readable JSON
+	// summary of the migration is written; "-" means stdout.
+	//
+	// migrateTempDir, if non-empty, is where the temporary state copies
+	// written for migration confirmation are created, instead of the system
+	// default temporary directory; the TF_TEMP_DIR environment variable is
+	// the fallback.
+	//
+	// migrateRedactTemp masks values recorded as sensitive in the temporary
+	// state copies written for migration confirmation.
+	//
+	// migrateNewLineage assigns a freshly generated lineage to each migrated
+	// destination state instead of inheriting the source's.
+	//
+	// migrateParallelism is how many workspaces a multi-workspace migration
+	// may copy concurrently; 1 preserves the historical serial behavior.
+	//
+	// migrateContinueOnError keeps a multi-workspace migration copying the
+	// remaining workspaces when one of them fails, reporting all of the
+	// failures at the end.
+	//
+	// migrateVerify re-reads each migrated workspace from the destination
+	// after persisting it, to check that the copy round-tripped intact.
+	//
+	// migrateWorkspaceFilter, if non-nil, restricts a multi-workspace
+	// migration to source workspaces whose names match the expression.
+	//
+	// migrateWorkspaceMapPath, if non-empty, names a JSON file mapping
+	// source workspace names to explicit destination names for a migration
+	// to HCP Terraform.
+	//
+	// migrateManifestPath, if non-empty, names a JSON manifest enumerating
+	// every source workspace with its destination and an optional
+	// per-workspace conflict policy, suppressing all interactive prompts.
+	//
+	// migrateBackupDir, if non-empty, names a local directory that receives
+	// a backup of each destination workspace's pre-existing state before it
+	// is overwritten.
+	//
+	// migrateVariables also copies each workspace's variables to its
+	// destination workspace, for migrations between backends that store
+	// variables remotely.
+	//
+	// migrateTimeout, when positive, bounds the wall-clock duration of the
+	// whole state migration.
+	//
+	// migrateSourceLockTimeout and migrateDestLockTimeout, when positive,
+	// override stateLockTimeout for the migration source and destination
+	// locks respectively, so a slow side can wait longer without making the
+	// fast side less responsive.
+	//
+	// migrateFromStatePath, if non-empty, names a loose local state file to
+	// migrate from instead of whatever backend the working directory was
+	// previously using.
+	//
+	// migratePreserveSerial re-asserts each source workspace's state serial
+	// on its destination after the copy, where the destination supports it.
+	//
+	// migrateSkipEmpty scans all source workspaces for empty state up front
+	// and skips the empty ones as a single announced group.
+	//
+	// migrateCheckpointPath names a JSON file recording multi-workspace
+	// migration progress, so an interrupted migration resumes where it left
+	// off instead of restarting.
+	//
+	// migrateCloudProject names the HCP Terraform project that workspaces
+	// created during a migration are placed in, instead of the
+	// organization's default project.
+	//
+	// migrateStrictVersion aborts a migration, rather than warning, when a
+	// destination workspace requires a newer Terraform version than this CLI.
+	//
+	// migrateDeleteSource deletes each migrated workspace (never the
+	// default) from the source backend once the whole migration succeeds.
+	//
+	// migrateConflict resolves a non-empty destination workspace without
+	// prompting: "newest-serial", "source-wins", or "abort".
+	//
+	// migrateWorkspacePattern is the destination workspace rename pattern
+	// for a migration to HCP Terraform, supplied instead of the interactive
+	// prompt.
+	//
+	// migrateDestinationWorkspace names the destination workspace for a
+	// single-state migration instead of the default workspace.
+	//
+	// migrateRetries is how many extra attempts a transient destination
+	// error gets during state migration, with exponential backoff.
+	//
 	// compactWarnings (-compact-warnings) selects a more compact presentation
 	// of warnings in the output when they are not accompanied by errors.
-	statePath        string
-	stateOutPath     string
-	backupPath       string
-	parallelism      int
-	stateLock        bool
-	stateLockTimeout time.Duration
-	forceInitCopy    bool
-	reconfigure      bool
-	migrateState     bool
-	compactWarnings  bool
+	statePath                   string
+	stateOutPath                string
+	backupPath                  string
+	parallelism                 int
+	stateLock                   bool
+	stateLockTimeout            time.Duration
+	forceInitCopy               bool
+	forceInitCopyEmpty          bool
+	reconfigure                 bool
+	migrateState                bool
+	migrateProvenance           bool
+	migrateMinFormatVersion     int
+	migrateDebug                bool
+	migratePruneDeposed         bool
+	migrateWorkspaceSuffix      string
+	migrateWorkspaceLimit       int
+	migrateInteractiveSelect    bool
+	migrateDiff                 bool
+	migrateNoDiff               bool
+	migrateMaxStateSize         int64
+	migrateWorkspaceRenameCmd   string
+	migrateCurrentOnly          bool
+	migrateSkipIfSynced         bool
+	migrateProviderRenames      []string
+	migrateConfirmAll           bool
+	migrateWorkspaces           []string
+	migrateDryRun               bool
+	migrateSummaryPath          string
+	migrateTempDir              string
+	migrateRedactTemp           bool
+	migrateNewLineage           bool
+	migrateParallelism          int
+	migrateContinueOnError      bool
+	migrateVerify               bool
+	migrateWorkspaceFilter      *regexp.Regexp
+	migrateWorkspaceMapPath     string
+	migrateManifestPath         string
+	migrateBackupDir            string
+	migrateVariables            bool
+	migrateTimeout              time.Duration
+	migrateSourceLockTimeout    time.Duration
+	migrateDestLockTimeout      time.Duration
+	migrateFromStatePath        string
+	migratePreserveSerial       bool
+	migrateSkipEmpty            bool
+	migrateCheckpointPath       string
+	migrateCloudProject         string
+	migrateStrictVersion        bool
+	migrateDeleteSource         bool
+	migrateConflict             string
+	migrateWorkspacePattern     string
+	migrateDestinationWorkspace string
+	migrateRetries              int
+	compactWarnings             bool
 
 	// Used with commands which write state to allow users to write remote
 	// state even if the remote and local Terraform versions don't match.
 	ignoreRemoteVersion bool
+
+	// ignoreRemoteVersionFor lists destination workspace names whose remote
+	// Terraform version check is skipped during state migration, while the
+	// check stays enforced for every other workspace.
+	ignoreRemoteVersionFor []string
 }
 
 type testingOverrides struct {
@@ -71,6 +71,16 @@ type BackendWithRemoteTerraformVersion interface {
 	IsLocalOperations() bool
 }
 
+// BackendWithWorkspaceVersions is implemented by backends that can report the
+// Terraform version a workspace is configured to use, again shared between
+// the 'remote' and 'cloud' backends.
+type BackendWithWorkspaceVersions interface {
+	// WorkspaceTerraformVersion returns the Terraform version the named
+	// workspace is pinned to, or an empty string when the workspace doesn't
+	// exist or doesn't pin a concrete version.
+	WorkspaceTerraformVersion(workspace string) (string, error)
+}
+
 // Backend initializes and returns the backend for this CLI session.
 //
 // The backend is used to perform the actual Terraform operations. This
@@ -215,13 +225,14 @@ func (m *Meta) Backend(opts *BackendOpts) (backendrun.OperationsBackend, tfdiags
 // if the currently selected workspace is valid. If not, it will ask
 // the user to select a workspace from the list.
 func (m *Meta) selectWorkspace(b backend.Backend) error {
-	workspaces, err := b.Workspaces()
-	if err == backend.ErrWorkspacesNotSupported {
-		return nil
-	}
+	info, err := backend.ListWorkspaces(b)
 	if err != nil {
 		return fmt.Errorf("Failed to get existing workspaces: %s", err)
 	}
+	if info.SingleState {
+		return nil
+	}
+	workspaces := info.Workspaces
 	if len(workspaces) == 0 {
 		if c, ok := b.(*cloud.Cloud); ok && m.input {
 			// len is always 1 if using Name; 0 means we're using Tags and there
@@ -902,11 +913,40 @@ func (m *Meta) backend_c_r_S(
 
 	// Perform the migration
 	err := m.backendMigrateState(&backendMigrateOpts{
-		SourceType:      s.Backend.Type,
-		DestinationType: "local",
-		Source:          b,
-		Destination:     localB,
-		ViewType:        vt,
+		SourceType:           s.Backend.Type,
+		DestinationType:      "local",
+		Source:               b,
+		Destination:          localB,
+		ViewType:             vt,
+		RecordProvenance:     m.migrateProvenance,
+		PruneDeposed:         m.migratePruneDeposed,
+		WorkspaceSuffix:      m.migrateWorkspaceSuffix,
+		WorkspaceRenameCmd:   m.migrateWorkspaceRenameCmd,
+		CurrentOnly:          m.migrateCurrentOnly,
+		SkipIfSynced:         m.migrateSkipIfSynced,
+		ProviderRenames:      m.migrateProviderRenames,
+		ConfirmAll:           m.migrateConfirmAll,
+		Workspaces:           m.migrateWorkspaces,
+		DryRun:               m.migrateDryRun,
+		SummaryPath:          m.migrateSummaryPath,
+		Parallelism:          m.migrateParallelism,
+		ContinueOnError:      m.migrateContinueOnError,
+		Verify:               m.migrateVerify,
+		WorkspaceFilter:      m.migrateWorkspaceFilter,
+		WorkspaceMapPath:     m.migrateWorkspaceMapPath,
+		ManifestPath:         m.migrateManifestPath,
+		CheckpointPath:       m.migrateCheckpointPath,
+		CloudProject:         m.migrateCloudProject,
+		PreserveSerial:       m.migratePreserveSerial,
+		SkipEmpty:            m.migrateSkipEmpty,
+		Timeout:              m.migrateTimeout,
+		MigrateVariables:     m.migrateVariables,
+		BackupDir:            m.migrateBackupDir,
+		DeleteSource:         m.migrateDeleteSource,
+		ConflictStrategy:     m.migrateConflict,
+		WorkspacePattern:     m.migrateWorkspacePattern,
+		DestinationWorkspace: m.migrateDestinationWorkspace,
+		Retries:              m.migrateRetries,
 	})
 	if err != nil {
 		diags = diags.Append(err)
@@ -995,11 +1035,40 @@ func (m *Meta) backend_C_r_s(c *configs.Backend, cHash int, sMgr *clistate.Local
 	if len(localStates) > 0 {
 		// Perform the migration
 		err = m.backendMigrateState(&backendMigrateOpts{
-			SourceType:      "local",
-			DestinationType: c.Type,
-			Source:          localB,
-			Destination:     b,
-			ViewType:        vt,
+			SourceType:           "local",
+			DestinationType:      c.Type,
+			Source:               localB,
+			Destination:          b,
+			ViewType:             vt,
+			RecordProvenance:     m.migrateProvenance,
+			PruneDeposed:         m.migratePruneDeposed,
+			WorkspaceSuffix:      m.migrateWorkspaceSuffix,
+			WorkspaceRenameCmd:   m.migrateWorkspaceRenameCmd,
+			CurrentOnly:          m.migrateCurrentOnly,
+			SkipIfSynced:         m.migrateSkipIfSynced,
+			ProviderRenames:      m.migrateProviderRenames,
+			ConfirmAll:           m.migrateConfirmAll,
+			Workspaces:           m.migrateWorkspaces,
+			DryRun:               m.migrateDryRun,
+			SummaryPath:          m.migrateSummaryPath,
+			Parallelism:          m.migrateParallelism,
+			ContinueOnError:      m.migrateContinueOnError,
+			Verify:               m.migrateVerify,
+			WorkspaceFilter:      m.migrateWorkspaceFilter,
+			WorkspaceMapPath:     m.migrateWorkspaceMapPath,
+			ManifestPath:         m.migrateManifestPath,
+			CheckpointPath:       m.migrateCheckpointPath,
+			CloudProject:         m.migrateCloudProject,
+			PreserveSerial:       m.migratePreserveSerial,
+			SkipEmpty:            m.migrateSkipEmpty,
+			Timeout:              m.migrateTimeout,
+			MigrateVariables:     m.migrateVariables,
+			BackupDir:            m.migrateBackupDir,
+			DeleteSource:         m.migrateDeleteSource,
+			ConflictStrategy:     m.migrateConflict,
+			WorkspacePattern:     m.migrateWorkspacePattern,
+			DestinationWorkspace: m.migrateDestinationWorkspace,
+			Retries:              m.migrateRetries,
 		})
 		if err != nil {
 			diags = diags.Append(err)
@@ -1169,11 +1238,40 @@ func (m *Meta) backend_C_r_S_changed(c *configs.Backend, cHash int, sMgr *clista
 
 		// Perform the migration
 		err := m.backendMigrateState(&backendMigrateOpts{
-			SourceType:      s.Backend.Type,
-			DestinationType: c.Type,
-			Source:          oldB,
-			Destination:     b,
-			ViewType:        vt,
+			SourceType:           s.Backend.Type,
+			DestinationType:      c.Type,
+			Source:               oldB,
+			Destination:          b,
+			ViewType:             vt,
+			RecordProvenance:     m.migrateProvenance,
+			PruneDeposed:         m.migratePruneDeposed,
+			WorkspaceSuffix:      m.migrateWorkspaceSuffix,
+			WorkspaceRenameCmd:   m.migrateWorkspaceRenameCmd,
+			CurrentOnly:          m.migrateCurrentOnly,
+			SkipIfSynced:         m.migrateSkipIfSynced,
+			ProviderRenames:      m.migrateProviderRenames,
+			ConfirmAll:           m.migrateConfirmAll,
+			Workspaces:           m.migrateWorkspaces,
+			DryRun:               m.migrateDryRun,
+			SummaryPath:          m.migrateSummaryPath,
+			Parallelism:          m.migrateParallelism,
+			ContinueOnError:      m.migrateContinueOnError,
+			Verify:               m.migrateVerify,
+			WorkspaceFilter:      m.migrateWorkspaceFilter,
+			WorkspaceMapPath:     m.migrateWorkspaceMapPath,
+			ManifestPath:         m.migrateManifestPath,
+			CheckpointPath:       m.migrateCheckpointPath,
+			CloudProject:         m.migrateCloudProject,
+			PreserveSerial:       m.migratePreserveSerial,
+			SkipEmpty:            m.migrateSkipEmpty,
+			Timeout:              m.migrateTimeout,
+			MigrateVariables:     m.migrateVariables,
+			BackupDir:            m.migrateBackupDir,
+			DeleteSource:         m.migrateDeleteSource,
+			ConflictStrategy:     m.migrateConflict,
+			WorkspacePattern:     m.migrateWorkspacePattern,
+			DestinationWorkspace: m.migrateDestinationWorkspace,
+			Retries:              m.migrateRetries,
 		})
 		if err != nil {
 			diags = diags.Append(err)
@@ -6,36 +6,413 @@ package command
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net"
 	"os"
+	"os/exec"
+	"os/user"
+	"path"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
-
+	"sync"
+	"syscall"
+	"time"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hashicorp/terraform/internal/addrs"
 	"github.com/hashicorp/terraform/internal/backend"
+	backendLocal "github.com/hashicorp/terraform/internal/backend/local"
 	"github.com/hashicorp/terraform/internal/backend/remote"
 	"github.com/hashicorp/terraform/internal/cloud"
 	"github.com/hashicorp/terraform/internal/command/arguments"
 	"github.com/hashicorp/terraform/internal/command/clistate"
 	"github.com/hashicorp/terraform/internal/command/views"
+	"github.com/hashicorp/terraform/internal/configs/configschema"
 	"github.com/hashicorp/terraform/internal/states"
+	"github.com/hashicorp/terraform/internal/states/statefile"
 	"github.com/hashicorp/terraform/internal/states/statemgr"
 	"github.com/hashicorp/terraform/internal/terraform"
+	"github.com/hashicorp/terraform/internal/tfdiags"
+	tfversion "github.com/hashicorp/terraform/version"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
 )
 
+// MigrationDestinationRoute pairs a workspace name glob with a destination
+// backend, for migrations that split one source backend's workspaces across
+// several destinations. The glob uses the syntax of path.Match.
+type MigrationDestinationRoute struct {
+	// Pattern is matched against each source workspace name; the first
+	// matching route in the slice wins.
+	Pattern string
+
+	// DestinationType names the routed backend in prompts, reports and
+	// errors, in the same way as backendMigrateOpts.DestinationType.
+	DestinationType string
+
+	// Destination is the backend that matching workspaces are copied into.
+	Destination backend.Backend
+}
+
 type backendMigrateOpts struct {
 	SourceType, DestinationType string
 	Source, Destination         backend.Backend
 	ViewType                    arguments.ViewType
 
+	// RecordProvenance requests that a provenance note describing where the
+	// state was migrated from be stamped into the destination state's
+	// metadata, where the destination state manager supports arbitrary
+	// metadata. Destinations that don't support metadata produce a warning
+	// rather than an error.
+	RecordProvenance bool
+
+	// PruneDeposed requests that deposed resource instance objects be dropped
+	// from the destination state while copying, rather than carried forward.
+	// Only deposed objects are ever removed; current objects are always
+	// preserved, and the source state is never modified. Everything pruned is
+	// reported per workspace.
+	PruneDeposed bool
+
+	// WorkspaceRenameCmd, if non-empty, names an external program invoked
+	// with each source workspace name as its only argument during a
+	// multi-workspace migration; the program's stdout supplies the
+	// destination workspace name. A failing command or empty output aborts
+	// the migration before any workspace is copied.
+	WorkspaceRenameCmd string
+
+	// WorkspaceSuffix, if non-empty, is appended to destination workspace
+	// names that would otherwise overwrite an existing destination workspace
+	// during a multi-workspace migration, creating parallel workspaces for
+	// comparison instead of clobbering. A collision on a suffixed name is an
+	// error.
+	WorkspaceSuffix string
+
+	// CurrentOnly restricts a multi-workspace migration to copying only the
+	// currently selected workspace, leaving the other source workspaces
+	// behind in the source backend.
+	CurrentOnly bool
+
+	// SkipIfSynced requests that the migration be skipped entirely when every
+	// source workspace already has matching state in the destination, judged
+	// by state snapshot metadata: same lineage, with the destination at the
+	// source's serial or newer. This makes re-running init against an
+	// already-migrated backend nearly free.
+	SkipIfSynced bool
+
+	// ProviderRenames holds old=new provider source address mappings applied
+	// to each state while migrating, so that states referencing a renamed
+	// provider come out of the migration referencing the new address. The
+	// source state is never modified; the renames apply only to the copy
+	// written to the destination, and are reported per workspace.
+	ProviderRenames []string
+
+	// ConfirmAll requests that a multi-workspace migration present every
+	// pending action (copies, renames, overwrites) as a single numbered list
+	// and ask for one approval covering all of them, instead of the
+	// per-scenario prompts. Without interactive input the migration aborts
+	// rather than assuming approval.
+	ConfirmAll bool
+
+	// Workspaces, if non-empty, restricts a multi-workspace migration to
+	// copying only the named workspaces. Requesting a workspace that doesn't
+	// exist in the source is an error reported before anything is copied,
+	// rather than a silent skip.
+	Workspaces []string
+
+	// DryRun reports what the migration would do without writing anything to
+	// the destination. Each workspace that would be copied is described along
+	// with its source serial and lineage and whether the copy would overwrite
+	// existing destination state. Confirmation prompts are skipped, since
+	// nothing is at stake.
+	DryRun bool
+
+	// SummaryPath, if non-empty, requests a machine-readable JSON summary of
+	// the completed migration: the source and destination backend types plus
+	// one record per source workspace with its old and new names, the number
+	// of bytes copied, the resulting lineage, and whether it was skipped for
+	// having no state. The special value "-" writes the summary to stdout;
+	// anything else is a file path.
+	SummaryPath string
+
+	// Parallelism is how many workspaces a multi-workspace migration may copy
+	// concurrently. Zero or one preserves the historical strictly-serial
+	// behavior. Locking remains per workspace pair, since each copy locks
+	// only its own source and destination state managers.
+	Parallelism int
+
+	// ContinueOnError requests that a multi-workspace migration keep going
+	// when one workspace fails to copy, collecting the errors and reporting
+	// at the end which workspaces succeeded and which failed, instead of
+	// stopping at the first failure. The source is never modified either
+	// way, so a follow-up run can retry just the failures.
+	ContinueOnError bool
+
+	// Verify requests that each workspace be re-read from the destination
+	// after its state is persisted, checking that the content and snapshot
+	// metadata that come back match what was written. This costs an extra
+	// round-trip per workspace, so it's opt-in.
+	Verify bool
+
+	// WorkspaceFilter, if non-nil, restricts a multi-workspace migration to
+	// source workspaces whose names match the expression. Unlike Workspaces
+	// it's not an error for the filter to exclude a workspace, so it suits
+	// patterns like "everything belonging to one team". Nil keeps the
+	// default migrate-everything behavior.
+	WorkspaceFilter *regexp.Regexp
+
+	// WorkspaceMapPath, if non-empty, names a JSON file mapping source
+	// workspace names to explicit destination names for a migration to HCP
+	// Terraform. A mapped workspace ignores the rename pattern entirely;
+	// unmapped workspaces fall back to it. The file is validated (including
+	// for duplicate destination names) before anything is copied.
+	WorkspaceMapPath string
+
+	// DeleteSource requests that each migrated workspace be deleted from the
+	// source backend once the whole migration has completed successfully.
+	// The default workspace is never deleted, nor are workspaces that were
+	// skipped for having no state, and nothing at all is deleted if any
+	// workspace failed to copy. Combining this with Verify gives the
+	// strongest guarantee that the destination holds the state before the
+	// source loses it.
+	DeleteSource bool
+
+	// ConflictStrategy, if non-empty, resolves the case where both the source
+	// and destination workspaces have state without prompting. "newest-serial"
+	// copies only when the source snapshot serial is greater than the
+	// destination's, skipping otherwise; "source-wins" always copies, like
+	// force; "abort" fails the migration instead. Empty keeps the interactive
+	// confirmation.
+	ConflictStrategy string
+
+	// Retries is how many extra attempts each destination read or write gets
+	// when it fails with an error that looks transient (a timeout, a
+	// connection reset, a 5xx response). Attempts are separated by an
+	// exponentially growing backoff. Authentication and conflict errors are
+	// never retried. Zero disables retrying.
+	Retries int
+
+	// DestinationWorkspace, if non-empty, names the destination workspace
+	// for a single-state migration instead of the default workspace. The
+	// multi-workspace scenarios compute their own destination names and
+	// ignore it.
+	DestinationWorkspace string
+
+	// WorkspacePattern, if non-empty, is the destination workspace rename
+	// pattern for a migration to HCP Terraform, supplied on the command line
+	// instead of interactively. It must contain exactly one '*', which is
+	// replaced with each source workspace name.
+	WorkspacePattern string
+
+	// BackupDir, if non-empty, names a local directory that receives a
+	// backup of each destination workspace's pre-existing state before it is
+	// overwritten, as terraform.<workspace>.backup.tfstate. Workspaces whose
+	// destination has no state produce no backup. This gives a recovery path
+	// when a migration turns out to be wrong.
+	BackupDir string
+
+	// ManifestPath, if non-empty, names a JSON manifest enumerating every
+	// source workspace with its destination workspace and, optionally, a
+	// per-workspace conflict policy. The manifest must cover exactly the
+	// workspaces that exist in the source; it is validated before anything
+	// is copied. With a manifest in hand nothing is left to ask, so every
+	// interactive prompt is suppressed.
+	ManifestPath string
+
+	// Timeout, when positive, bounds the wall-clock duration of the whole
+	// migration. The deadline is carried by the context threaded through
+	// every state operation and lock, so a misbehaving backend is cancelled
+	// rather than hanging the run indefinitely; the resulting error names
+	// the workspace that was in progress when the deadline hit.
+	Timeout time.Duration
+
+	// SkipEmpty requests that source workspaces with empty state be
+	// discovered in one scan before anything is copied and skipped as a
+	// single announced group, rather than each being found empty one at a
+	// time partway through the copies. Empty workspaces are never copied
+	// either way; this only changes when and how the skipping is reported.
+	SkipEmpty bool
+
+	// PreserveSerial requests that each destination workspace end up with
+	// the same state serial as its source. statemgr.Migrate already carries
+	// the serial across when both state managers support full-fidelity
+	// migration, but some destinations bump the serial when the copy is
+	// persisted; this re-asserts the source's serial afterwards where the
+	// destination supports metadata writes, and warns that the serial will
+	// differ where it doesn't. For external tooling that tracks serials
+	// across a migration.
+	PreserveSerial bool
+
+	// CheckpointPath, if non-empty, names a JSON file recording the progress
+	// of a multi-workspace migration to HCP Terraform or Terraform
+	// Enterprise. The file is updated after each workspace's state lands in
+	// its destination, and a later run pointed at the same file skips the
+	// completed workspaces and reuses the rename pattern and prompted names
+	// recorded in it, so an interrupted migration resumes consistently
+	// instead of restarting and re-prompting. The file is removed once the
+	// whole migration succeeds.
+	CheckpointPath string
+
+	// CloudProject, if non-empty, names the HCP Terraform project that
+	// workspaces created during the migration are placed in, instead of the
+	// organization's default project. The destination must be HCP Terraform
+	// or Terraform Enterprise, and the cloud configuration block must not
+	// already assign a different project.
+	CloudProject string
+
+	// MigrateVariables requests that each workspace's variables be copied to
+	// its destination workspace along with the state, for migrations where
+	// both sides store variables remotely (cloud to cloud, or remote to
+	// cloud). Sensitive variables can't be read back from the source, so
+	// they are created as empty sensitive placeholders the user must
+	// re-populate afterwards.
+	MigrateVariables bool
+
+	// Hooks, when non-nil, receives a callback before and after each
+	// workspace copy in a multi-workspace migration. It exists for callers
+	// embedding Terraform who want to observe the migration (notifications,
+	// audit logging) without forking it. Nil disables the callbacks.
+	Hooks MigrationHooks
+
+	// DestinationRoutes, when non-empty, fans a multi-to-multi migration out
+	// to several destination backends: each source workspace is copied to
+	// the first route whose glob matches its name, and workspaces matching
+	// no route fall back to Destination. Routing is only supported between
+	// state backends; migrations involving HCP Terraform reject it.
+	DestinationRoutes []MigrationDestinationRoute
+
 	// Fields below are set internally when migrate is called
 
 	sourceWorkspace      string
 	destinationWorkspace string
 	force                bool // if true, won't ask for confirmation
+	forceEmpty           bool // like force, but only when the destination has no state
+
+	// deleteSourceAfterCopy is set when the user answers the empty-destination
+	// prompt with "copy-and-delete", and is consumed (and reset) once the
+	// copied state has been successfully persisted, deleting the source
+	// workspace.
+	deleteSourceAfterCopy bool
+
+	// providerRenames is the parsed form of ProviderRenames, populated by
+	// backendMigrateState before any workspace is copied.
+	providerRenames map[addrs.Provider]addrs.Provider
+
+	// workspaceMap is the parsed form of WorkspaceMapPath, populated by
+	// backendMigrateState before any workspace is copied.
+	workspaceMap map[string]string
+
+	// manifest is the parsed form of ManifestPath, populated by
+	// backendMigrateState before any workspace is copied.
+	manifest *backendMigrateManifest
+
+	// recorder accumulates the per-workspace records for SummaryPath. It is
+	// created by backendMigrateState and appended to by the scenario
+	// implementations as each workspace is copied or skipped.
+	recorder *backendMigrateRecorder
+
+	// stats accumulates the serialized size and copy duration of each
+	// workspace that was actually copied, for the quantitative report at the
+	// end of a migration.
+	stats *backendMigrateStats
+
+	// provenanceWarnOnce ensures we warn only once that the destination
+	// doesn't support provenance annotations. It's a pointer so that the
+	// per-worker copies of opts made by a parallel migration still share
+	// it; when nil (for callers that don't go through backendMigrateState)
+	// the warning is simply printed every time.
+	provenanceWarnOnce *sync.Once
+
+	// logger emits the migration's decision points in a structured
+	// key/value form. Set by backendMigrateState; the log() accessor
+	// lazily builds one for callers that don't go through it.
+	logger *migrateLogger
+}
+
+// migrateLogger writes the migration's decision points to the log as
+// key/value pairs under a single prefix, so that questions like "why was
+// workspace X skipped?" can be answered by filtering the log rather than
+// piecing prose messages together.
+type migrateLogger struct {
+	sourceType      string
+	destinationType string
+}
+
+// decision records one migration decision about a workspace. action is a
+// short machine-readable verb such as "copy" or "skip", and fields holds
+// any further detail as alternating keys and values, typically including a
+// "reason" for anything other than a copy.
+func (l *migrateLogger) decision(workspace, action string, fields ...string) {
+	var detail strings.Builder
+	for i := 0; i+1 < len(fields); i += 2 {
+		fmt.Fprintf(&detail, " %s=%q", fields[i], fields[i+1])
+	}
+	log.Printf("[INFO] migrate: decision=%q workspace=%q source=%q destination=%q%s",
+		action, workspace, l.sourceType, l.destinationType, detail.String())
+}
+
+// MigrationHooks receives callbacks around each workspace copy of a
+// multi-workspace migration. PreMigrateWorkspace runs before the copy is
+// attempted and PostMigrateWorkspace runs once it has finished, successfully
+// or not; err is nil on success. Implementations must be safe for concurrent
+// use, since a migration with parallelism invokes them from several
+// goroutines at once.
+type MigrationHooks interface {
+	PreMigrateWorkspace(source, destination string)
+	PostMigrateWorkspace(source, destination string, err error)
+}
+
+// noopMigrationHooks stands in when no hooks were configured.
+type noopMigrationHooks struct{}
+
+func (noopMigrationHooks) PreMigrateWorkspace(source, destination string) {}
+
+func (noopMigrationHooks) PostMigrateWorkspace(source, destination string, err error) {}
+
+// conflictStrategy returns the conflict strategy in effect for the workspace
+// currently being copied: the manifest's per-workspace policy when one is
+// set, otherwise the global ConflictStrategy.
+func (opts *backendMigrateOpts) conflictStrategy() string {
+	if opts.manifest != nil {
+		if policy := opts.manifest.conflictPolicyFor(opts.sourceWorkspace); policy != "" {
+			return policy
+		}
+	}
+	return opts.ConflictStrategy
+}
+
+// log returns the decision logger for this migration, building one on the
+// fly for callers that don't go through backendMigrateState.
+func (opts *backendMigrateOpts) log() *migrateLogger {
+	if opts.logger == nil {
+		opts.logger = &migrateLogger{
+			sourceType:      opts.SourceType,
+			destinationType: opts.DestinationType,
+		}
+	}
+	return opts.logger
+}
+
+// hooks returns the configured migration hooks, or a no-op implementation
+// when none were set, so the per-workspace loops don't need nil checks.
+func (opts *backendMigrateOpts) hooks() MigrationHooks {
+	if opts.Hooks != nil {
+		return opts.Hooks
+	}
+	return noopMigrationHooks{}
 }
 
 // backendMigrateState handles migrating (copying) state from one backend
@@ -51,6 +428,85 @@ type backendMigrateOpts struct {
 // This will attempt to lock both states for the migration.
 func (m *Meta) backendMigrateState(opts *backendMigrateOpts) error {
 	log.Printf("[INFO] backendMigrateState: need to migrate from %q to %q backend config", opts.SourceType, opts.DestinationType)
+
+	// -migrate-from-state replaces whatever source the init flow resolved
+	// with a synthetic single-workspace backend reading the given loose
+	// state file, so that an orphaned terraform.tfstate can be imported
+	// without first writing a local backend configuration for it.
+	if m.migrateFromStatePath != "" {
+		if _, err := os.Stat(m.migrateFromStatePath); err != nil {
+			return fmt.Errorf(strings.TrimSpace(
+				errMigrateFromStateMissing), m.migrateFromStatePath, err)
+		}
+		opts.Source = newStateFileBackend(m.migrateFromStatePath)
+		opts.SourceType = "local state file"
+	}
+
+	// Parse any provider rename mappings up front, so that a typo aborts the
+	// migration before anything is read or copied.
+	if len(opts.ProviderRenames) > 0 {
+		renames, err := parseProviderRenames(opts.ProviderRenames)
+		if err != nil {
+			return err
+		}
+		opts.providerRenames = renames
+	}
+
+	// Likewise the migration manifest, so that a malformed or
+	// self-contradictory file aborts the migration before anything is copied.
+	if opts.ManifestPath != "" {
+		manifest, err := parseMigrationManifest(opts.ManifestPath)
+		if err != nil {
+			return err
+		}
+		opts.manifest = manifest
+	}
+
+	// And the workspace name mapping file, for the same reason.
+	if opts.WorkspaceMapPath != "" {
+		mapping, err := parseWorkspaceMapFile(opts.WorkspaceMapPath)
+		if err != nil {
+			return err
+		}
+		opts.workspaceMap = mapping
+	}
+
+	opts.provenanceWarnOnce = new(sync.Once)
+	opts.stats = &backendMigrateStats{}
+	opts.logger = &migrateLogger{
+		sourceType:      opts.SourceType,
+		destinationType: opts.DestinationType,
+	}
+
+	// Start collecting per-workspace records if a JSON summary was requested,
+	// so the scenario implementations have somewhere to report into. Source
+	// cleanup uses the same records to know which workspaces were actually
+	// copied rather than skipped.
+	if opts.SummaryPath != "" || opts.DeleteSource {
+		opts.recorder = &backendMigrateRecorder{}
+	}
+
+	// Migrations are invoked from init before any request-scoped context
+	// exists, so the root context comes from the Meta and is cancelled on an
+	// interrupt, making Ctrl-C release any held locks instead of abandoning
+	// them on the backend. The package tracer comes from the global
+	// OpenTelemetry provider, which is a no-op unless telemetry has been
+	// configured, so the span costs nothing in the common case.
+	baseCtx, done := m.InterruptibleContext(m.CommandContext())
+	defer done()
+	if opts.Timeout > 0 {
+		// An overall deadline turns an unbounded hang on a misbehaving
+		// backend into a cancellation that releases any held locks.
+		var cancel context.CancelFunc
+		baseCtx, cancel = context.WithTimeout(baseCtx, opts.Timeout)
+		defer cancel()
+	}
+	ctx, span := tracer.Start(baseCtx, "migrate state", trace.WithAttributes(
+		attribute.String("source_backend", opts.SourceType),
+		attribute.String("destination_backend", opts.DestinationType),
+	))
+	defer span.End()
+
 	// We need to check what the named state status is. If we're converting
 	// from multi-state to single-state for example, we need to handle that.
 	var sourceSingleState, destinationSingleState, sourceTFC, destinationTFC bool
@@ -58,19 +514,128 @@ func (m *Meta) backendMigrateState(opts *backendMigrateOpts) error {
 	_, sourceTFC = opts.Source.(*cloud.Cloud)
 	_, destinationTFC = opts.Destination.(*cloud.Cloud)
 
-	sourceWorkspaces, sourceSingleState, err := retrieveWorkspaces(opts.Source, opts.SourceType)
-	if err != nil {
-		return err
+	// A project assignment is carried out by the cloud backend's workspace
+	// creation, so it is only meaningful when the destination is HCP
+	// Terraform or Terraform Enterprise.
+	if opts.CloudProject != "" && !destinationTFC {
+		return fmt.Errorf(strings.TrimSpace(errMigrateCloudProjectNotCloud), opts.DestinationType)
 	}
-	destinationWorkspaces, destinationSingleState, err := retrieveWorkspaces(opts.Destination, opts.SourceType)
-	if err != nil {
+
+	// Routing workspaces across several destinations is resolved inside the
+	// multi-to-multi copy loop, which migrations involving HCP Terraform
+	// don't go through, so reject the combination up front.
+	if len(opts.DestinationRoutes) > 0 {
+		if sourceTFC || destinationTFC {
+			return fmt.Errorf(strings.TrimSpace(errMigrateRoutesTFC))
+		}
+		if err := validateMigrationDestinationRoutes(opts.DestinationRoutes); err != nil {
+			return err
+		}
+	}
+
+	// Inspect the source and destination concurrently, since for remote
+	// backends each inspection is a network round-trip.
+	_, inspectSpan := tracer.Start(ctx, "inspect backends")
+	var (
+		sourceWorkspaces, destinationWorkspaces []string
+		sourceErr, destinationErr               error
+		wg                                      sync.WaitGroup
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		sourceWorkspaces, sourceSingleState, sourceErr = retrieveWorkspaces(opts.Source, opts.SourceType)
+	}()
+	go func() {
+		defer wg.Done()
+		destinationWorkspaces, destinationSingleState, destinationErr = retrieveWorkspaces(opts.Destination, opts.DestinationType)
+	}()
+	wg.Wait()
+	// retrieveWorkspaces already wraps failures in errMigrateLoadStates,
+	// naming the backend that failed, so we return its errors as-is. The
+	// source error is reported first when both sides fail.
+	if sourceErr != nil {
+		inspectSpan.SetStatus(codes.Error, "inspecting source backend failed")
+		inspectSpan.End()
+		return sourceErr
+	}
+	if destinationErr != nil {
+		inspectSpan.SetStatus(codes.Error, "inspecting destination backend failed")
+		inspectSpan.End()
+		return destinationErr
+	}
+	inspectSpan.End()
+
+	// When requested, find out whether the destination already holds every
+	// source workspace's state before doing any further work, so that
+	// re-running init against an already-migrated backend finishes almost
+	// immediately. The TFC strategies map workspace names in ways this cheap
+	// comparison can't predict, so those always take the full path.
+	if opts.SkipIfSynced && !sourceTFC && !destinationTFC {
+		synced, err := m.backendMigrateAllSynced(opts, sourceWorkspaces, destinationWorkspaces, sourceSingleState, destinationSingleState)
+		if err != nil {
+			return err
+		}
+		if synced {
+			log.Print("[INFO] backendMigrateState: destination already has matching state for every workspace, so skipping migration")
+			m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+				"[reset][bold]States in the %q backend are already synced to the %q backend, so no\nmigration is needed.[reset]",
+				opts.SourceType, opts.DestinationType)))
+			return nil
+		}
+	}
+
+	// The pre-flight checks below all happen before anything is copied, so a
+	// failure here leaves both backends untouched.
+	_, checkSpan := tracer.Start(ctx, "check migration preconditions")
+
+	// A manifest must cover exactly the workspaces that exist in the source,
+	// so any mismatch surfaces here before anything is copied.
+	if opts.manifest != nil {
+		if err := opts.manifest.validateAgainst(sourceWorkspaces, opts.ManifestPath); err != nil {
+			checkSpan.SetStatus(codes.Error, "migration manifest check failed")
+			checkSpan.End()
+			return err
+		}
+		// Destination names come from the manifest's entries, reusing the
+		// explicit-mapping handling.
+		opts.workspaceMap = opts.manifest.destinations()
+	}
+
+	// Refuse to migrate states stored in a format older than the operator's
+	// configured threshold, before we touch anything on either side.
+	if m.migrateMinFormatVersion > 0 {
+		if err := m.backendMigrateCheckFormatVersions(opts, sourceWorkspaces, sourceSingleState); err != nil {
+			checkSpan.SetStatus(codes.Error, "state format version check failed")
+			checkSpan.End()
+			return err
+		}
+	}
+
+	// Likewise refuse to start a migration that the destination would reject
+	// for state size, before anything is uploaded.
+	if err := m.backendMigrateCheckStateSizes(opts, sourceWorkspaces, sourceSingleState); err != nil {
+		checkSpan.SetStatus(codes.Error, "state size check failed")
+		checkSpan.End()
 		return err
 	}
 
 	// Set up defaults
 	opts.sourceWorkspace = backend.DefaultStateName
 	opts.destinationWorkspace = backend.DefaultStateName
+	if opts.DestinationWorkspace != "" {
+		// An explicit destination workspace replaces the default for a
+		// single-state migration. It also preempts the rename prompt that a
+		// destination refusing the default workspace would otherwise raise.
+		opts.destinationWorkspace = opts.DestinationWorkspace
+	}
 	opts.force = m.forceInitCopy
+	opts.forceEmpty = m.forceInitCopyEmpty
+	if opts.manifest != nil {
+		// The manifest pre-resolves every decision, so there is nothing left
+		// to confirm interactively.
+		opts.force = true
+	}
 
 	// Disregard remote Terraform version for the state source backend. If it's a
 	// HCP Terraform remote backend, we don't care about the remote version,
@@ -81,12 +646,28 @@ func (m *Meta) backendMigrateState(opts *backendMigrateOpts) error {
 	if m.ignoreRemoteVersion {
 		m.ignoreRemoteVersionConflict(opts.Destination)
 	} else {
+		// Some workspaces may be individually allowlisted out of the version
+		// check via -ignore-remote-version-for, while the check stays
+		// enforced for the rest.
+		skipVersionCheck := make(map[string]bool, len(m.ignoreRemoteVersionFor))
+		for _, name := range m.ignoreRemoteVersionFor {
+			skipVersionCheck[name] = true
+		}
+		var versionCheckBypassed []string
+
 		// Check the remote Terraform version for the state destination backend. If
 		// it's an HCP Terraform remote backend, we want to ensure that we don't
 		// break the workspace by uploading an incompatible state file.
 		for _, workspace := range destinationWorkspaces {
+			if skipVersionCheck[workspace] {
+				log.Printf("[INFO] backendMigrateState: skipping the remote version check for workspace %q, as requested", workspace)
+				versionCheckBypassed = append(versionCheckBypassed, workspace)
+				continue
+			}
 			diags := m.remoteVersionCheck(opts.Destination, workspace)
 			if diags.HasErrors() {
+				checkSpan.SetStatus(codes.Error, "remote version check failed")
+				checkSpan.End()
 				return diags.Err()
 			}
 		}
@@ -95,53 +676,221 @@ func (m *Meta) backendMigrateState(opts *backendMigrateOpts) error {
 		// Ensure that we are not dealing with HCP Terraform migrations, as it
 		// does not support the default name.
 		if len(destinationWorkspaces) == 0 && !destinationTFC {
-			diags := m.remoteVersionCheck(opts.Destination, backend.DefaultStateName)
-			if diags.HasErrors() {
-				return diags.Err()
+			if skipVersionCheck[backend.DefaultStateName] {
+				log.Printf("[INFO] backendMigrateState: skipping the remote version check for workspace %q, as requested", backend.DefaultStateName)
+				versionCheckBypassed = append(versionCheckBypassed, backend.DefaultStateName)
+			} else {
+				diags := m.remoteVersionCheck(opts.Destination, backend.DefaultStateName)
+				if diags.HasErrors() {
+					checkSpan.SetStatus(codes.Error, "remote version check failed")
+					checkSpan.End()
+					return diags.Err()
+				}
 			}
 		}
+
+		if len(versionCheckBypassed) > 0 {
+			m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+				"[reset][bold]Skipped the remote version check for %d workspace(s):[reset] %s",
+				len(versionCheckBypassed), strings.Join(versionCheckBypassed, ", "))))
+		}
 	}
 
+	// remoteVersionCheck only rejects pairings the destination itself
+	// considers incompatible. A destination workspace pinned to a newer
+	// Terraform than this CLI passes that check, yet once the state is
+	// there this CLI may no longer be able to operate on it, so that case
+	// is surfaced separately: as a warning by default, or as an error
+	// under -migrate-strict-version.
+	if err := m.backendMigrateCheckDestinationVersions(opts, destinationWorkspaces); err != nil {
+		checkSpan.SetStatus(codes.Error, "destination version check failed")
+		checkSpan.End()
+		return err
+	}
+	checkSpan.End()
+
 	// Determine migration behavior based on whether the source/destination
 	// supports multi-state.
+	strategy := backendMigrateChooseStrategy(sourceTFC, destinationTFC, sourceSingleState, destinationSingleState, sourceWorkspaces)
+
+	if m.migrateDebug {
+		// Ignore invalid workspace name as the report is informational only.
+		currentWorkspace, _ := m.Workspace()
+		m.Ui.Output(formatMigrateDebugReport(opts, strategy,
+			sourceTFC, destinationTFC, sourceSingleState, destinationSingleState,
+			sourceWorkspaces, destinationWorkspaces, currentWorkspace))
+	}
+
+	// Let the user override the detected migration path when they've asked
+	// to choose it explicitly and more than one mode is possible.
+	if m.migrateInteractiveSelect {
+		selected, err := m.backendMigrateSelectStrategy(opts, strategy,
+			sourceTFC, destinationTFC, sourceSingleState, destinationSingleState, sourceWorkspaces)
+		if err != nil {
+			return err
+		}
+		strategy = selected
+	}
+
+	// Pre-flight the destination workspace count before any workspaces are
+	// created, so that a quota problem aborts the whole migration rather than
+	// striking partway through.
+	if strategy == migrateStrategyMultiToMulti || strategy == migrateStrategyTFC {
+		if err := m.backendMigrateCheckWorkspaceLimit(opts, sourceWorkspaces, destinationWorkspaces); err != nil {
+			return err
+		}
+	}
+
+	var migrateErr error
+	switch strategy {
+	case migrateStrategyTFC:
+		migrateErr = m.backendMigrateTFC(ctx, opts)
+	case migrateStrategySingle:
+		migrateErr = m.backendMigrateState_s_s(ctx, opts)
+	case migrateStrategyMultiToSingle:
+		migrateErr = m.backendMigrateState_S_s(ctx, opts)
+	case migrateStrategyMultiToMulti:
+		if opts.CurrentOnly {
+			migrateErr = m.backendMigrateState_S_S_current(ctx, opts)
+		} else {
+			migrateErr = m.backendMigrateState_S_S(ctx, opts)
+		}
+	}
+	if migrateErr != nil {
+		if opts.Timeout > 0 && ctx.Err() == context.DeadlineExceeded {
+			// The scenario error is likely just a cancellation symptom at
+			// this point; say what actually happened and where.
+			return fmt.Errorf(strings.TrimSpace(errMigrateTimeout),
+				opts.Timeout, opts.sourceWorkspace, migrateErr)
+		}
+		return migrateErr
+	}
+
+	// When an explicit destination workspace received a single-state
+	// migration, reselect it if the default workspace is current, just as
+	// the interactive rename prompt would have.
+	if strategy == migrateStrategySingle && opts.DestinationWorkspace != "" &&
+		opts.destinationWorkspace != backend.DefaultStateName && !opts.DryRun {
+		// Ignore invalid workspace name as it is irrelevant in this context.
+		if workspace, _ := m.Workspace(); workspace == backend.DefaultStateName {
+			if err := m.SetWorkspace(opts.destinationWorkspace); err != nil {
+				return fmt.Errorf("Failed to set new workspace: %s", err)
+			}
+		}
+	}
+
+	// Only a completed migration gets a summary: a partial one would let a
+	// pipeline mistake an aborted run for a finished one. Dry runs write
+	// nothing, so they have nothing to summarize either.
+	if opts.recorder != nil && opts.SummaryPath != "" && !opts.DryRun {
+		if err := m.backendMigrateEmitSummary(opts); err != nil {
+			return err
+		}
+	}
+
+	// Source cleanup runs last of all, so every workspace has been copied,
+	// persisted (and verified, if requested) before anything is deleted.
+	if opts.DeleteSource && !opts.DryRun {
+		if err := m.backendMigrateDeleteSource(opts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backendMigrateStrategy identifies which of the migration scenario
+// implementations backendMigrateState will dispatch to.
+type backendMigrateStrategy string
+
+const (
+	migrateStrategyTFC           backendMigrateStrategy = "HCP Terraform"
+	migrateStrategySingle        backendMigrateStrategy = "single-to-single"
+	migrateStrategyMultiToSingle backendMigrateStrategy = "multi-to-single"
+	migrateStrategyMultiToMulti  backendMigrateStrategy = "multi-to-multi"
+)
+
+// backendMigrateChooseStrategy determines the migration behavior based on
+// whether the source/destination supports multi-state, mirroring the
+// scenarios described at length further down in this file.
+// backendMigrateSameLocation reports whether the source and destination
+// sides of a single workspace copy resolve to the same state storage, which
+// would make the copy lock the same state twice. The same backend instance
+// with the same workspace on both sides is always the same storage; beyond
+// that, two local backends are compared by the paths the two workspaces
+// actually live at. Other backend types can't be compared generically, so
+// they're assumed distinct.
+func backendMigrateSameLocation(opts *backendMigrateOpts) bool {
+	if opts.Source == opts.Destination && opts.sourceWorkspace == opts.destinationWorkspace {
+		return true
+	}
+	if sourceLocal, ok := opts.Source.(*backendLocal.Local); ok {
+		if destinationLocal, ok := opts.Destination.(*backendLocal.Local); ok {
+			sourcePath, _, _ := sourceLocal.StatePaths(opts.sourceWorkspace)
+			destinationPath, _, _ := destinationLocal.StatePaths(opts.destinationWorkspace)
+			return sourcePath == destinationPath
+		}
+	}
+	return false
+}
+
+func backendMigrateChooseStrategy(sourceTFC, destinationTFC, sourceSingleState, destinationSingleState bool, sourceWorkspaces []string) backendMigrateStrategy {
+	// If the source only has one state and it is the default, treat it as
+	// if it doesn't support multi-state.
+	sourceEffectivelySingle := sourceSingleState ||
+		(len(sourceWorkspaces) == 1 && sourceWorkspaces[0] == backend.DefaultStateName)
+
 	switch {
 	case sourceTFC || destinationTFC:
-		return m.backendMigrateTFC(opts)
+		return migrateStrategyTFC
 
-	// Single-state to single-state. This is the easiest case: we just
-	// copy the default state directly.
-	case sourceSingleState && destinationSingleState:
-		return m.backendMigrateState_s_s(opts)
-
-	// Single-state to multi-state. This is easy since we just copy
-	// the default state and ignore the rest in the destination.
-	case sourceSingleState && !destinationSingleState:
-		return m.backendMigrateState_s_s(opts)
+	// Single-state to single-state is the easiest case: we just copy the
+	// default state directly. Single-state to multi-state is just as easy,
+	// since we copy the default state and ignore the rest in the
+	// destination.
+	case sourceEffectivelySingle:
+		return migrateStrategySingle
 
 	// Multi-state to single-state. If the source has more than the default
 	// state this is complicated since we have to ask the user what to do.
-	case !sourceSingleState && destinationSingleState:
-		// If the source only has one state and it is the default,
-		// treat it as if it doesn't support multi-state.
-		if len(sourceWorkspaces) == 1 && sourceWorkspaces[0] == backend.DefaultStateName {
-			return m.backendMigrateState_s_s(opts)
-		}
-
-		return m.backendMigrateState_S_s(opts)
+	case destinationSingleState:
+		return migrateStrategyMultiToSingle
 
 	// Multi-state to multi-state. We merge the states together (migrating
 	// each from the source to the destination one by one).
-	case !sourceSingleState && !destinationSingleState:
-		// If the source only has one state and it is the default,
-		// treat it as if it doesn't support multi-state.
-		if len(sourceWorkspaces) == 1 && sourceWorkspaces[0] == backend.DefaultStateName {
-			return m.backendMigrateState_s_s(opts)
-		}
+	default:
+		return migrateStrategyMultiToMulti
+	}
+}
 
-		return m.backendMigrateState_S_S(opts)
+// formatMigrateDebugReport renders the one-shot -migrate-debug report of
+// everything backendMigrateState computed before dispatching, so that
+// unexpected migration behavior can be diagnosed from a single paste.
+func formatMigrateDebugReport(opts *backendMigrateOpts, strategy backendMigrateStrategy,
+	sourceTFC, destinationTFC, sourceSingleState, destinationSingleState bool,
+	sourceWorkspaces, destinationWorkspaces []string, currentWorkspace string) string {
+
+	workspaceList := func(names []string) string {
+		if len(names) == 0 {
+			return "(none)"
+		}
+		return strings.Join(names, ", ")
 	}
 
-	return nil
+	var buf bytes.Buffer
+	buf.WriteString("Migration decision report:\n")
+	fmt.Fprintf(&buf, "  Source backend:             %q (single-state: %t, HCP Terraform: %t)\n",
+		opts.SourceType, sourceSingleState, sourceTFC)
+	fmt.Fprintf(&buf, "  Destination backend:        %q (single-state: %t, HCP Terraform: %t)\n",
+		opts.DestinationType, destinationSingleState, destinationTFC)
+	fmt.Fprintf(&buf, "  Source workspaces (%d):      %s\n",
+		len(sourceWorkspaces), workspaceList(sourceWorkspaces))
+	fmt.Fprintf(&buf, "  Destination workspaces (%d): %s\n",
+		len(destinationWorkspaces), workspaceList(destinationWorkspaces))
+	fmt.Fprintf(&buf, "  Current workspace:          %s\n", currentWorkspace)
+	fmt.Fprintf(&buf, "  Force copy:                 %t\n", opts.force)
+	fmt.Fprintf(&buf, "  Chosen migration path:      %s", strategy)
+	return buf.String()
 }
 
 //-------------------------------------------------------------------
@@ -161,12 +910,24 @@ func (m *Meta) backendMigrateState(opts *backendMigrateOpts) error {
 //-------------------------------------------------------------------
 
 // Multi-state to multi-state.
-func (m *Meta) backendMigrateState_S_S(opts *backendMigrateOpts) error {
+func (m *Meta) backendMigrateState_S_S(ctx context.Context, opts *backendMigrateOpts) error {
 	log.Print("[INFO] backendMigrateState: migrating all named workspaces")
 
 	migrate := opts.force
-	if !migrate {
-		var err error
+	if !migrate && !opts.ConfirmAll && !opts.DryRun {
+		// Show what "all workspaces" actually means before asking, since the
+		// honest answer may well depend on how many there are and which of
+		// them would overwrite state the destination already holds.
+		lines, err := backendMigrateDescribeWorkspaces(opts)
+		if err != nil {
+			return err
+		}
+		m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+			"[reset][bold]Workspaces in the %q backend:[reset]", opts.SourceType)))
+		for _, line := range lines {
+			m.Ui.Output("  - " + line)
+		}
+
 		// Ask the user if they want to migrate their existing remote state
 		migrate, err = m.confirm(&terraform.InputOpts{
 			Id: "backend-migrate-multistate-to-multistate",
@@ -181,9 +942,9 @@ func (m *Meta) backendMigrateState_S_S(opts *backendMigrateOpts) error {
 			return fmt.Errorf(
 				"Error asking for state migration action: %s", err)
 		}
-	}
-	if !migrate {
-		return fmt.Errorf("Migration aborted by user.")
+		if !migrate {
+			return fmt.Errorf("Migration aborted by user.")
+		}
 	}
 
 	// Read all the states
@@ -196,46 +957,314 @@ func (m *Meta) backendMigrateState_S_S(opts *backendMigrateOpts) error {
 	// Sort the states so they're always copied alphabetically
 	sort.Strings(sourceWorkspaces)
 
-	// Go through each and migrate
+	// When only certain workspaces were requested, restrict the copy to
+	// those, refusing up front if any of them doesn't exist in the source.
+	if len(opts.Workspaces) > 0 {
+		selected, err := selectMigrationWorkspaces(sourceWorkspaces, opts.Workspaces, opts.SourceType)
+		if err != nil {
+			return err
+		}
+		sourceWorkspaces = selected
+	}
+
+	// A filter expression narrows the copy further to matching names only.
+	if opts.WorkspaceFilter != nil {
+		sourceWorkspaces = filterMigrationWorkspaces(sourceWorkspaces, opts.WorkspaceFilter)
+	}
+
+	// With -migrate-skip-empty the emptiness of every source workspace is
+	// established up front and the empty ones are dropped here as one
+	// announced group, so the loop below doesn't need to take a lock on a
+	// workspace just to discover there's nothing to copy.
+	if opts.SkipEmpty {
+		nonEmpty, empty, err := backendMigrateScanEmptyWorkspaces(opts, sourceWorkspaces)
+		if err != nil {
+			return err
+		}
+		if len(empty) > 0 {
+			m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+				"[reset][bold]Skipping %d workspace(s) with empty state:[reset]", len(empty))))
+			for _, name := range empty {
+				m.Ui.Output("  - " + name)
+				opts.sourceWorkspace = name
+				opts.destinationWorkspace = name
+				opts.log().decision(name, "skip", "reason", "empty-source")
+				if opts.recorder != nil {
+					opts.recorder.recordSkipped(opts, "empty")
+				}
+			}
+		}
+		sourceWorkspaces = nonEmpty
+	}
+
+	// When a rename command or a collision-avoiding suffix is configured,
+	// resolve every destination name before copying anything, so that a
+	// failing rename or a collision among suffixed names aborts the
+	// migration before any workspace is touched.
+	destinationNames := map[string]string{}
+	suffixedFrom := map[string]string{}
+	if opts.WorkspaceSuffix != "" || opts.WorkspaceRenameCmd != "" {
+		var destinationExisting map[string]bool
+		if opts.WorkspaceSuffix != "" {
+			destinationWorkspaces, err := opts.Destination.Workspaces()
+			if err != nil {
+				return fmt.Errorf(strings.TrimSpace(
+					errMigrateLoadStates), opts.DestinationType, err)
+			}
+			destinationExisting = make(map[string]bool, len(destinationWorkspaces))
+			for _, name := range destinationWorkspaces {
+				destinationExisting[name] = true
+			}
+		}
+		for _, name := range sourceWorkspaces {
+			destinationName := name
+			if opts.WorkspaceRenameCmd != "" {
+				renamed, err := runWorkspaceRenameCmd(opts.WorkspaceRenameCmd, name)
+				if err != nil {
+					return err
+				}
+				log.Printf("[TRACE] backendMigrateState: rename command mapped workspace %q to %q", name, renamed)
+				destinationName = renamed
+			}
+			if opts.WorkspaceSuffix != "" {
+				resolved, err := resolveWorkspaceSuffix(destinationName, opts.WorkspaceSuffix, destinationExisting)
+				if err != nil {
+					return err
+				}
+				if resolved != destinationName {
+					suffixedFrom[name] = destinationName
+				}
+				destinationName = resolved
+			}
+			destinationNames[name] = destinationName
+		}
+	}
+
+	// A manifest names every destination explicitly, overriding whatever the
+	// rename command or suffix would have produced.
+	if opts.manifest != nil {
+		for name, mapped := range opts.manifest.destinations() {
+			destinationNames[name] = mapped
+		}
+	}
+
+	// When one informed approval was requested instead of the scattered
+	// prompts, it happens here: every destination name has been resolved, so
+	// the whole set of pending actions is known before anything is copied.
+	if opts.ConfirmAll && !opts.force && !opts.DryRun {
+		actions, err := m.backendMigrateComputeActions(opts, sourceWorkspaces, destinationNames, true)
+		if err != nil {
+			return err
+		}
+		confirmed, err := m.backendMigrateConfirmAll(opts, actions)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return fmt.Errorf("Migration aborted by user.")
+		}
+	}
+
+	// Resolve the full set of copies up front, announcing any renames, so the
+	// loop below (serial or parallel) only has to perform them.
+	type workspaceCopy struct {
+		source             string
+		destination        string
+		destinationBackend backend.Backend
+		destinationType    string
+	}
+	copies := make([]workspaceCopy, 0, len(sourceWorkspaces))
 	for _, name := range sourceWorkspaces {
-		// Copy the same names
-		opts.sourceWorkspace = name
-		opts.destinationWorkspace = name
+		destinationName := name
+		destinationBackend, destinationType := resolveMigrationDestinationRoute(opts, name)
+		if mapped, ok := destinationNames[name]; ok && mapped != name {
+			if collidingName, wasSuffixed := suffixedFrom[name]; wasSuffixed {
+				m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+					"[reset][yellow]Workspace %q already exists in the %q backend, so its state will be\nmigrated into the new workspace %q instead.[reset]",
+					collidingName, opts.DestinationType, destinationName)))
+			} else {
+				m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+					"[reset][bold]Workspace %q will be migrated to destination workspace %q.[reset]",
+					name, mapped)))
+			}
+			destinationName = mapped
+		}
+		copies = append(copies, workspaceCopy{
+			source:             name,
+			destination:        destinationName,
+			destinationBackend: destinationBackend,
+			destinationType:    destinationType,
+		})
+	}
 
-		// Force it, we confirmed above
-		opts.force = true
+	// Every destination name is now final, so if the destination enforces
+	// naming rules then check them all here, rather than letting the first
+	// bad name abort a migration partway through the copies.
+	if validator, ok := opts.Destination.(backend.WorkspaceNameValidator); ok {
+		var invalid []string
+		for _, pair := range copies {
+			if err := validator.ValidateWorkspaceName(pair.destination); err != nil {
+				invalid = append(invalid, fmt.Sprintf("%s: %s", pair.destination, err))
+			}
+		}
+		if len(invalid) > 0 {
+			return fmt.Errorf(strings.TrimSpace(errMigrateInvalidDestinationNames),
+				opts.DestinationType, strings.Join(invalid, "\n  - "))
+		}
+	}
 
-		// Perform the migration
-		if err := m.backendMigrateState_s_s(opts); err != nil {
-			return fmt.Errorf(strings.TrimSpace(
-				errMigrateMulti), name, opts.SourceType, opts.DestinationType, err)
+	// Force it, we confirmed above
+	opts.force = true
+
+	// The loops below point opts at each workspace's routed destination, so
+	// put the main destination back for whatever runs after the copies, and
+	// prepare the per-destination report that routing makes meaningful.
+	reportRouteSummary := func() {}
+	if len(opts.DestinationRoutes) > 0 {
+		mainDestination, mainDestinationType := opts.Destination, opts.DestinationType
+		defer func() {
+			opts.Destination, opts.DestinationType = mainDestination, mainDestinationType
+		}()
+
+		counts := make(map[string]int, len(copies))
+		for _, pair := range copies {
+			counts[pair.destinationType]++
+		}
+		types := make([]string, 0, len(counts))
+		for destinationType := range counts {
+			types = append(types, destinationType)
+		}
+		sort.Strings(types)
+		reportRouteSummary = func() {
+			m.Ui.Output(m.Colorize().Color("[reset][bold]Workspaces migrated per destination:[reset]"))
+			for _, destinationType := range types {
+				m.Ui.Output(fmt.Sprintf("  - %s: %d workspace(s)", destinationType, counts[destinationType]))
+			}
+		}
+	}
+
+	if opts.Parallelism <= 1 {
+		// Go through each and migrate
+		var diags tfdiags.Diagnostics
+		var succeeded, failed []string
+		for i, pair := range copies {
+			opts.sourceWorkspace = pair.source
+			opts.destinationWorkspace = pair.destination
+			opts.Destination = pair.destinationBackend
+			opts.DestinationType = pair.destinationType
+
+			m.backendMigrateProgress(i+1, len(copies), pair.source, pair.destination)
+			started := time.Now()
+
+			// Perform the migration
+			opts.hooks().PreMigrateWorkspace(pair.source, pair.destination)
+			err := m.backendMigrateState_s_s(ctx, opts)
+			opts.hooks().PostMigrateWorkspace(pair.source, pair.destination, err)
+			if err != nil {
+				if opts.ContinueOnError {
+					log.Printf("[WARN] backendMigrateState: workspace %q failed to copy, continuing with the rest: %s", pair.source, err)
+					diags = diags.Append(fmt.Errorf(strings.TrimSpace(
+						errMigrateMulti), pair.source, opts.SourceType, pair.destinationType, err))
+					failed = append(failed, pair.source)
+					continue
+				}
+				return fmt.Errorf(strings.TrimSpace(
+					errMigrateMulti), pair.source, opts.SourceType, pair.destinationType, err)
+			}
+			m.backendMigrateElapsed(pair.source, started)
+			succeeded = append(succeeded, pair.source)
+		}
+		if len(failed) > 0 {
+			succeededList := "(none)"
+			if len(succeeded) > 0 {
+				succeededList = strings.Join(succeeded, ", ")
+			}
+			m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+				"[reset][bold]Migration finished with errors.[reset]\n  Succeeded: %s\n  Failed:    %s",
+				succeededList, strings.Join(failed, ", "))))
+			return diags.Err()
 		}
+		reportRouteSummary()
+		return nil
+	}
+
+	// Copy workspaces through a bounded worker pool. Each worker gets its own
+	// shallow copy of opts so the per-workspace names don't race; everything
+	// shared (the summary accumulator, the provenance warn-once) is behind a
+	// pointer and safe for concurrent use. Locking stays per workspace pair
+	// because each backendMigrateState_s_s call locks only its own source and
+	// destination state managers.
+	log.Printf("[INFO] backendMigrateState: copying %d workspaces with parallelism %d", len(copies), opts.Parallelism)
+	var (
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, opts.Parallelism)
+		mu       sync.Mutex
+		failures []string
+	)
+	for i, pair := range copies {
+		wg.Add(1)
+		go func(i int, pair workspaceCopy) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			m.backendMigrateProgress(i+1, len(copies), pair.source, pair.destination)
+			started := time.Now()
+
+			workerOpts := *opts
+			workerOpts.sourceWorkspace = pair.source
+			workerOpts.destinationWorkspace = pair.destination
+			workerOpts.Destination = pair.destinationBackend
+			workerOpts.DestinationType = pair.destinationType
+			workerOpts.hooks().PreMigrateWorkspace(pair.source, pair.destination)
+			err := m.backendMigrateState_s_s(ctx, &workerOpts)
+			workerOpts.hooks().PostMigrateWorkspace(pair.source, pair.destination, err)
+			if err != nil {
+				mu.Lock()
+				failures = append(failures, fmt.Sprintf("%s: %s", pair.source, err))
+				mu.Unlock()
+				return
+			}
+			m.backendMigrateElapsed(pair.source, started)
+		}(i, pair)
+	}
+	wg.Wait()
+
+	if len(failures) > 0 {
+		// Sorted so the report is deterministic regardless of the order the
+		// copies finished in.
+		sort.Strings(failures)
+		return fmt.Errorf(strings.TrimSpace(errMigrateMultiParallel),
+			opts.SourceType, opts.DestinationType, strings.Join(failures, "\n  "))
 	}
 
+	reportRouteSummary()
 	return nil
 }
 
-// Multi-state to single state.
-func (m *Meta) backendMigrateState_S_s(opts *backendMigrateOpts) error {
-	log.Printf("[INFO] backendMigrateState: destination backend type %q does not support named workspaces", opts.DestinationType)
-
+// Multi-state to multi-state, restricted to the current workspace only.
+// This behaves like the multi-to-single scenario in that just one workspace
+// is copied, except that the destination keeps the workspace's name rather
+// than collapsing it into the default workspace.
+func (m *Meta) backendMigrateState_S_S_current(ctx context.Context, opts *backendMigrateOpts) error {
 	currentWorkspace, err := m.Workspace()
 	if err != nil {
 		return err
 	}
 
-	migrate := opts.force
+	log.Printf("[INFO] backendMigrateState: migrating only the current workspace %q", currentWorkspace)
+
+	migrate := opts.force || opts.DryRun
 	if !migrate {
 		var err error
-		// Ask the user if they want to migrate their existing remote state
+		// Ask the user if they want to migrate just their current workspace
 		migrate, err = m.confirm(&terraform.InputOpts{
-			Id: "backend-migrate-multistate-to-single",
+			Id: "backend-migrate-multistate-to-multistate-current",
 			Query: fmt.Sprintf(
-				"Destination state %q doesn't support workspaces.\n"+
-					"Do you want to copy only your current workspace?",
-				opts.DestinationType),
+				"Do you want to migrate only the current workspace %q to %q?",
+				currentWorkspace, opts.DestinationType),
 			Description: fmt.Sprintf(
-				strings.TrimSpace(inputBackendMigrateMultiToSingle),
+				strings.TrimSpace(inputBackendMigrateMultiToMultiCurrent),
 				opts.SourceType, opts.DestinationType, currentWorkspace),
 		})
 		if err != nil {
@@ -243,24 +1272,92 @@ func (m *Meta) backendMigrateState_S_s(opts *backendMigrateOpts) error {
 				"Error asking for state migration action: %s", err)
 		}
 	}
-
 	if !migrate {
 		return fmt.Errorf("Migration aborted by user.")
 	}
 
-	// Copy the default state
+	// Copy just the current workspace, keeping its name.
 	opts.sourceWorkspace = currentWorkspace
+	opts.destinationWorkspace = currentWorkspace
 
-	// now switch back to the default env so we can acccess the new backend
-	m.SetWorkspace(backend.DefaultStateName)
+	// Force it, we confirmed above
+	opts.force = true
 
-	return m.backendMigrateState_s_s(opts)
+	return m.backendMigrateState_s_s(ctx, opts)
 }
 
-// Single state to single state, assumed default state name.
-func (m *Meta) backendMigrateState_s_s(opts *backendMigrateOpts) error {
-	log.Printf("[INFO] backendMigrateState: single-to-single migrating %q workspace to %q workspace", opts.sourceWorkspace, opts.destinationWorkspace)
-
+// Multi-state to single state.
+func (m *Meta) backendMigrateState_S_s(ctx context.Context, opts *backendMigrateOpts) error {
+	log.Printf("[INFO] backendMigrateState: destination backend type %q does not support named workspaces", opts.DestinationType)
+
+	currentWorkspace, err := m.Workspace()
+	if err != nil {
+		return err
+	}
+
+	migrate := opts.force || opts.DryRun
+	if !migrate {
+		var err error
+		// Ask the user if they want to migrate their existing remote state
+		migrate, err = m.confirm(&terraform.InputOpts{
+			Id: "backend-migrate-multistate-to-single",
+			Query: fmt.Sprintf(
+				"Destination state %q doesn't support workspaces.\n"+
+					"Do you want to copy only your current workspace?",
+				opts.DestinationType),
+			Description: fmt.Sprintf(
+				strings.TrimSpace(inputBackendMigrateMultiToSingle),
+				opts.SourceType, opts.DestinationType, currentWorkspace),
+		})
+		if err != nil {
+			return fmt.Errorf(
+				"Error asking for state migration action: %s", err)
+		}
+	}
+
+	if !migrate {
+		return fmt.Errorf("Migration aborted by user.")
+	}
+
+	// Copy the default state
+	opts.sourceWorkspace = currentWorkspace
+
+	// now switch back to the default env so we can acccess the new backend
+	m.SetWorkspace(backend.DefaultStateName)
+
+	// If the migration fails, put the workspace selection back the way it
+	// was, so an aborted migration doesn't silently leave the user on the
+	// default workspace.
+	if err := m.backendMigrateState_s_s(ctx, opts); err != nil {
+		if restoreErr := m.SetWorkspace(currentWorkspace); restoreErr != nil {
+			log.Printf("[WARN] backendMigrateState: failed to restore workspace selection %q: %s", currentWorkspace, restoreErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// Single state to single state, assumed default state name.
+func (m *Meta) backendMigrateState_s_s(ctx context.Context, opts *backendMigrateOpts) error {
+	// Every multi-workspace scenario funnels through here once per
+	// workspace, so checking for cancellation here stops the whole
+	// migration promptly after an interrupt without each loop needing its
+	// own check. Workspaces already copied stay copied; the source is never
+	// modified either way.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] backendMigrateState: single-to-single migrating %q workspace to %q workspace", opts.sourceWorkspace, opts.destinationWorkspace)
+
+	// Multi-workspace migrations funnel through here once per workspace, so
+	// this span is the per-workspace unit of work in the trace.
+	ctx, span := tracer.Start(ctx, "copy workspace state", trace.WithAttributes(
+		attribute.String("source_workspace", opts.sourceWorkspace),
+		attribute.String("destination_workspace", opts.destinationWorkspace),
+	))
+	defer span.End()
+
 	sourceState, err := opts.Source.StateMgr(opts.sourceWorkspace)
 	if err != nil {
 		return fmt.Errorf(strings.TrimSpace(
@@ -273,7 +1370,15 @@ func (m *Meta) backendMigrateState_s_s(opts *backendMigrateOpts) error {
 
 	// Do not migrate workspaces without state.
 	if sourceState.State().Empty() {
-		log.Print("[TRACE] backendMigrateState: source workspace has empty state, so nothing to migrate")
+		opts.log().decision(opts.sourceWorkspace, "skip", "reason", "empty-source")
+		if opts.DryRun {
+			m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+				"[reset][bold]Dry run:[reset] would skip workspace %q in %q, which has no state",
+				opts.sourceWorkspace, opts.SourceType)))
+		}
+		if opts.recorder != nil {
+			opts.recorder.recordSkipped(opts, "empty")
+		}
 		return nil
 	}
 
@@ -314,7 +1419,9 @@ func (m *Meta) backendMigrateState_s_s(opts *backendMigrateOpts) error {
 		return fmt.Errorf(strings.TrimSpace(
 			errMigrateSingleLoadDefault), opts.DestinationType, err)
 	}
-	if err := destinationState.RefreshState(); err != nil {
+	if err := m.backendMigrateRetry(opts,
+		fmt.Sprintf("reading workspace %q from the %q backend", opts.destinationWorkspace, opts.DestinationType),
+		nil, destinationState.RefreshState); err != nil {
 		return fmt.Errorf(strings.TrimSpace(
 			errMigrateSingleLoadDefault), opts.DestinationType, err)
 	}
@@ -324,6 +1431,33 @@ func (m *Meta) backendMigrateState_s_s(opts *backendMigrateOpts) error {
 	source := sourceState.State()
 	destination := destinationState.State()
 
+	// Cheapest check first: when both sides report the same lineage and
+	// serial, the workspace was already migrated, so a re-run after a
+	// partial failure can skip it without locking or comparing the full
+	// states.
+	if sm1, ok := sourceState.(statemgr.PersistentMeta); ok {
+		if sm2, ok := destinationState.(statemgr.PersistentMeta); ok {
+			sourceMeta := sm1.StateSnapshotMeta()
+			destinationMeta := sm2.StateSnapshotMeta()
+			if sourceMeta.Lineage != "" && sourceMeta.Lineage == destinationMeta.Lineage &&
+				sourceMeta.Serial == destinationMeta.Serial {
+				opts.log().decision(opts.sourceWorkspace, "skip",
+					"reason", "already-migrated",
+					"lineage", sourceMeta.Lineage,
+					"serial", fmt.Sprintf("%d", sourceMeta.Serial))
+				if opts.DryRun {
+					m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+						"[reset][bold]Dry run:[reset] would skip workspace %q in %q, which is already migrated",
+						opts.sourceWorkspace, opts.SourceType)))
+				}
+				if opts.recorder != nil {
+					opts.recorder.recordSkipped(opts, "already-migrated")
+				}
+				return nil
+			}
+		}
+	}
+
 	// no reason to migrate if the state is already there
 	if source.Equal(destination) {
 		// Equal isn't identical; it doesn't check lineage.
@@ -331,266 +1465,2438 @@ func (m *Meta) backendMigrateState_s_s(opts *backendMigrateOpts) error {
 		sm2, _ := destinationState.(statemgr.PersistentMeta)
 		if source != nil && destination != nil {
 			if sm1 == nil || sm2 == nil {
-				log.Print("[TRACE] backendMigrateState: both source and destination workspaces have no state, so no migration is needed")
+				opts.log().decision(opts.sourceWorkspace, "skip", "reason", "states-equal")
 				return nil
 			}
 			if sm1.StateSnapshotMeta().Lineage == sm2.StateSnapshotMeta().Lineage {
-				log.Printf("[TRACE] backendMigrateState: both source and destination workspaces have equal state with lineage %q, so no migration is needed", sm1.StateSnapshotMeta().Lineage)
+				opts.log().decision(opts.sourceWorkspace, "skip",
+					"reason", "states-equal",
+					"lineage", sm1.StateSnapshotMeta().Lineage)
 				return nil
 			}
 		}
 	}
 
+	// A copy is apparently needed, so refuse to proceed when the two sides
+	// resolve to the same storage location. Locking the source and then the
+	// destination would contend for the same lock and deadlock against
+	// itself. Same-location pairs whose states are already in sync never get
+	// this far, since the checks above skip them before any lock is taken;
+	// that keeps re-initializing a default local backend working as a no-op.
+	if backendMigrateSameLocation(opts) {
+		opts.log().decision(opts.sourceWorkspace, "abort", "reason", "same-location")
+		return fmt.Errorf(strings.TrimSpace(errMigrateSameLocation),
+			opts.SourceType, opts.DestinationType)
+	}
+
+	// The core copy is handled by MigrateWorkspaceState; everything below
+	// builds the callbacks that carry the CLI-specific concerns (locking UI,
+	// confirmation prompts, dry-run reporting, retries) into it.
+	migrateOpts := MigrateOptions{
+		SourceType:      opts.SourceType,
+		DestinationType: opts.DestinationType,
+		NewLineage:      m.migrateNewLineage,
+	}
+
 	if m.stateLock {
-		lockCtx := context.Background()
-		vt := arguments.ViewJSON
-		// Set default viewtype if none was set as the StateLocker needs to know exactly
-		// what viewType we want to have.
-		if opts == nil || opts.ViewType != vt {
-			vt = arguments.ViewHuman
-		}
-		view := views.NewStateLocker(vt, m.View)
-		locker := clistate.NewLocker(m.stateLockTimeout, view)
-
-		lockerSource := locker.WithContext(lockCtx)
-		if diags := lockerSource.Lock(sourceState, "migration source state"); diags.HasErrors() {
-			return diags.Err()
-		}
-		defer lockerSource.Unlock()
+		migrateOpts.Lock = func(src, dst statemgr.Full) (func() error, error) {
+			// The per-workspace context, so an interrupt cancels a pending
+			// lock acquisition instead of blocking on it.
+			lockCtx := ctx
+			vt := arguments.ViewJSON
+			// Set default viewtype if none was set as the StateLocker needs to know exactly
+			// what viewType we want to have.
+			if opts == nil || opts.ViewType != vt {
+				vt = arguments.ViewHuman
+			}
+			view := views.NewStateLocker(vt, m.View)
+
+			// Each side gets its own lock timeout when an override was
+			// given, since a busy remote destination may need to wait much
+			// longer than a local source (or vice versa).
+			sourceTimeout := m.stateLockTimeout
+			if m.migrateSourceLockTimeout > 0 {
+				sourceTimeout = m.migrateSourceLockTimeout
+			}
+			destinationTimeout := m.stateLockTimeout
+			if m.migrateDestLockTimeout > 0 {
+				destinationTimeout = m.migrateDestLockTimeout
+			}
 
-		lockerDestination := locker.WithContext(lockCtx)
-		if diags := lockerDestination.Lock(destinationState, "migration destination state"); diags.HasErrors() {
-			return diags.Err()
-		}
-		defer lockerDestination.Unlock()
+			lockerSource := clistate.NewLocker(sourceTimeout, view).WithContext(lockCtx)
+			if diags := lockerSource.Lock(src, "migration source state"); diags.HasErrors() {
+				return nil, diags.Err()
+			}
 
-		// We now own a lock, so double check that we have the version
-		// corresponding to the lock.
-		log.Print("[TRACE] backendMigrateState: refreshing source workspace state")
-		if err := sourceState.RefreshState(); err != nil {
-			return fmt.Errorf(strings.TrimSpace(
-				errMigrateSingleLoadDefault), opts.SourceType, err)
+			lockerDestination := clistate.NewLocker(destinationTimeout, view).WithContext(lockCtx)
+			if diags := lockerDestination.Lock(dst, "migration destination state"); diags.HasErrors() {
+				lockerSource.Unlock()
+				return nil, diags.Err()
+			}
+
+			return func() error {
+				lockerDestination.Unlock()
+				lockerSource.Unlock()
+				return nil
+			}, nil
 		}
-		log.Print("[TRACE] backendMigrateState: refreshing destination workspace state")
-		if err := destinationState.RefreshState(); err != nil {
-			return fmt.Errorf(strings.TrimSpace(
-				errMigrateSingleLoadDefault), opts.SourceType, err)
+	}
+
+	// Set by the confirmation callback once a copy is actually going ahead,
+	// so that the post-migration steps below can tell a completed copy apart
+	// from a declined or skipped one.
+	copied := false
+
+	migrateOpts.Confirm = func(src, dst statemgr.Full) (bool, error) {
+		destination := dst.State()
+
+		// Set when a -migrate-conflict strategy already decided the copy
+		// should happen, in which case there's nothing to confirm
+		// interactively.
+		conflictResolved := false
+
+		var confirmFunc func(statemgr.Full, statemgr.Full, *backendMigrateOpts) (bool, error)
+		switch {
+		// We have existing state moving into no state. Ask the user if
+		// they'd like to do this.
+		case destination.Empty():
+			if opts.SourceType == "cloud" || opts.DestinationType == "cloud" {
+				// HACK: backendMigrateTFC has its own earlier prompt for
+				// whether to migrate state in the cloud case, so we'll skip
+				// this later prompt for Cloud, even though we do still need it
+				// for state backends.
+				confirmFunc = func(statemgr.Full, statemgr.Full, *backendMigrateOpts) (bool, error) {
+					return true, nil // the answer is implied to be "yes" if we reached this point
+				}
+			} else {
+				log.Print("[TRACE] backendMigrateState: destination workspace has empty state, so might copy source workspace state")
+				confirmFunc = m.backendMigrateEmptyConfirm
+			}
+
+		// Both states are non-empty, meaning we need to determine which
+		// state should be used and update accordingly.
+		default:
+			log.Print("[TRACE] backendMigrateState: both source and destination workspaces have states, so might overwrite destination with source")
+			if opts.conflictStrategy() != "" {
+				copyNeeded, err := backendMigrateResolveConflict(src, dst, opts)
+				if err != nil {
+					return false, err
+				}
+				if !copyNeeded {
+					opts.log().decision(opts.sourceWorkspace, "skip",
+						"reason", "conflict-strategy",
+						"strategy", opts.conflictStrategy())
+					m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+						"[reset][bold]Skipped workspace %q:[reset] the destination state is not older than the source, per the %q conflict strategy.",
+						opts.sourceWorkspace, opts.conflictStrategy())))
+					if opts.recorder != nil {
+						opts.recorder.recordSkipped(opts, "conflict-strategy")
+					}
+					return false, nil
+				}
+				// The strategy already decided, so there's nothing to ask.
+				conflictResolved = true
+				confirmFunc = func(statemgr.Full, statemgr.Full, *backendMigrateOpts) (bool, error) {
+					return true, nil
+				}
+			} else {
+				confirmFunc = m.backendMigrateNonEmptyConfirm
+			}
 		}
 
-		source = sourceState.State()
-		destination = destinationState.State()
-	}
+		// In a dry run we've now learned everything we need: report what
+		// would happen and stop before anything is written to the
+		// destination.
+		if opts.DryRun {
+			opts.log().decision(opts.sourceWorkspace, "skip", "reason", "dry-run")
+			m.Ui.Output(m.Colorize().Color(formatMigrateDryRun(opts, src, destination)))
+			return false, nil
+		}
 
-	var confirmFunc func(statemgr.Full, statemgr.Full, *backendMigrateOpts) (bool, error)
-	switch {
-	// No migration necessary
-	case source.Empty() && destination.Empty():
-		log.Print("[TRACE] backendMigrateState: both source and destination workspaces have empty state, so no migration is required")
-		return nil
+		// A copy into an empty destination carries no risk of losing state,
+		// so forceEmpty auto-confirms it while overwrites of a non-empty
+		// destination still go through the prompt below.
+		forced := opts.force || (opts.forceEmpty && destination.Empty())
 
-	// No migration necessary if we're inheriting state.
-	case source.Empty() && !destination.Empty():
-		log.Print("[TRACE] backendMigrateState: source workspace has empty state, so no migration is required")
-		return nil
+		if !forced && !conflictResolved {
+			// Abort if we can't ask for input.
+			if !m.input {
+				log.Print("[TRACE] backendMigrateState: can't prompt for input, so aborting migration")
+				return false, errors.New(strings.TrimSpace(errInteractiveInputDisabled))
+			}
 
-	// We have existing state moving into no state. Ask the user if
-	// they'd like to do this.
-	case !source.Empty() && destination.Empty():
-		if opts.SourceType == "cloud" || opts.DestinationType == "cloud" {
-			// HACK: backendMigrateTFC has its own earlier prompt for
-			// whether to migrate state in the cloud case, so we'll skip
-			// this later prompt for Cloud, even though we do still need it
-			// for state backends.
-			confirmFunc = func(statemgr.Full, statemgr.Full, *backendMigrateOpts) (bool, error) {
-				return true, nil // the answer is implied to be "yes" if we reached this point
+			// Confirm with the user whether we want to copy state over
+			confirm, err := confirmFunc(src, dst, opts)
+			if err != nil {
+				log.Print("[TRACE] backendMigrateState: error reading input, so aborting migration")
+				return false, err
+			}
+			if !confirm {
+				opts.log().decision(opts.sourceWorkspace, "abort", "reason", "declined")
+				return false, nil
 			}
-		} else {
-			log.Print("[TRACE] backendMigrateState: destination workspace has empty state, so might copy source workspace state")
-			confirmFunc = m.backendMigrateEmptyConfirm
 		}
 
-	// Both states are non-empty, meaning we need to determine which
-	// state should be used and update accordingly.
-	case !source.Empty() && !destination.Empty():
-		log.Print("[TRACE] backendMigrateState: both source and destination workspaces have states, so might overwrite destination with source")
-		confirmFunc = m.backendMigrateNonEmptyConfirm
-	}
+		// The destination's current state is about to be replaced, so if the
+		// user asked for backups then snapshot it to disk first.
+		if opts.BackupDir != "" && !destination.Empty() {
+			if err := m.backendMigrateBackupDestination(opts, dst); err != nil {
+				return false, err
+			}
+		}
 
-	if confirmFunc == nil {
-		panic("confirmFunc must not be nil")
+		copied = true
+		return true, nil
 	}
 
-	if !opts.force {
-		// Abort if we can't ask for input.
-		if !m.input {
-			log.Print("[TRACE] backendMigrateState: can't prompt for input, so aborting migration")
-			return errors.New(strings.TrimSpace(errInteractiveInputDisabled))
+	migrateOpts.PrePersist = func(dst statemgr.Full) error {
+		if len(opts.providerRenames) > 0 {
+			// Rewrite provider addresses on the copy held by the destination
+			// manager before it persists, so that the source state keeps its
+			// original provider references untouched.
+			renamedState := dst.State().DeepCopy()
+			applied := renameStateProviders(renamedState, opts.providerRenames)
+			if len(applied) > 0 {
+				if err := dst.WriteState(renamedState); err != nil {
+					return err
+				}
+				m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+					"[reset][bold]Renamed providers for %d resource(s) in workspace %q:[reset]",
+					len(applied), opts.destinationWorkspace)))
+				for _, line := range applied {
+					m.Ui.Output(fmt.Sprintf("  - %s", line))
+				}
+			} else {
+				log.Printf("[TRACE] backendMigrateState: no provider renames apply in workspace %q", opts.destinationWorkspace)
+			}
 		}
 
-		// Confirm with the user whether we want to copy state over
-		confirm, err := confirmFunc(sourceState, destinationState, opts)
-		if err != nil {
-			log.Print("[TRACE] backendMigrateState: error reading input, so aborting migration")
-			return err
-		}
-		if !confirm {
-			log.Print("[TRACE] backendMigrateState: user cancelled at confirmation prompt, so aborting migration")
-			return nil
+		if opts.PruneDeposed {
+			// Prune the copy held by the destination manager before it
+			// persists, so that the source state keeps its deposed objects
+			// untouched.
+			prunedState := dst.State().DeepCopy()
+			pruned := pruneDeposedObjects(prunedState)
+			if len(pruned) > 0 {
+				if err := dst.WriteState(prunedState); err != nil {
+					return err
+				}
+				m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+					"[reset][bold]Pruned %d deposed object(s) from workspace %q:[reset]",
+					len(pruned), opts.destinationWorkspace)))
+				for _, line := range pruned {
+					m.Ui.Output(fmt.Sprintf("  - %s", line))
+				}
+			} else {
+				log.Printf("[TRACE] backendMigrateState: no deposed objects to prune in workspace %q", opts.destinationWorkspace)
+			}
 		}
-	}
 
-	// Confirmed! We'll have the statemgr package handle the migration, which
-	// includes preserving any lineage/serial information where possible, if
-	// both managers support such metadata.
-	log.Print("[TRACE] backendMigrateState: migration confirmed, so migrating")
-	if err := statemgr.Migrate(destinationState, sourceState); err != nil {
-		return fmt.Errorf(strings.TrimSpace(errBackendStateCopy),
-			opts.SourceType, opts.DestinationType, err)
+		return nil
 	}
+
 	// The backend is currently handled before providers are installed during init,
 	// so requiring schemas here could lead to a catch-22 where it requires some manual
 	// intervention to proceed far enough for provider installation. To avoid this,
 	// when migrating to HCP Terraform backend, the initial JSON varient of state won't be generated and stored.
-	if err := destinationState.PersistState(nil); err != nil {
-		return fmt.Errorf(strings.TrimSpace(errBackendStateCopy),
-			opts.SourceType, opts.DestinationType, err)
-	}
-
-	// And we're done.
-	return nil
-}
-
-func (m *Meta) backendMigrateEmptyConfirm(source, destination statemgr.Full, opts *backendMigrateOpts) (bool, error) {
-	var inputOpts *terraform.InputOpts
-	if opts.DestinationType == "cloud" {
-		appName := "HCP Terraform"
-		if cloudBackend, ok := opts.Destination.(*cloud.Cloud); ok {
-			appName = cloudBackend.AppName()
-		}
-		inputOpts = &terraform.InputOpts{
-			Id:          "backend-migrate-copy-to-empty-cloud",
-			Query:       "Do you want to copy existing state to HCP Terraform?",
-			Description: fmt.Sprintf(strings.TrimSpace(inputBackendMigrateEmptyCloud), opts.SourceType, appName),
+	migrateOpts.Persist = func(dst statemgr.Full) error {
+		_, persistSpan := tracer.Start(ctx, "persist state")
+		defer persistSpan.End()
+		// A failed attempt may have left the remote in an unknown condition,
+		// so before each retry the snapshot is re-read and the migrated state
+		// written again on top of it, re-checking lineage and serial against
+		// whatever is stored now. Any locks stay held throughout.
+		writtenState := dst.State()
+		revalidate := func() error {
+			if err := dst.RefreshState(); err != nil {
+				return fmt.Errorf(strings.TrimSpace(
+					errMigrateSingleLoadDefault), opts.DestinationType, err)
+			}
+			return dst.WriteState(writtenState)
 		}
-	} else {
-		inputOpts = &terraform.InputOpts{
-			Id:    "backend-migrate-copy-to-empty",
-			Query: "Do you want to copy existing state to the new backend?",
-			Description: fmt.Sprintf(
-				strings.TrimSpace(inputBackendMigrateEmpty),
-				opts.SourceType, opts.DestinationType),
+		if err := m.backendMigrateRetry(opts,
+			fmt.Sprintf("persisting workspace %q to the %q backend", opts.destinationWorkspace, opts.DestinationType),
+			revalidate, func() error { return dst.PersistState(nil) }); err != nil {
+			persistSpan.SetStatus(codes.Error, "persisting state failed")
+			return err
 		}
+		return nil
 	}
 
-	return m.confirm(inputOpts)
-}
+	copyStarted := time.Now()
+	if err := MigrateWorkspaceState(destinationState, sourceState, migrateOpts); err != nil {
+		span.SetStatus(codes.Error, "state migration failed")
+		return err
+	}
+	copyDuration := time.Since(copyStarted)
 
-func (m *Meta) backendMigrateNonEmptyConfirm(
-	sourceState, destinationState statemgr.Full, opts *backendMigrateOpts) (bool, error) {
-	// We need to grab both states so we can write them to a file
-	source := sourceState.State()
-	destination := destinationState.State()
+	if !copied {
+		// The migration was declined or skipped before anything was written,
+		// so there's nothing further to verify or record.
+		return nil
+	}
 
-	// Save both to a temporary
-	td, err := ioutil.TempDir("", "terraform")
-	if err != nil {
-		return false, fmt.Errorf("Error creating temporary directory: %s", err)
+	if opts.PreserveSerial {
+		if err := m.backendMigratePreserveSerial(opts, sourceState, destinationState); err != nil {
+			return err
+		}
 	}
-	defer os.RemoveAll(td)
 
-	// Helper to write the state
-	saveHelper := func(n, path string, s *states.State) error {
-		mgr := statemgr.NewFilesystem(path)
-		return mgr.WriteState(s)
+	if opts.Verify {
+		_, verifySpan := tracer.Start(ctx, "verify state")
+		if err := m.backendMigrateVerify(opts, destinationState); err != nil {
+			verifySpan.SetStatus(codes.Error, "verification failed")
+			verifySpan.End()
+			return err
+		}
+		verifySpan.End()
 	}
 
-	// Write the states
-	sourcePath := filepath.Join(td, fmt.Sprintf("1-%s.tfstate", opts.SourceType))
-	destinationPath := filepath.Join(td, fmt.Sprintf("2-%s.tfstate", opts.DestinationType))
-	if err := saveHelper(opts.SourceType, sourcePath, source); err != nil {
-		return false, fmt.Errorf("Error saving temporary state: %s", err)
+	if opts.RecordProvenance {
+		m.recordMigrationProvenance(destinationState, opts)
 	}
-	if err := saveHelper(opts.DestinationType, destinationPath, destination); err != nil {
-		return false, fmt.Errorf("Error saving temporary state: %s", err)
+
+	if opts.recorder != nil {
+		opts.recorder.recordCopied(opts, destinationState)
 	}
 
-	// Ask for confirmation
-	var inputOpts *terraform.InputOpts
-	if opts.DestinationType == "cloud" {
-		appName := "HCP Terraform"
-		if cloudBackend, ok := opts.Destination.(*cloud.Cloud); ok {
-			appName = cloudBackend.AppName()
-		}
-		inputOpts = &terraform.InputOpts{
-			Id:    "backend-migrate-to-tfc",
-			Query: "Do you want to copy existing state to HCP Terraform?",
-			Description: fmt.Sprintf(
-				strings.TrimSpace(inputBackendMigrateNonEmptyCloud),
-				opts.SourceType, sourcePath, destinationPath, appName),
-		}
+	if opts.stats != nil {
+		opts.stats.record(opts, sourceState, copyDuration)
+	}
+
+	opts.log().decision(opts.sourceWorkspace, "copy",
+		"destination_workspace", opts.destinationWorkspace,
+		"duration", copyDuration.Round(time.Millisecond).String())
+
+	// Record exactly what ended up stored where, as an audit trail of the
+	// migration result. The snapshot metadata is read only now, after any
+	// serial rewrite above, so the logged serial is the one that persisted.
+	if meta, ok := destinationState.(statemgr.PersistentMeta); ok {
+		snapshot := meta.StateSnapshotMeta()
+		log.Printf("[INFO] backendMigrateState: wrote workspace %q to the %q backend with lineage %q serial %d",
+			opts.destinationWorkspace, opts.DestinationType, snapshot.Lineage, snapshot.Serial)
 	} else {
-		inputOpts = &terraform.InputOpts{
-			Id:    "backend-migrate-to-backend",
-			Query: "Do you want to copy existing state to the new backend?",
-			Description: fmt.Sprintf(
-				strings.TrimSpace(inputBackendMigrateNonEmpty),
-				opts.SourceType, opts.DestinationType, sourcePath, destinationPath),
+		log.Printf("[INFO] backendMigrateState: wrote workspace %q to the %q backend; its state manager does not expose snapshot metadata, so the stored lineage and serial are unavailable",
+			opts.destinationWorkspace, opts.DestinationType)
+	}
+
+	if opts.deleteSourceAfterCopy {
+		// Reset before deleting so that a later workspace sharing these
+		// opts doesn't inherit the answer.
+		opts.deleteSourceAfterCopy = false
+		log.Printf("[INFO] backendMigrateState: deleting workspace %q from the source backend after a successful copy", opts.sourceWorkspace)
+		if err := opts.Source.DeleteWorkspace(opts.sourceWorkspace, false); err != nil {
+			return fmt.Errorf(strings.TrimSpace(errMigrateDeleteSourceAfterCopy),
+				opts.sourceWorkspace, opts.SourceType, err)
 		}
+		opts.log().decision(opts.sourceWorkspace, "delete-source",
+			"reason", "copy-and-delete")
+		m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+			"[reset][bold]Deleted workspace %q from the %q backend after a successful copy.[reset]",
+			opts.sourceWorkspace, opts.SourceType)))
 	}
 
-	// Confirm with the user that the copy should occur
-	return m.confirm(inputOpts)
+	// And we're done.
+	return nil
 }
 
-func retrieveWorkspaces(back backend.Backend, sourceType string) ([]string, bool, error) {
-	var singleState bool
-	var err error
-	workspaces, err := back.Workspaces()
-	if err == backend.ErrWorkspacesNotSupported {
-		singleState = true
-		err = nil
+// backendMigratePreserveSerial re-asserts the source's state serial on the
+// destination after a copy has been persisted. When the destination's state
+// manager supports metadata writes, a differing serial is rewritten to the
+// source's and persisted again; when it doesn't, a warning explains that the
+// serial will differ so serial-tracking tooling isn't silently broken.
+func (m *Meta) backendMigratePreserveSerial(opts *backendMigrateOpts, sourceState, destinationState statemgr.Full) error {
+	sourceMeta, ok := sourceState.(statemgr.PersistentMeta)
+	if !ok {
+		// Without source metadata there is no serial to preserve.
+		log.Printf("[TRACE] backendMigrateState: the %q backend does not expose snapshot metadata, so there is no serial to preserve", opts.SourceType)
+		return nil
 	}
-	if err != nil {
-		return nil, singleState, fmt.Errorf(strings.TrimSpace(
-			errMigrateLoadStates), sourceType, err)
+	wantSerial := sourceMeta.StateSnapshotMeta().Serial
+
+	destinationMeta, hasMeta := destinationState.(statemgr.PersistentMeta)
+	migrator, hasMigrator := destinationState.(statemgr.Migrator)
+	if !hasMeta || !hasMigrator {
+		m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+			"[reset][yellow]The %q backend does not support setting the state serial explicitly, so\nworkspace %q was copied with serial %d in the source but may be stored under\na different serial in the destination.[reset]",
+			opts.DestinationType, opts.destinationWorkspace, wantSerial)))
+		return nil
 	}
 
-	return workspaces, singleState, err
-}
-
-func (m *Meta) backendMigrateTFC(opts *backendMigrateOpts) error {
-	_, sourceTFC := opts.Source.(*cloud.Cloud)
-	cloudBackendDestination, destinationTFC := opts.Destination.(*cloud.Cloud)
+	if destinationMeta.StateSnapshotMeta().Serial == wantSerial {
+		log.Printf("[TRACE] backendMigrateState: destination workspace %q already has serial %d", opts.destinationWorkspace, wantSerial)
+		return nil
+	}
 
-	sourceWorkspaces, sourceSingleState, err := retrieveWorkspaces(opts.Source, opts.SourceType)
-	if err != nil {
-		return err
+	log.Printf("[INFO] backendMigrateState: rewriting destination workspace %q serial from %d to %d", opts.destinationWorkspace, destinationMeta.StateSnapshotMeta().Serial, wantSerial)
+	f := statemgr.Export(destinationState)
+	f.Serial = wantSerial
+	if err := migrator.WriteStateForMigration(f, true); err != nil {
+		return fmt.Errorf(strings.TrimSpace(errMigratePreserveSerial),
+			opts.destinationWorkspace, opts.DestinationType, err)
 	}
-	//to be used below, not yet implamented
-	// destinationWorkspaces, destinationSingleState
-	_, _, err = retrieveWorkspaces(opts.Destination, opts.SourceType)
-	if err != nil {
-		return err
+	if err := destinationState.PersistState(nil); err != nil {
+		return fmt.Errorf(strings.TrimSpace(errMigratePreserveSerial),
+			opts.destinationWorkspace, opts.DestinationType, err)
 	}
-
-	// from HCP Terraform to non-TFC backend
-	if sourceTFC && !destinationTFC {
-		// From HCP Terraform to another backend. This is not yet implemented, and
-		// we recommend people to use the HCP Terraform API.
-		return fmt.Errorf(strings.TrimSpace(errTFCMigrateNotYetImplemented))
+	if got := destinationMeta.StateSnapshotMeta().Serial; got != wantSerial {
+		// The manager accepted the write but still stored a different
+		// serial, so be honest about it rather than claiming success.
+		m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+			"[reset][yellow]The %q backend stored workspace %q under serial %d rather than the source's\nserial %d.[reset]",
+			opts.DestinationType, opts.destinationWorkspace, got, wantSerial)))
 	}
+	return nil
+}
 
-	// Everything below, by the above two conditionals, now assumes that the
-	// destination is always HCP Terraform.
-	sourceSingle := sourceSingleState || (len(sourceWorkspaces) == 1)
-	if sourceSingle {
-		if cloudBackendDestination.WorkspaceMapping.Strategy() == cloud.WorkspaceNameStrategy {
-			// If we know the name via WorkspaceNameStrategy, then set the
-			// destinationWorkspace to the new Name and skip the user prompt. Here the
-			// destinationWorkspace is not set to `default` thereby we will create it
-			// in HCP Terraform if it does not exist.
-			opts.destinationWorkspace = cloudBackendDestination.WorkspaceMapping.Name
-		}
+// MigrateOptions configures a call to MigrateWorkspaceState. The zero value
+// migrates unconditionally with no locking, which is suitable only when the
+// caller has already arranged both.
+type MigrateOptions struct {
+	// SourceType and DestinationType name the backends on either side of the
+	// migration, and appear only in error messages.
+	SourceType      string
+	DestinationType string
+
+	// Lock, when non-nil, is called to lock both state managers before any
+	// comparison or copy, and must return a function that releases whatever
+	// it acquired. Once the lock is held both managers are refreshed again so
+	// the copy works from the locked snapshots.
+	Lock func(src, dst statemgr.Full) (func() error, error)
+
+	// Confirm, when non-nil, is consulted once it's established that a copy
+	// is actually needed, before anything is written to the destination.
+	// Returning false abandons the migration without error.
+	Confirm func(src, dst statemgr.Full) (bool, error)
+
+	// PrePersist, when non-nil, runs after the source state has been copied
+	// into the destination manager but before it persists, and may rewrite
+	// the held copy via dst.WriteState. The source manager is never modified.
+	PrePersist func(dst statemgr.Full) error
+
+	// Persist, when non-nil, replaces the default dst.PersistState(nil) call,
+	// e.g. to add retries around a flaky remote.
+	Persist func(dst statemgr.Full) error
+
+	// NewLineage assigns a freshly generated lineage to the destination
+	// instead of inheriting the source's, decoupling the destination's
+	// history from the source. It also disables the equal-state no-op, which
+	// keys on lineage equality, so re-running the same migration will copy
+	// again rather than being recognized as already done.
+	NewLineage bool
+}
+
+// MigrateWorkspaceState copies the state held by src into dst, preserving
+// lineage and serial metadata where both managers support it. This is the
+// core of "terraform init" state migration, exposed so that other callers can
+// drive the same copy logic with their own locking and confirmation behavior
+// via the callbacks in opts.
+//
+// Both managers must already have been refreshed by the caller; when
+// opts.Lock is set they are refreshed again under the lock. If both sides
+// already hold equal state with the same lineage, or the source is empty,
+// the call is a no-op and returns nil.
+func MigrateWorkspaceState(dst, src statemgr.Full, opts MigrateOptions) error {
+	if opts.Lock != nil {
+		unlock, err := opts.Lock(src, dst)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		// We now own a lock, so double check that we have the version
+		// corresponding to the lock.
+		log.Print("[TRACE] MigrateWorkspaceState: refreshing source workspace state")
+		if err := src.RefreshState(); err != nil {
+			return fmt.Errorf(strings.TrimSpace(
+				errMigrateSingleLoadDefault), opts.SourceType, err)
+		}
+		log.Print("[TRACE] MigrateWorkspaceState: refreshing destination workspace state")
+		if err := dst.RefreshState(); err != nil {
+			return fmt.Errorf(strings.TrimSpace(
+				errMigrateSingleLoadDefault), opts.DestinationType, err)
+		}
+	}
+
+	source := src.State()
+	destination := dst.State()
+
+	// There's no reason to copy if the source has nothing to offer: an empty
+	// source never overwrites a populated destination.
+	if source.Empty() {
+		log.Print("[TRACE] MigrateWorkspaceState: source workspace has empty state, so no migration is required")
+		return nil
+	}
+
+	// ...and no reason to copy if the state is already there. When a new
+	// lineage has been requested this shortcut is disabled, since the whole
+	// point is to rewrite the destination's metadata.
+	if source.Equal(destination) && !opts.NewLineage {
+		// Equal isn't identical; it doesn't check lineage.
+		sm1, _ := src.(statemgr.PersistentMeta)
+		sm2, _ := dst.(statemgr.PersistentMeta)
+		if source != nil && destination != nil {
+			if sm1 == nil || sm2 == nil {
+				log.Print("[TRACE] MigrateWorkspaceState: source and destination workspaces have equal state, so no migration is needed")
+				return nil
+			}
+			if sm1.StateSnapshotMeta().Lineage == sm2.StateSnapshotMeta().Lineage {
+				log.Printf("[TRACE] MigrateWorkspaceState: source and destination workspaces have equal state with lineage %q, so no migration is needed", sm1.StateSnapshotMeta().Lineage)
+				return nil
+			}
+		}
+	}
+
+	if opts.Confirm != nil {
+		confirm, err := opts.Confirm(src, dst)
+		if err != nil {
+			return err
+		}
+		if !confirm {
+			log.Print("[TRACE] MigrateWorkspaceState: caller declined the copy, so aborting migration")
+			return nil
+		}
+	}
+
+	// Confirmed! We'll have the statemgr package handle the migration, which
+	// includes preserving any lineage/serial information where possible, if
+	// both managers support such metadata.
+	log.Print("[TRACE] MigrateWorkspaceState: migration confirmed, so migrating")
+	if opts.NewLineage {
+		f := statemgr.Export(src)
+		f.Lineage = statemgr.NewLineage()
+		log.Printf("[TRACE] MigrateWorkspaceState: assigning new lineage %q to the destination", f.Lineage)
+		if err := statemgr.Import(f, dst, true); err != nil {
+			return fmt.Errorf(strings.TrimSpace(errBackendStateCopy),
+				opts.SourceType, opts.DestinationType, err)
+		}
+	} else if err := statemgr.Migrate(dst, src); err != nil {
+		return fmt.Errorf(strings.TrimSpace(errBackendStateCopy),
+			opts.SourceType, opts.DestinationType, err)
+	}
+
+	if opts.PrePersist != nil {
+		if err := opts.PrePersist(dst); err != nil {
+			return fmt.Errorf(strings.TrimSpace(errBackendStateCopy),
+				opts.SourceType, opts.DestinationType, err)
+		}
+	}
+
+	persist := opts.Persist
+	if persist == nil {
+		persist = func(dst statemgr.Full) error {
+			return dst.PersistState(nil)
+		}
+	}
+	if err := persist(dst); err != nil {
+		return fmt.Errorf(strings.TrimSpace(errBackendStateCopy),
+			opts.SourceType, opts.DestinationType, err)
+	}
+
+	return nil
+}
+
+// backendRenameWorkspace renames a workspace within a single backend by
+// migrating its state to the new name and optionally deleting the old
+// workspace afterwards. The copy reuses the ordinary single-workspace
+// migration path with the same backend on both sides; workspace locks are
+// held per workspace, so locking the source and destination there cannot
+// deadlock against itself. The destination workspace must not already hold
+// state, since a rename must never destroy anything.
+func (m *Meta) backendRenameWorkspace(b backend.Backend, backendType, oldName, newName string, deleteOld bool) error {
+	if newName == oldName {
+		return fmt.Errorf("Cannot rename workspace %q to itself.", oldName)
+	}
+	if !validWorkspaceName(newName) {
+		return fmt.Errorf("Invalid workspace name %q.", newName)
+	}
+
+	destinationState, err := b.StateMgr(newName)
+	if err != nil {
+		return fmt.Errorf(strings.TrimSpace(
+			errMigrateSingleLoadDefault), backendType, err)
+	}
+	if err := destinationState.RefreshState(); err != nil {
+		return fmt.Errorf(strings.TrimSpace(
+			errMigrateSingleLoadDefault), backendType, err)
+	}
+	if !destinationState.State().Empty() {
+		return fmt.Errorf(strings.TrimSpace(errRenameWorkspaceExists),
+			newName, oldName)
+	}
+
+	log.Printf("[INFO] backendRenameWorkspace: renaming workspace %q to %q within the %q backend", oldName, newName, backendType)
+	opts := &backendMigrateOpts{
+		SourceType:           backendType,
+		DestinationType:      backendType,
+		Source:               b,
+		Destination:          b,
+		sourceWorkspace:      oldName,
+		destinationWorkspace: newName,
+		force:                true,
+	}
+	// Renames are invoked outside of any migration, so there is no trace to
+	// continue here; the copy starts its own span from a fresh context.
+	if err := m.backendMigrateState_s_s(context.Background(), opts); err != nil {
+		return err
+	}
+
+	// Keep the user's selection pointed at the renamed workspace.
+	if current, err := m.Workspace(); err == nil && current == oldName {
+		if err := m.SetWorkspace(newName); err != nil {
+			return fmt.Errorf("Failed to select the renamed workspace: %s", err)
+		}
+	}
+
+	if deleteOld {
+		if err := b.DeleteWorkspace(oldName, false); err != nil {
+			return fmt.Errorf(strings.TrimSpace(errRenameWorkspaceDelete),
+				oldName, newName, err)
+		}
+	}
+
+	return nil
+}
+
+// backendMigrateVerify re-reads a workspace from the destination after its
+// state has been persisted and checks that what comes back matches what was
+// written, both in content and in snapshot metadata where the manager exposes
+// it. The comparison is against the state the destination manager just
+// persisted rather than the raw source, so deliberate transformations such as
+// provider renames or deposed-object pruning don't trip it.
+// backendMigrateBackupDestination writes the destination workspace's current
+// state snapshot to a file in opts.BackupDir, so that it can be restored by
+// hand if the migration that is about to overwrite it turns out to be a
+// mistake. It is only called when that snapshot is non-empty.
+func (m *Meta) backendMigrateBackupDestination(opts *backendMigrateOpts, destinationState statemgr.Full) error {
+	file := statemgr.Export(destinationState)
+	if file == nil {
+		// A nil snapshot means the destination has nothing worth backing up
+		// after all, despite the state object being non-empty in memory.
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := statefile.Write(file, &buf); err != nil {
+		return fmt.Errorf(strings.TrimSpace(errMigrateBackup),
+			opts.destinationWorkspace, err)
+	}
+
+	if err := os.MkdirAll(opts.BackupDir, 0755); err != nil {
+		return fmt.Errorf(strings.TrimSpace(errMigrateBackup),
+			opts.destinationWorkspace, err)
+	}
+
+	path := filepath.Join(opts.BackupDir, fmt.Sprintf("terraform.%s.backup.tfstate", opts.destinationWorkspace))
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf(strings.TrimSpace(errMigrateBackup),
+			opts.destinationWorkspace, err)
+	}
+
+	log.Printf("[TRACE] backendMigrateState: backed up destination workspace %q to %s", opts.destinationWorkspace, path)
+	m.Ui.Output(fmt.Sprintf("Backed up the previous state of workspace %q to %s.",
+		opts.destinationWorkspace, path))
+	return nil
+}
+
+func (m *Meta) backendMigrateVerify(opts *backendMigrateOpts, destinationState statemgr.Full) error {
+	expected := destinationState.State()
+	var expectedMeta statemgr.SnapshotMeta
+	expectedHasMeta := false
+	if sm, ok := destinationState.(statemgr.PersistentMeta); ok {
+		expectedMeta = sm.StateSnapshotMeta()
+		expectedHasMeta = true
+	}
+
+	log.Printf("[TRACE] backendMigrateState: re-reading workspace %q from the destination to verify the copy", opts.destinationWorkspace)
+	if err := destinationState.RefreshState(); err != nil {
+		return fmt.Errorf(strings.TrimSpace(errMigrateVerify),
+			opts.destinationWorkspace, opts.DestinationType,
+			fmt.Sprintf("an error instead of the state: %s", err))
+	}
+
+	if !expected.Equal(destinationState.State()) {
+		return fmt.Errorf(strings.TrimSpace(errMigrateVerify),
+			opts.destinationWorkspace, opts.DestinationType,
+			"state content that differs from what was written")
+	}
+	if expectedHasMeta {
+		if sm, ok := destinationState.(statemgr.PersistentMeta); ok {
+			meta := sm.StateSnapshotMeta()
+			if meta.Lineage != expectedMeta.Lineage {
+				return fmt.Errorf(strings.TrimSpace(errMigrateVerify),
+					opts.destinationWorkspace, opts.DestinationType,
+					fmt.Sprintf("lineage %q instead of the written %q", meta.Lineage, expectedMeta.Lineage))
+			}
+			if meta.Serial != expectedMeta.Serial {
+				return fmt.Errorf(strings.TrimSpace(errMigrateVerify),
+					opts.destinationWorkspace, opts.DestinationType,
+					fmt.Sprintf("serial %d instead of the written %d", meta.Serial, expectedMeta.Serial))
+			}
+		}
+	}
+
+	log.Printf("[TRACE] backendMigrateState: verified workspace %q in the destination matches what was written", opts.destinationWorkspace)
+	return nil
+}
+
+// recordMigrationProvenance stamps a note into the destination state's
+// metadata recording where the migrated state came from, when it was
+// migrated, and by whom. The migration itself has already completed by the
+// time this runs, so a destination that can't store metadata (or fails to)
+// produces only a warning.
+func (m *Meta) recordMigrationProvenance(destinationState statemgr.Full, opts *backendMigrateOpts) {
+	recorder, ok := destinationState.(statemgr.Annotator)
+	if !ok {
+		log.Printf("[DEBUG] backendMigrateState: destination state manager doesn't support metadata, so not recording provenance")
+		warn := func() {
+			m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+				"[reset][yellow]The %q backend does not support state metadata, so no provenance\nannotation was recorded for this migration.[reset]",
+				opts.DestinationType)))
+		}
+		if opts.provenanceWarnOnce != nil {
+			opts.provenanceWarnOnce.Do(warn)
+		} else {
+			warn()
+		}
+		return
+	}
+
+	username := "unknown"
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		username = current.Username
+	}
+	note := fmt.Sprintf("migrated from %q on %s by %s",
+		opts.SourceType, time.Now().UTC().Format(time.RFC3339), username)
+
+	log.Printf("[TRACE] backendMigrateState: recording provenance annotation %q", note)
+	if err := recorder.RecordAnnotation("migration-provenance", note); err != nil {
+		m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+			"[reset][yellow]Failed to record a provenance annotation in the %q backend: %s\nThe migrated state itself is unaffected.[reset]",
+			opts.DestinationType, err)))
+	}
+}
+
+// backendMigrateSelectStrategy explains the migration path that
+// backendMigrateChooseStrategy detected and lets the user pick a different
+// one, for -migrate-interactive-select. Only genuinely ambiguous situations
+// prompt: both backends must support multiple workspaces and neither may be
+// HCP Terraform, since otherwise there is only one possible mode (or, for
+// HCP Terraform, a dedicated flow with its own prompts). In the unambiguous
+// cases the detected strategy is kept as-is.
+func (m *Meta) backendMigrateSelectStrategy(opts *backendMigrateOpts, detected backendMigrateStrategy,
+	sourceTFC, destinationTFC, sourceSingleState, destinationSingleState bool, sourceWorkspaces []string) (backendMigrateStrategy, error) {
+
+	if sourceTFC || destinationTFC || sourceSingleState || destinationSingleState {
+		log.Printf("[TRACE] backendMigrateState: only the %q migration path is possible, so not prompting for a mode", detected)
+		return detected, nil
+	}
+
+	if !m.input {
+		log.Print("[TRACE] backendMigrateState: can't prompt for input, so aborting migration")
+		return detected, errors.New(strings.TrimSpace(errInteractiveInputDisabled))
+	}
+
+	currentWorkspace, err := m.Workspace()
+	if err != nil {
+		return detected, err
+	}
+
+	mode, err := m.UIInput().Input(context.Background(), &terraform.InputOpts{
+		Id:    "backend-migrate-select-strategy",
+		Query: "[reset][bold][yellow]How should Terraform migrate your workspaces?[reset]",
+		Description: fmt.Sprintf(
+			strings.TrimSpace(inputBackendMigrateSelectStrategy),
+			opts.SourceType, opts.DestinationType, detected,
+			strings.Join(sourceWorkspaces, ", "), currentWorkspace),
+	})
+	if err != nil {
+		return detected, fmt.Errorf("Error asking for state migration action: %s", err)
+	}
+
+	switch mode {
+	case "1":
+		return migrateStrategyMultiToMulti, nil
+	case "2":
+		// Single-to-single copies whatever workspaces are named in opts, so
+		// aim it at the current workspace rather than the defaults.
+		opts.sourceWorkspace = currentWorkspace
+		opts.destinationWorkspace = currentWorkspace
+		return migrateStrategySingle, nil
+	default:
+		return detected, fmt.Errorf("Please select 1 or 2 as part of this option.")
+	}
+}
+
+// backendMigrateCheckWorkspaceLimit enforces a cap on the number of
+// workspaces a multi-workspace migration may leave in the destination,
+// aborting before any workspace is created. The limit comes from
+// -migrate-workspace-limit when set, or otherwise from the destination
+// backend itself when it implements backend.WorkspaceCountLimiter. Source
+// workspaces whose names already exist in the destination don't count as new,
+// though later renaming (workspace name patterns, collision suffixes) can
+// make this an approximation; the total count is unaffected by renames.
+func (m *Meta) backendMigrateCheckWorkspaceLimit(opts *backendMigrateOpts, sourceWorkspaces, destinationWorkspaces []string) error {
+	limit := m.migrateWorkspaceLimit
+	limitSource := "the -migrate-workspace-limit option"
+	if limit == 0 {
+		if limiter, ok := opts.Destination.(backend.WorkspaceCountLimiter); ok {
+			limit = limiter.WorkspaceLimit()
+			limitSource = fmt.Sprintf("the %q backend", opts.DestinationType)
+		}
+	}
+	if limit == 0 {
+		return nil
+	}
+
+	existing := make(map[string]bool, len(destinationWorkspaces))
+	for _, name := range destinationWorkspaces {
+		existing[name] = true
+	}
+	newCount := 0
+	for _, name := range sourceWorkspaces {
+		if !existing[name] {
+			newCount++
+		}
+	}
+
+	projected := len(destinationWorkspaces) + newCount
+	log.Printf("[TRACE] backendMigrateState: migration would leave the destination with %d workspaces; limit is %d", projected, limit)
+	if projected > limit {
+		return fmt.Errorf(strings.TrimSpace(errMigrateWorkspaceLimit),
+			newCount, len(destinationWorkspaces), projected, limit, limitSource)
+	}
+	return nil
+}
+
+// backendMigrateCheckDestinationVersions compares this CLI's Terraform
+// version against the version each destination workspace is pinned to, for
+// destinations that can report one. A workspace pinned to a newer version
+// than the local CLI is accepted by the destination's own compatibility
+// check, but after migration this CLI may be unable to operate on the state
+// it just copied there, so by default each such workspace gets a warning.
+// Under -migrate-strict-version the first one aborts the migration instead,
+// before anything is copied.
+func (m *Meta) backendMigrateCheckDestinationVersions(opts *backendMigrateOpts, destinationWorkspaces []string) error {
+	versioned, ok := opts.Destination.(BackendWithWorkspaceVersions)
+	if !ok {
+		return nil
+	}
+
+	local := version.Must(version.NewSemver(tfversion.String()))
+	for _, workspace := range destinationWorkspaces {
+		pinned, err := versioned.WorkspaceTerraformVersion(workspace)
+		if err != nil {
+			return fmt.Errorf("Error reading Terraform version for workspace %q: %s", workspace, err)
+		}
+		if pinned == "" {
+			// The workspace doesn't pin a concrete version, so there's
+			// nothing to compare against.
+			continue
+		}
+		remote, err := version.NewSemver(pinned)
+		if err != nil {
+			log.Printf("[WARN] backendMigrateState: workspace %q has unparseable Terraform version %q; skipping version check", workspace, pinned)
+			continue
+		}
+		if !remote.GreaterThan(local) {
+			continue
+		}
+		if m.migrateStrictVersion {
+			return fmt.Errorf(strings.TrimSpace(errMigrateDestinationNewerVersion),
+				workspace, pinned, tfversion.String())
+		}
+		m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+			"[reset][yellow]Workspace %q requires Terraform %s, which is newer than this CLI\n(%s). After migrating, this CLI may be unable to operate on that\nworkspace's state.[reset]",
+			workspace, pinned, tfversion.String())))
+	}
+	return nil
+}
+
+// runWorkspaceRenameCmd invokes the configured rename program with the given
+// source workspace name as its only argument and returns the destination
+// workspace name from the program's stdout, with surrounding whitespace
+// trimmed. A failing program or empty output is an error, so that arbitrary
+// renaming logic can veto a migration before anything is copied.
+func runWorkspaceRenameCmd(command, name string) (string, error) {
+	cmd := exec.Command(command, name)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf(strings.TrimSpace(errMigrateRenameCmdFailed),
+			command, name, err, strings.TrimSpace(stderr.String()))
+	}
+	newName := strings.TrimSpace(stdout.String())
+	if newName == "" {
+		return "", fmt.Errorf(strings.TrimSpace(errMigrateRenameCmdEmpty),
+			command, name)
+	}
+	return newName, nil
+}
+
+// resolveWorkspaceSuffix returns the destination workspace name to migrate
+// into when a collision-avoiding suffix is configured. Names that don't
+// collide with an existing destination workspace are kept as-is; colliding
+// names get the suffix appended. A collision on the suffixed name itself is
+// an error rather than cause for stacking another suffix, since that would
+// just move the overwrite somewhere less predictable.
+func resolveWorkspaceSuffix(name, suffix string, existing map[string]bool) (string, error) {
+	if !existing[name] {
+		return name, nil
+	}
+	suffixed := name + suffix
+	if existing[suffixed] {
+		return "", fmt.Errorf(strings.TrimSpace(errMigrateSuffixCollision),
+			name, suffixed)
+	}
+	return suffixed, nil
+}
+
+// selectMigrationWorkspaces filters the sorted source workspace list down to
+// the requested names, preserving the alphabetical copy order. Requested
+// names that don't exist in the source are an error listing every missing
+// name at once.
+func selectMigrationWorkspaces(sourceWorkspaces, requested []string, sourceType string) ([]string, error) {
+	requestedSet := make(map[string]bool, len(requested))
+	for _, name := range requested {
+		requestedSet[name] = true
+	}
+
+	var selected []string
+	for _, name := range sourceWorkspaces {
+		if requestedSet[name] {
+			selected = append(selected, name)
+			delete(requestedSet, name)
+		}
+	}
+
+	if len(requestedSet) > 0 {
+		missing := make([]string, 0, len(requestedSet))
+		for name := range requestedSet {
+			missing = append(missing, name)
+		}
+		sort.Strings(missing)
+		return nil, fmt.Errorf(strings.TrimSpace(errMigrateMissingWorkspaces),
+			sourceType, strings.Join(missing, ", "))
+	}
+	return selected, nil
+}
+
+// backendMigrateComputeActions describes every action a multi-workspace
+// migration is about to take, one string per source workspace, in copy order.
+// destinationNames maps source workspace names to resolved destination names
+// where they differ. When checkDestination is set the destination is read to
+// distinguish copies into empty workspaces from overwrites; callers migrating
+// into HCP Terraform pass false, since reading a workspace there may create
+// it as a side effect.
+func (m *Meta) backendMigrateComputeActions(opts *backendMigrateOpts, sourceWorkspaces []string, destinationNames map[string]string, checkDestination bool) ([]string, error) {
+	var actions []string
+	for _, name := range sourceWorkspaces {
+		destinationName := name
+		if mapped, ok := destinationNames[name]; ok {
+			destinationName = mapped
+		}
+
+		sourceState, err := opts.Source.StateMgr(name)
+		if err != nil {
+			return nil, fmt.Errorf(strings.TrimSpace(
+				errMigrateSingleLoadDefault), opts.SourceType, err)
+		}
+		if err := sourceState.RefreshState(); err != nil {
+			return nil, fmt.Errorf(strings.TrimSpace(
+				errMigrateSingleLoadDefault), opts.SourceType, err)
+		}
+		if sourceState.State().Empty() {
+			actions = append(actions, fmt.Sprintf(
+				"Skip workspace %q, which has no state", name))
+			continue
+		}
+
+		action := fmt.Sprintf("Copy workspace %q to %q", name, destinationName)
+		if destinationName != name {
+			action += " (renamed)"
+		}
+		if checkDestination {
+			destinationState, err := opts.Destination.StateMgr(destinationName)
+			if err != nil {
+				return nil, fmt.Errorf(strings.TrimSpace(
+					errMigrateSingleLoadDefault), opts.DestinationType, err)
+			}
+			if err := destinationState.RefreshState(); err != nil {
+				return nil, fmt.Errorf(strings.TrimSpace(
+					errMigrateSingleLoadDefault), opts.DestinationType, err)
+			}
+			if !destinationState.State().Empty() {
+				action += ", OVERWRITING its existing state"
+			}
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// backendMigrateManifest is the parsed form of backendMigrateOpts.
+// ManifestPath: one entry per source workspace naming its destination and,
+// optionally, a conflict policy that overrides ConflictStrategy for just
+// that workspace.
+type backendMigrateManifest struct {
+	Workspaces []backendMigrateManifestEntry `json:"workspaces"`
+}
+
+// backendMigrateManifestEntry is one workspace of a migration manifest.
+type backendMigrateManifestEntry struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	OnConflict  string `json:"on_conflict,omitempty"`
+}
+
+// parseMigrationManifest reads and validates a migration manifest: every
+// entry must name a source and a destination, no source may appear twice, no
+// two sources may share a destination, and any conflict policy must be one
+// of the -migrate-conflict strategies.
+func parseMigrationManifest(path string) (*backendMigrateManifest, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(strings.TrimSpace(errMigrateInvalidManifest), path, err)
+	}
+
+	manifest := &backendMigrateManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf(strings.TrimSpace(errMigrateInvalidManifest), path, err)
+	}
+	if len(manifest.Workspaces) == 0 {
+		return nil, fmt.Errorf(strings.TrimSpace(errMigrateInvalidManifest),
+			path, "the manifest names no workspaces")
+	}
+
+	sources := map[string]bool{}
+	destinations := map[string]string{}
+	for _, entry := range manifest.Workspaces {
+		if entry.Source == "" || entry.Destination == "" {
+			return nil, fmt.Errorf(strings.TrimSpace(errMigrateInvalidManifest),
+				path, "workspace names must not be empty")
+		}
+		if sources[entry.Source] {
+			return nil, fmt.Errorf(strings.TrimSpace(errMigrateInvalidManifest),
+				path, fmt.Sprintf("workspace %q has more than one entry", entry.Source))
+		}
+		sources[entry.Source] = true
+		if previous, ok := destinations[entry.Destination]; ok {
+			return nil, fmt.Errorf(strings.TrimSpace(errMigrateInvalidManifest),
+				path, fmt.Sprintf("workspaces %q and %q are both mapped to %q", previous, entry.Source, entry.Destination))
+		}
+		destinations[entry.Destination] = entry.Source
+		switch entry.OnConflict {
+		case "", backendMigrateConflictNewestSerial, backendMigrateConflictSourceWins, backendMigrateConflictAbort:
+		default:
+			return nil, fmt.Errorf(strings.TrimSpace(errMigrateInvalidManifest),
+				path, fmt.Sprintf("workspace %q has unsupported conflict policy %q", entry.Source, entry.OnConflict))
+		}
+	}
+
+	return manifest, nil
+}
+
+// validateAgainst checks the manifest against the workspaces that actually
+// exist in the source backend, reporting entries for workspaces that don't
+// exist and workspaces the manifest fails to cover. Both lists are sorted so
+// the same mismatch always reads the same way.
+func (mf *backendMigrateManifest) validateAgainst(sourceWorkspaces []string, path string) error {
+	existing := map[string]bool{}
+	for _, name := range sourceWorkspaces {
+		existing[name] = true
+	}
+	covered := map[string]bool{}
+	var unknown []string
+	for _, entry := range mf.Workspaces {
+		covered[entry.Source] = true
+		if !existing[entry.Source] {
+			unknown = append(unknown, entry.Source)
+		}
+	}
+	var missing []string
+	for _, name := range sourceWorkspaces {
+		if !covered[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf(strings.TrimSpace(errMigrateInvalidManifest),
+			path, fmt.Sprintf("these workspaces do not exist in the source backend: %s", strings.Join(unknown, ", ")))
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf(strings.TrimSpace(errMigrateInvalidManifest),
+			path, fmt.Sprintf("the manifest has no entry for these source workspaces: %s", strings.Join(missing, ", ")))
+	}
+	return nil
+}
+
+// destinations returns the manifest's source-to-destination name mapping in
+// the same form parseWorkspaceMapFile produces, so the explicit-mapping
+// handling can be reused as-is.
+func (mf *backendMigrateManifest) destinations() map[string]string {
+	mapping := make(map[string]string, len(mf.Workspaces))
+	for _, entry := range mf.Workspaces {
+		mapping[entry.Source] = entry.Destination
+	}
+	return mapping
+}
+
+// conflictPolicyFor returns the conflict policy the manifest sets for the
+// given source workspace, or empty when it sets none.
+func (mf *backendMigrateManifest) conflictPolicyFor(source string) string {
+	for _, entry := range mf.Workspaces {
+		if entry.Source == source {
+			return entry.OnConflict
+		}
+	}
+	return ""
+}
+
+// parseWorkspaceMapFile reads a JSON file mapping source workspace names to
+// explicit destination names and validates it: names must be non-empty and no
+// two sources may share a destination, since that would make the later copy
+// overwrite one workspace's state with another's.
+func parseWorkspaceMapFile(path string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(strings.TrimSpace(errMigrateInvalidWorkspaceMap), path, err)
+	}
+
+	mapping := map[string]string{}
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf(strings.TrimSpace(errMigrateInvalidWorkspaceMap), path, err)
+	}
+
+	// Sorted so a file with several problems always reports the same one.
+	names := make([]string, 0, len(mapping))
+	for name := range mapping {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	seen := map[string]string{}
+	for _, name := range names {
+		destination := mapping[name]
+		if name == "" || destination == "" {
+			return nil, fmt.Errorf(strings.TrimSpace(errMigrateInvalidWorkspaceMap),
+				path, "workspace names must not be empty")
+		}
+		if previous, ok := seen[destination]; ok {
+			return nil, fmt.Errorf(strings.TrimSpace(errMigrateInvalidWorkspaceMap),
+				path, fmt.Sprintf("workspaces %q and %q are both mapped to %q", previous, name, destination))
+		}
+		seen[destination] = name
+	}
+
+	return mapping, nil
+}
+
+// filterMigrationWorkspaces returns the subset of source workspace names that
+// match the filter expression, preserving their order. Unlike
+// selectMigrationWorkspaces, names excluded by the filter are not an error.
+func filterMigrationWorkspaces(sourceWorkspaces []string, filter *regexp.Regexp) []string {
+	var filtered []string
+	for _, name := range sourceWorkspaces {
+		if filter.MatchString(name) {
+			filtered = append(filtered, name)
+		} else {
+			log.Printf("[TRACE] backendMigrateState: workspace %q does not match the filter, so skipping it", name)
+		}
+	}
+	return filtered
+}
+
+// formatMigrateDryRun describes what copying a single workspace would do,
+// without doing it. Multi-workspace migrations call through here once per
+// workspace, so each planned source->destination mapping (including any
+// rename pattern expansion) gets its own report.
+func formatMigrateDryRun(opts *backendMigrateOpts, sourceState statemgr.Full, destination *states.State) string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "[reset][bold]Dry run:[reset] would copy workspace %q in %q to workspace %q in %q\n",
+		opts.sourceWorkspace, opts.SourceType,
+		opts.destinationWorkspace, opts.DestinationType)
+	if sm, ok := sourceState.(statemgr.PersistentMeta); ok {
+		meta := sm.StateSnapshotMeta()
+		fmt.Fprintf(&buf, "  Source serial: %d, lineage: %s\n", meta.Serial, meta.Lineage)
+	}
+	if destination.Empty() {
+		buf.WriteString("  The destination workspace is empty, so this would be a plain copy.")
+	} else {
+		buf.WriteString("  The destination workspace has existing state that would be OVERWRITTEN.")
+	}
+	return buf.String()
+}
+
+// backendMigrateSummary is the machine-readable account of a completed
+// migration, serialized as JSON when -migrate-summary is set. It exists for
+// pipelines that orchestrate backend moves and need to assert the exact set
+// of workspaces that was migrated, which the human-oriented output doesn't
+// support.
+type backendMigrateSummary struct {
+	SourceType      string                          `json:"source_type"`
+	DestinationType string                          `json:"destination_type"`
+	Workspaces      []backendMigrateWorkspaceRecord `json:"workspaces"`
+}
+
+// backendMigrateRecorder accumulates the per-workspace records while a
+// migration runs. It is kept separate from backendMigrateSummary so that the
+// marshalled document stays a plain value, while the accumulator carries the
+// mutex that a parallel migration needs without the risk of the lock being
+// copied along with the summary.
+type backendMigrateRecorder struct {
+	// mu guards workspaces, since a parallel migration appends records from
+	// several goroutines at once.
+	mu         sync.Mutex
+	workspaces []backendMigrateWorkspaceRecord
+}
+
+// records returns a copy of the accumulated records, so callers can read
+// (and sort) them without holding the lock.
+func (r *backendMigrateRecorder) records() []backendMigrateWorkspaceRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	records := make([]backendMigrateWorkspaceRecord, len(r.workspaces))
+	copy(records, r.workspaces)
+	return records
+}
+
+// backendMigrateWorkspaceRecord describes what happened to one source
+// workspace during a migration.
+type backendMigrateWorkspaceRecord struct {
+	SourceName      string   `json:"source_name"`
+	DestinationName string   `json:"destination_name"`
+	BytesCopied     int64    `json:"bytes_copied"`
+	Lineage         string   `json:"lineage,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+	Skipped         bool     `json:"skipped"`
+
+	// SkipReason says why a skipped workspace was not copied: "empty" when
+	// the source workspace had no state, "already-migrated" when the
+	// destination already held the same snapshot, or "conflict-strategy"
+	// when a conflict strategy decided the destination should be kept.
+	SkipReason string `json:"skip_reason,omitempty"`
+
+	// Variables lists the keys of the workspace variables that were copied
+	// to the destination workspace, and SensitiveVariables the subset that
+	// were created as empty placeholders because their values can't be read
+	// back from the source.
+	Variables          []string `json:"variables,omitempty"`
+	SensitiveVariables []string `json:"sensitive_variables,omitempty"`
+}
+
+// recordCopied appends a record for a workspace whose state was just
+// persisted to the destination. The byte count and lineage are taken from
+// the destination manager so they reflect what was actually written,
+// including any provider renames or deposed-object pruning.
+func (r *backendMigrateRecorder) recordCopied(opts *backendMigrateOpts, destinationState statemgr.Full) {
+	record := backendMigrateWorkspaceRecord{
+		SourceName:      opts.sourceWorkspace,
+		DestinationName: opts.destinationWorkspace,
+	}
+	if file := statemgr.Export(destinationState); file != nil {
+		var buf bytes.Buffer
+		if err := statefile.Write(file, &buf); err == nil {
+			record.BytesCopied = int64(buf.Len())
+		}
+		record.Lineage = file.Lineage
+	}
+	r.mu.Lock()
+	r.workspaces = append(r.workspaces, record)
+	r.mu.Unlock()
+}
+
+// recordTags attaches the applied tag list to the record for the given
+// destination workspace. Tags are only known after a workspace has been
+// copied, so this amends the record recordCopied created.
+func (r *backendMigrateRecorder) recordTags(destinationName string, tags []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.workspaces {
+		if r.workspaces[i].DestinationName == destinationName {
+			r.workspaces[i].Tags = tags
+		}
+	}
+}
+
+// recordVariables attaches the copied variable keys to the record for the
+// given destination workspace, amending the record recordCopied created in
+// the same way recordTags does.
+func (r *backendMigrateRecorder) recordVariables(destinationName string, variables, sensitive []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.workspaces {
+		if r.workspaces[i].DestinationName == destinationName {
+			r.workspaces[i].Variables = variables
+			r.workspaces[i].SensitiveVariables = sensitive
+		}
+	}
+}
+
+// recordSkipped appends a record for a workspace that was not copied, with
+// the given reason, so that workspaces missing from the destination can be
+// accounted for from the summary alone.
+func (r *backendMigrateRecorder) recordSkipped(opts *backendMigrateOpts, reason string) {
+	r.mu.Lock()
+	r.workspaces = append(r.workspaces, backendMigrateWorkspaceRecord{
+		SourceName:      opts.sourceWorkspace,
+		DestinationName: opts.destinationWorkspace,
+		Skipped:         true,
+		SkipReason:      reason,
+	})
+	r.mu.Unlock()
+}
+
+// backendMigrateStats accumulates quantitative figures about each workspace
+// that was actually copied: how large the serialized source state was and
+// how long the copy (migrate plus persist) took. The figures feed the report
+// printed at the end of a multi-workspace migration, so that slow migrations
+// can be attributed to particular oversized workspaces.
+type backendMigrateStats struct {
+	// mu guards workspaces, since a parallel migration appends from several
+	// goroutines at once.
+	mu         sync.Mutex
+	workspaces []backendMigrateWorkspaceStat
+}
+
+// snapshot returns a copy of the accumulated measurements, so the renderer
+// can read them without holding the lock.
+func (s *backendMigrateStats) snapshot() []backendMigrateWorkspaceStat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := make([]backendMigrateWorkspaceStat, len(s.workspaces))
+	copy(stats, s.workspaces)
+	return stats
+}
+
+// backendMigrateWorkspaceStat holds the measurements for one copied
+// workspace.
+type backendMigrateWorkspaceStat struct {
+	SourceName      string
+	DestinationName string
+	Bytes           int64
+	Duration        time.Duration
+}
+
+// record appends the measurements for a workspace that was just copied. The
+// byte count is the serialized size of the source state, so it reflects what
+// had to be read and transferred rather than what the destination chose to
+// store.
+func (s *backendMigrateStats) record(opts *backendMigrateOpts, sourceState statemgr.Full, duration time.Duration) {
+	stat := backendMigrateWorkspaceStat{
+		SourceName:      opts.sourceWorkspace,
+		DestinationName: opts.destinationWorkspace,
+		Duration:        duration,
+	}
+	if file := statemgr.Export(sourceState); file != nil {
+		var buf bytes.Buffer
+		if err := statefile.Write(file, &buf); err == nil {
+			stat.Bytes = int64(buf.Len())
+		}
+	}
+	s.mu.Lock()
+	s.workspaces = append(s.workspaces, stat)
+	s.mu.Unlock()
+}
+
+// backendMigrateRenderStats prints one line per copied workspace with its
+// serialized state size and copy duration, followed by the totals. Nothing
+// is printed when no workspace was copied.
+func (m *Meta) backendMigrateRenderStats(opts *backendMigrateOpts) {
+	if opts.stats == nil {
+		return
+	}
+	stats := opts.stats.snapshot()
+	if len(stats) == 0 {
+		return
+	}
+
+	m.Ui.Output(m.Colorize().Color("[reset][bold]Migration statistics:[reset]"))
+	var totalBytes int64
+	var totalDuration time.Duration
+	for _, stat := range stats {
+		m.Ui.Output(fmt.Sprintf("  - %s -> %s: %d bytes in %s",
+			stat.SourceName, stat.DestinationName, stat.Bytes,
+			stat.Duration.Round(time.Millisecond)))
+		totalBytes += stat.Bytes
+		totalDuration += stat.Duration
+	}
+	m.Ui.Output(fmt.Sprintf("  Total: %d bytes across %d workspace(s) in %s",
+		totalBytes, len(stats),
+		totalDuration.Round(time.Millisecond)))
+}
+
+// backendMigrateEmitSummary writes the accumulated migration summary as JSON
+// to opts.SummaryPath, or to stdout when the path is "-". Records are sorted
+// by source workspace name so the output is deterministic regardless of the
+// order the copies happened to finish in.
+func (m *Meta) backendMigrateEmitSummary(opts *backendMigrateOpts) error {
+	summary := &backendMigrateSummary{
+		SourceType:      opts.SourceType,
+		DestinationType: opts.DestinationType,
+		Workspaces:      opts.recorder.records(),
+	}
+	sort.Slice(summary.Workspaces, func(i, j int) bool {
+		return summary.Workspaces[i].SourceName < summary.Workspaces[j].SourceName
+	})
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		// Should never happen, since the summary is a plain struct.
+		return fmt.Errorf("Failed to serialize migration summary: %s", err)
+	}
+
+	if opts.SummaryPath == "-" {
+		m.Ui.Output(string(data))
+		return nil
+	}
+	if err := ioutil.WriteFile(opts.SummaryPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("Failed to write migration summary to %s: %s", opts.SummaryPath, err)
+	}
+	return nil
+}
+
+// backendMigrateSlowWorkspace is how long a single workspace copy may take
+// before the progress output notes the elapsed time, so an operator watching
+// a terminal can tell a slow copy from a hung one.
+const backendMigrateSlowWorkspace = 5 * time.Second
+
+// backendMigrateProgress emits a progress line for one workspace of a
+// multi-workspace migration. It stays quiet when interactive input is
+// disabled, since then nobody is watching a terminal and the extra lines
+// would just clutter captured logs.
+func (m *Meta) backendMigrateProgress(index, total int, sourceName, destinationName string) {
+	if !m.input {
+		return
+	}
+	label := sourceName
+	if destinationName != sourceName {
+		label = fmt.Sprintf("%s -> %s", sourceName, destinationName)
+	}
+	m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+		"[reset][bold]Migrating workspace %d/%d:[reset] %s", index, total, label)))
+}
+
+// backendMigrateElapsed reports how long one workspace took to copy, but only
+// when it was slow enough that an operator might wonder whether the migration
+// hung. Like the progress lines it's suppressed without interactive input.
+func (m *Meta) backendMigrateElapsed(name string, started time.Time) {
+	if !m.input {
+		return
+	}
+	if elapsed := time.Since(started); elapsed > backendMigrateSlowWorkspace {
+		m.Ui.Output(fmt.Sprintf("  Workspace %q took %s.", name, elapsed.Round(time.Second)))
+	}
+}
+
+// backendMigrateDeleteSource removes the migrated workspaces from the source
+// backend. It runs only after the whole migration has succeeded, so any
+// failed workspace aborts before anything is deleted. The default workspace
+// is never deleted, and neither are workspaces that were skipped because
+// they had no state.
+func (m *Meta) backendMigrateDeleteSource(opts *backendMigrateOpts) error {
+	var deleted []string
+	for _, record := range opts.recorder.records() {
+		if record.Skipped {
+			log.Printf("[TRACE] backendMigrateState: workspace %q was not migrated, so not deleting it from the source", record.SourceName)
+			continue
+		}
+		if record.SourceName == backend.DefaultStateName {
+			log.Print("[TRACE] backendMigrateState: never deleting the default workspace from the source")
+			continue
+		}
+		log.Printf("[INFO] backendMigrateState: deleting migrated workspace %q from the source backend", record.SourceName)
+		if err := opts.Source.DeleteWorkspace(record.SourceName, false); err != nil {
+			return fmt.Errorf(strings.TrimSpace(errMigrateDeleteSource),
+				record.SourceName, opts.SourceType, err)
+		}
+		deleted = append(deleted, record.SourceName)
+	}
+
+	if len(deleted) > 0 {
+		sort.Strings(deleted)
+		m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+			"[reset][bold]Deleted %d migrated workspace(s) from the %q backend:[reset]",
+			len(deleted), opts.SourceType)))
+		for _, name := range deleted {
+			m.Ui.Output(fmt.Sprintf("  - %s", name))
+		}
+	}
+	return nil
+}
+
+// backendMigrateConfirmAll presents every pending migration action as a
+// single numbered list and asks for one approval covering all of them. It
+// never assumes approval: without interactive input it returns the same
+// error the per-workspace prompts would, so that automation has to opt in
+// via -force-copy explicitly.
+func (m *Meta) backendMigrateConfirmAll(opts *backendMigrateOpts, actions []string) (bool, error) {
+	if len(actions) == 0 {
+		// Nothing to approve.
+		return true, nil
+	}
+	if !m.input {
+		log.Print("[TRACE] backendMigrateState: can't prompt for a combined approval, so aborting migration")
+		return false, errors.New(strings.TrimSpace(errInteractiveInputDisabled))
+	}
+
+	m.Ui.Output(m.Colorize().Color("[reset][bold]Terraform is about to take the following migration actions:[reset]"))
+	for i, action := range actions {
+		m.Ui.Output(fmt.Sprintf("  %d. %s", i+1, action))
+	}
+
+	return m.confirm(&terraform.InputOpts{
+		Id:    "backend-migrate-confirm-all",
+		Query: "Do you approve all of the actions listed above?",
+		Description: fmt.Sprintf(
+			strings.TrimSpace(inputBackendMigrateConfirmAll),
+			opts.SourceType, opts.DestinationType),
+	})
+}
+
+// parseProviderRenames parses a list of old=new provider source address
+// mappings into provider addresses, validating both sides. Mapping the same
+// source provider twice is an error, since the result would depend on
+// ordering.
+func parseProviderRenames(mappings []string) (map[addrs.Provider]addrs.Provider, error) {
+	renames := make(map[addrs.Provider]addrs.Provider, len(mappings))
+	for _, mapping := range mappings {
+		eq := strings.Index(mapping, "=")
+		if eq < 1 || eq == len(mapping)-1 {
+			return nil, fmt.Errorf(strings.TrimSpace(errMigrateInvalidProviderRename),
+				mapping, "each mapping must have the form old=new")
+		}
+		oldAddr, diags := addrs.ParseProviderSourceString(mapping[:eq])
+		if diags.HasErrors() {
+			return nil, fmt.Errorf(strings.TrimSpace(errMigrateInvalidProviderRename),
+				mapping, diags.Err())
+		}
+		newAddr, diags := addrs.ParseProviderSourceString(mapping[eq+1:])
+		if diags.HasErrors() {
+			return nil, fmt.Errorf(strings.TrimSpace(errMigrateInvalidProviderRename),
+				mapping, diags.Err())
+		}
+		if _, exists := renames[oldAddr]; exists {
+			return nil, fmt.Errorf(strings.TrimSpace(errMigrateInvalidProviderRename),
+				mapping, fmt.Sprintf("provider %s is mapped more than once", oldAddr))
+		}
+		renames[oldAddr] = newAddr
+	}
+	return renames, nil
+}
+
+// renameStateProviders rewrites the provider configuration address of every
+// resource in the given state according to the given mapping, in-place,
+// returning a description of each applied rename sorted alphabetically.
+func renameStateProviders(state *states.State, renames map[addrs.Provider]addrs.Provider) []string {
+	var applied []string
+	for _, ms := range state.Modules {
+		for _, rs := range ms.Resources {
+			oldAddr := rs.ProviderConfig.Provider
+			newAddr, ok := renames[oldAddr]
+			if !ok {
+				continue
+			}
+			rs.ProviderConfig.Provider = newAddr
+			applied = append(applied, fmt.Sprintf("%s: %s -> %s", rs.Addr, oldAddr, newAddr))
+		}
+	}
+	sort.Strings(applied)
+	return applied
+}
+
+// pruneDeposedObjects removes every deposed resource instance object from the
+// given state in-place, returning a description of each removed object sorted
+// alphabetically. This is deliberately conservative: deposed objects are
+// remnants of failed create_before_destroy replacements that Terraform would
+// otherwise try to destroy on the next apply, and current objects are never
+// touched.
+func pruneDeposedObjects(state *states.State) []string {
+	var pruned []string
+	for _, ms := range state.Modules {
+		for _, rs := range ms.Resources {
+			for key, is := range rs.Instances {
+				instAddr := rs.Addr.Instance(key)
+				for deposedKey := range is.Deposed {
+					pruned = append(pruned, fmt.Sprintf("%s (deposed object %s)", instAddr, deposedKey))
+					ms.ForgetResourceInstanceDeposed(instAddr.Resource, deposedKey)
+				}
+			}
+		}
+	}
+	sort.Strings(pruned)
+	return pruned
+}
+
+// The accepted values for backendMigrateOpts.ConflictStrategy.
+const (
+	backendMigrateConflictNewestSerial = "newest-serial"
+	backendMigrateConflictSourceWins   = "source-wins"
+	backendMigrateConflictAbort        = "abort"
+)
+
+// backendMigrateResolveConflict decides, without prompting, whether the
+// source state should overwrite a non-empty destination according to the
+// configured conflict strategy. It reports whether the copy should proceed;
+// a false result with a nil error means the workspace is skipped.
+func backendMigrateResolveConflict(sourceState, destinationState statemgr.Full, opts *backendMigrateOpts) (bool, error) {
+	strategy := opts.conflictStrategy()
+	switch strategy {
+	case backendMigrateConflictSourceWins:
+		return true, nil
+
+	case backendMigrateConflictAbort:
+		return false, fmt.Errorf(strings.TrimSpace(errMigrateConflictAbort),
+			opts.destinationWorkspace, opts.DestinationType)
+
+	case backendMigrateConflictNewestSerial:
+		sm1, ok1 := sourceState.(statemgr.PersistentMeta)
+		sm2, ok2 := destinationState.(statemgr.PersistentMeta)
+		if !ok1 || !ok2 {
+			return false, fmt.Errorf(strings.TrimSpace(errMigrateConflictNoMeta),
+				opts.SourceType, opts.DestinationType, strategy)
+		}
+		sourceSerial := sm1.StateSnapshotMeta().Serial
+		destinationSerial := sm2.StateSnapshotMeta().Serial
+		log.Printf("[TRACE] backendMigrateResolveConflict: source serial %d, destination serial %d", sourceSerial, destinationSerial)
+		return sourceSerial > destinationSerial, nil
+
+	default:
+		return false, fmt.Errorf("unsupported conflict resolution strategy %q", strategy)
+	}
+}
+
+func (m *Meta) backendMigrateEmptyConfirm(source, destination statemgr.Full, opts *backendMigrateOpts) (bool, error) {
+	var inputOpts *terraform.InputOpts
+	if opts.DestinationType == "cloud" {
+		appName := "HCP Terraform"
+		if cloudBackend, ok := opts.Destination.(*cloud.Cloud); ok {
+			appName = cloudBackend.AppName()
+		}
+		inputOpts = &terraform.InputOpts{
+			Id:          "backend-migrate-copy-to-empty-cloud",
+			Query:       "Do you want to copy existing state to HCP Terraform?",
+			Description: fmt.Sprintf(strings.TrimSpace(inputBackendMigrateEmptyCloud), opts.SourceType, appName),
+		}
+	} else {
+		inputOpts = &terraform.InputOpts{
+			Id:    "backend-migrate-copy-to-empty",
+			Query: "Do you want to copy existing state to the new backend?",
+			Description: fmt.Sprintf(
+				strings.TrimSpace(inputBackendMigrateEmpty),
+				opts.SourceType, opts.DestinationType),
+		}
+	}
+
+	// For an intentional move the user can also ask for the source workspace
+	// to be deleted once the copy is safely persisted, saving a manual
+	// cleanup step. The choice is only offered when the source could
+	// actually honor it.
+	if backendMigrateCanDeleteSource(opts) {
+		inputOpts.Description += "\n\n" + fmt.Sprintf(
+			strings.TrimSpace(inputBackendMigrateEmptyDelete),
+			opts.sourceWorkspace, opts.SourceType)
+		for i := 0; i < 2; i++ {
+			v, err := m.UIInput().Input(context.Background(), inputOpts)
+			if err != nil {
+				return false, fmt.Errorf("Error asking for confirmation: %s", err)
+			}
+			switch strings.ToLower(v) {
+			case "no":
+				return false, nil
+			case "yes":
+				return true, nil
+			case "copy-and-delete":
+				opts.deleteSourceAfterCopy = true
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	return m.confirm(inputOpts)
+}
+
+// backendMigrateCanDeleteSource reports whether the source workspace could be
+// deleted after a successful copy. The default workspace can't be deleted
+// from any backend, and single-workspace backends don't support deletion at
+// all, so the copy-and-delete choice is only offered when neither applies.
+func backendMigrateCanDeleteSource(opts *backendMigrateOpts) bool {
+	if opts.sourceWorkspace == backend.DefaultStateName {
+		return false
+	}
+	if _, err := opts.Source.Workspaces(); err != nil {
+		return false
+	}
+	return true
+}
+
+func (m *Meta) backendMigrateNonEmptyConfirm(
+	sourceState, destinationState statemgr.Full, opts *backendMigrateOpts) (bool, error) {
+	// We need to grab both states so we can write them to a file
+	source := sourceState.State()
+	destination := destinationState.State()
+
+	// Save both to a temporary. State can contain secrets, so the location
+	// honors -migrate-temp-dir and TF_TEMP_DIR rather than always landing in
+	// the world-readable system temporary directory.
+	parent := m.migrateTempDir
+	if parent == "" {
+		parent = os.Getenv("TF_TEMP_DIR")
+	}
+	td, err := ioutil.TempDir(parent, "terraform")
+	if err != nil {
+		return false, fmt.Errorf("Error creating temporary directory: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	// Helper to write the state
+	saveHelper := func(n, path string, s *states.State) error {
+		// -migrate-redact-temp masks sensitive values before anything hits
+		// disk; the confirmation only compares structure and ownership, so
+		// the secret values themselves aren't needed.
+		if m.migrateRedactTemp {
+			s = redactSensitiveState(s)
+		}
+		mgr := statemgr.NewFilesystem(path)
+		if err := mgr.WriteState(s); err != nil {
+			return err
+		}
+		// Tighten the permissions explicitly so the temporary copies aren't
+		// readable by other users regardless of the process umask.
+		return os.Chmod(path, 0600)
+	}
+
+	// Write the states
+	sourcePath := filepath.Join(td, fmt.Sprintf("1-%s.tfstate", opts.SourceType))
+	destinationPath := filepath.Join(td, fmt.Sprintf("2-%s.tfstate", opts.DestinationType))
+	if err := saveHelper(opts.SourceType, sourcePath, source); err != nil {
+		return false, fmt.Errorf("Error saving temporary state: %s", err)
+	}
+	if err := saveHelper(opts.DestinationType, destinationPath, destination); err != nil {
+		return false, fmt.Errorf("Error saving temporary state: %s", err)
+	}
+
+	// Show how the overwrite would change the destination in familiar
+	// plan-style terms, in addition to the temporary files above. The diff
+	// renders by default; -migrate-no-diff falls back to just the files.
+	if !m.migrateNoDiff {
+		m.Ui.Output(m.Colorize().Color(formatStateMigrationDiff(source, destination)))
+	}
+
+	// Surface the lineage and serial of both states so the user can judge
+	// whether they are really two snapshots of the same environment before
+	// agreeing to the overwrite.
+	sm1, ok1 := sourceState.(statemgr.PersistentMeta)
+	sm2, ok2 := destinationState.(statemgr.PersistentMeta)
+	if ok1 && ok2 {
+		sourceMeta := sm1.StateSnapshotMeta()
+		destinationMeta := sm2.StateSnapshotMeta()
+		m.Ui.Output(fmt.Sprintf(
+			"Source state:      lineage %q, serial %d\nDestination state: lineage %q, serial %d",
+			sourceMeta.Lineage, sourceMeta.Serial,
+			destinationMeta.Lineage, destinationMeta.Serial))
+		if sourceMeta.Lineage != "" && destinationMeta.Lineage != "" &&
+			sourceMeta.Lineage != destinationMeta.Lineage {
+			m.Ui.Output(m.Colorize().Color(fmt.Sprintf(strings.TrimSpace(
+				outputBackendMigrateLineageMismatch),
+				opts.destinationWorkspace, opts.DestinationType)))
+		}
+	}
+
+	// Ask for confirmation
+	var inputOpts *terraform.InputOpts
+	if opts.DestinationType == "cloud" {
+		appName := "HCP Terraform"
+		if cloudBackend, ok := opts.Destination.(*cloud.Cloud); ok {
+			appName = cloudBackend.AppName()
+		}
+		inputOpts = &terraform.InputOpts{
+			Id:    "backend-migrate-to-tfc",
+			Query: "Do you want to copy existing state to HCP Terraform?",
+			Description: fmt.Sprintf(
+				strings.TrimSpace(inputBackendMigrateNonEmptyCloud),
+				opts.SourceType, sourcePath, destinationPath, appName),
+		}
+	} else {
+		inputOpts = &terraform.InputOpts{
+			Id:    "backend-migrate-to-backend",
+			Query: "Do you want to copy existing state to the new backend?",
+			Description: fmt.Sprintf(
+				strings.TrimSpace(inputBackendMigrateNonEmpty),
+				opts.SourceType, opts.DestinationType, sourcePath, destinationPath),
+		}
+	}
+
+	// Confirm with the user that the copy should occur
+	return m.confirm(inputOpts)
+}
+
+// redactedValuePlaceholder is what redactSensitiveState substitutes for
+// values recorded as sensitive.
+const redactedValuePlaceholder = "(sensitive value)"
+
+// redactSensitiveState returns a copy of the given state in which every
+// value recorded as sensitive is replaced with a placeholder: resource
+// instance attributes selected by the recorded sensitive paths, and root
+// output values flagged as sensitive. The structure of the state is
+// otherwise preserved, so it remains useful for comparing what a migration
+// would overwrite without spilling credentials to disk.
+func redactSensitiveState(s *states.State) *states.State {
+	if s == nil {
+		return nil
+	}
+
+	ret := s.DeepCopy()
+	for _, ms := range ret.Modules {
+		for _, rs := range ms.Resources {
+			for _, is := range rs.Instances {
+				objs := make([]*states.ResourceInstanceObjectSrc, 0, 1+len(is.Deposed))
+				objs = append(objs, is.Current)
+				for _, obj := range is.Deposed {
+					objs = append(objs, obj)
+				}
+				for _, obj := range objs {
+					if obj == nil || len(obj.AttrSensitivePaths) == 0 || len(obj.AttrsJSON) == 0 {
+						continue
+					}
+
+					var attrs interface{}
+					if err := json.Unmarshal(obj.AttrsJSON, &attrs); err != nil {
+						// Unreadable attributes have nothing recognizable to
+						// redact, so they are left as-is.
+						continue
+					}
+					for _, path := range obj.AttrSensitivePaths {
+						attrs = redactAttrPath(attrs, path)
+					}
+					if masked, err := json.Marshal(attrs); err == nil {
+						obj.AttrsJSON = masked
+					}
+				}
+			}
+		}
+	}
+
+	for _, ov := range ret.RootOutputValues {
+		if ov.Sensitive {
+			ov.Value = cty.StringVal(redactedValuePlaceholder)
+		}
+	}
+
+	return ret
+}
+
+// redactAttrPath replaces the value selected by the given path within a
+// JSON-decoded attribute structure with the redaction placeholder, returning
+// the (possibly replaced) value. Paths that don't resolve within the
+// structure leave it unchanged, since sensitive paths can legitimately refer
+// to values that are currently null or absent.
+func redactAttrPath(v interface{}, path cty.Path) interface{} {
+	if len(path) == 0 {
+		return redactedValuePlaceholder
+	}
+
+	switch step := path[0].(type) {
+	case cty.GetAttrStep:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+		if cur, ok := m[step.Name]; ok {
+			m[step.Name] = redactAttrPath(cur, path[1:])
+		}
+		return m
+	case cty.IndexStep:
+		switch {
+		case step.Key.Type() == cty.Number:
+			list, ok := v.([]interface{})
+			if !ok {
+				return v
+			}
+			idx, _ := step.Key.AsBigFloat().Int64()
+			if idx >= 0 && idx < int64(len(list)) {
+				list[idx] = redactAttrPath(list[idx], path[1:])
+			}
+			return list
+		case step.Key.Type() == cty.String:
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return v
+			}
+			key := step.Key.AsString()
+			if cur, ok := m[key]; ok {
+				m[key] = redactAttrPath(cur, path[1:])
+			}
+			return m
+		}
+	}
+	return v
+}
+
+// formatStateMigrationDiffMaxChanges caps how many changed resource
+// instances formatStateMigrationDiff lists, so that a huge state doesn't
+// scroll the confirmation prompt out of view. Changes beyond the cap are
+// summarized as a count.
+const formatStateMigrationDiffMaxChanges = 50
+
+// formatStateMigrationDiff summarizes, in the plan renderer's +/-/~ terms,
+// how the destination state would change if migration overwrote it with the
+// source state. Provider schemas aren't available while the backend is being
+// initialized, so the comparison is at whole-resource-instance granularity:
+// attribute-level rendering would require decoding each object against its
+// schema.
+func formatStateMigrationDiff(source, destination *states.State) string {
+	collect := func(s *states.State) map[string]string {
+		objs := map[string]string{}
+		if s == nil {
+			return objs
+		}
+		for _, ms := range s.Modules {
+			for _, rs := range ms.Resources {
+				for key, is := range rs.Instances {
+					if is.Current == nil {
+						continue
+					}
+					objs[rs.Addr.Instance(key).String()] = string(is.Current.AttrsJSON)
+				}
+			}
+		}
+		return objs
+	}
+
+	sourceObjs := collect(source)
+	destinationObjs := collect(destination)
+
+	var allAddrs []string
+	for addr := range sourceObjs {
+		allAddrs = append(allAddrs, addr)
+	}
+	for addr := range destinationObjs {
+		if _, ok := sourceObjs[addr]; !ok {
+			allAddrs = append(allAddrs, addr)
+		}
+	}
+	sort.Strings(allAddrs)
+
+	var buf bytes.Buffer
+	buf.WriteString("[bold]Overwriting would change the destination state as follows:[reset]\n")
+	changes := 0
+	truncated := 0
+	for _, addr := range allAddrs {
+		sourceAttrs, inSource := sourceObjs[addr]
+		destinationAttrs, inDestination := destinationObjs[addr]
+
+		var symbol string
+		switch {
+		case inSource && !inDestination:
+			symbol = "[green]+[reset]"
+		case !inSource && inDestination:
+			symbol = "[red]-[reset]"
+		case sourceAttrs != destinationAttrs:
+			symbol = "[yellow]~[reset]"
+		default:
+			continue
+		}
+
+		changes++
+		if changes > formatStateMigrationDiffMaxChanges {
+			truncated++
+			continue
+		}
+		fmt.Fprintf(&buf, "  %s %s\n", symbol, addr)
+	}
+	if truncated > 0 {
+		fmt.Fprintf(&buf, "  ... and %d more change(s) not shown.\n", truncated)
+	}
+	if changes == 0 {
+		buf.WriteString("  (no resource-level differences; the states may still differ in outputs or metadata)\n")
+	}
+	return buf.String()
+}
+
+// backendMigrateCheckFormatVersions enforces -migrate-min-format-version by
+// inspecting the raw state format version of each source workspace before
+// any migration begins. All workspaces below the threshold are collected and
+// reported in a single error so the user sees the full extent of the problem
+// at once. Source state managers that can't report a raw format version are
+// skipped, since their states get upgraded on load during migration anyway.
+func (m *Meta) backendMigrateCheckFormatVersions(opts *backendMigrateOpts, sourceWorkspaces []string, sourceSingleState bool) error {
+	names := sourceWorkspaces
+	if sourceSingleState {
+		names = []string{backend.DefaultStateName}
+	}
+
+	var tooOld []string
+	for _, name := range names {
+		sourceState, err := opts.Source.StateMgr(name)
+		if err != nil {
+			return fmt.Errorf(strings.TrimSpace(
+				errMigrateSingleLoadDefault), opts.SourceType, err)
+		}
+
+		versioner, ok := sourceState.(statemgr.FormatVersioner)
+		if !ok {
+			log.Printf("[DEBUG] backendMigrateState: source state manager can't report a raw format version, so not checking workspace %q", name)
+			continue
+		}
+
+		version, err := versioner.StateFormatVersion()
+		if err == statefile.ErrNoState {
+			// Empty workspaces are never migrated, so they can't violate
+			// the threshold.
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf(strings.TrimSpace(
+				errMigrateSingleLoadDefault), opts.SourceType, err)
+		}
+
+		log.Printf("[TRACE] backendMigrateState: source workspace %q has state format version %d", name, version)
+		if version < uint64(m.migrateMinFormatVersion) {
+			tooOld = append(tooOld, fmt.Sprintf("%s (format version %d)", name, version))
+		}
+	}
+
+	if len(tooOld) > 0 {
+		return fmt.Errorf(strings.TrimSpace(errMigrateFormatVersionTooOld),
+			m.migrateMinFormatVersion, strings.Join(tooOld, "\n  "))
+	}
+	return nil
+}
+
+// backendMigrateCheckStateSizes verifies, without uploading anything, that
+// every source workspace's serialized state fits within the destination's
+// state size limit. The limit comes from -migrate-max-state-size when set, or
+// otherwise from the destination backend itself when it implements
+// backend.StateSizeLimiter. Every workspace is checked before failing so the
+// user sees all oversized states at once rather than striking them one at a
+// time mid-migration.
+func (m *Meta) backendMigrateCheckStateSizes(opts *backendMigrateOpts, sourceWorkspaces []string, sourceSingleState bool) error {
+	limit := m.migrateMaxStateSize
+	if limit == 0 {
+		if limiter, ok := opts.Destination.(backend.StateSizeLimiter); ok {
+			limit = limiter.StateSizeLimit()
+		}
+	}
+	if limit == 0 {
+		return nil
+	}
+
+	names := sourceWorkspaces
+	if sourceSingleState {
+		names = []string{backend.DefaultStateName}
+	}
+
+	var tooLarge []string
+	for _, name := range names {
+		sourceState, err := opts.Source.StateMgr(name)
+		if err != nil {
+			return fmt.Errorf(strings.TrimSpace(
+				errMigrateSingleLoadDefault), opts.SourceType, err)
+		}
+		if err := sourceState.RefreshState(); err != nil {
+			return fmt.Errorf(strings.TrimSpace(
+				errMigrateSingleLoadDefault), opts.SourceType, err)
+		}
+		// Empty workspaces are never migrated, so they can't be rejected.
+		if sourceState.State().Empty() {
+			continue
+		}
+
+		file := statemgr.Export(sourceState)
+		if file == nil {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := statefile.Write(file, &buf); err != nil {
+			return fmt.Errorf(strings.TrimSpace(
+				errMigrateSingleLoadDefault), opts.SourceType, err)
+		}
+		size := int64(buf.Len())
+		log.Printf("[TRACE] backendMigrateState: source workspace %q serializes to %d bytes", name, size)
+		if size > limit {
+			tooLarge = append(tooLarge, fmt.Sprintf("%s (%d bytes)", name, size))
+		}
+	}
+
+	if len(tooLarge) > 0 {
+		return fmt.Errorf(strings.TrimSpace(errMigrateStateTooLarge),
+			limit, strings.Join(tooLarge, "\n  "))
+	}
+	return nil
+}
+
+// backendMigrateAllSynced reports whether every source workspace already has
+// matching state in the destination, judged by state snapshot metadata: the
+// destination must share the source's lineage and be at the source's serial
+// or newer. The comparison is deliberately conservative; whenever sync can't
+// be proven from metadata alone (a missing destination workspace, a state
+// manager without snapshot metadata, or an unset lineage) it reports false
+// and the migration proceeds normally.
+func (m *Meta) backendMigrateAllSynced(opts *backendMigrateOpts, sourceWorkspaces, destinationWorkspaces []string, sourceSingleState, destinationSingleState bool) (bool, error) {
+	names := sourceWorkspaces
+	if sourceSingleState || len(names) == 0 {
+		names = []string{backend.DefaultStateName}
+	}
+
+	destinationExisting := make(map[string]bool, len(destinationWorkspaces))
+	for _, name := range destinationWorkspaces {
+		destinationExisting[name] = true
+	}
+
+	for _, name := range names {
+		sourceState, err := opts.Source.StateMgr(name)
+		if err != nil {
+			return false, fmt.Errorf(strings.TrimSpace(
+				errMigrateSingleLoadDefault), opts.SourceType, err)
+		}
+		if err := sourceState.RefreshState(); err != nil {
+			return false, fmt.Errorf(strings.TrimSpace(
+				errMigrateSingleLoadDefault), opts.SourceType, err)
+		}
+		// Empty workspaces are never migrated, so they're synced by
+		// definition.
+		if sourceState.State().Empty() {
+			continue
+		}
+
+		destinationName := name
+		if destinationSingleState {
+			destinationName = backend.DefaultStateName
+		} else if !destinationExisting[destinationName] {
+			log.Printf("[TRACE] backendMigrateState: workspace %q does not exist in the destination, so not synced", destinationName)
+			return false, nil
+		}
+
+		destinationState, err := opts.Destination.StateMgr(destinationName)
+		if err != nil {
+			return false, fmt.Errorf(strings.TrimSpace(
+				errMigrateSingleLoadDefault), opts.DestinationType, err)
+		}
+		if err := destinationState.RefreshState(); err != nil {
+			return false, fmt.Errorf(strings.TrimSpace(
+				errMigrateSingleLoadDefault), opts.DestinationType, err)
+		}
+		if destinationState.State().Empty() {
+			return false, nil
+		}
+
+		sm1, ok1 := sourceState.(statemgr.PersistentMeta)
+		sm2, ok2 := destinationState.(statemgr.PersistentMeta)
+		if !ok1 || !ok2 {
+			log.Printf("[TRACE] backendMigrateState: no snapshot metadata for workspace %q, so can't prove it is synced", name)
+			return false, nil
+		}
+		sourceMeta := sm1.StateSnapshotMeta()
+		destinationMeta := sm2.StateSnapshotMeta()
+		if sourceMeta.Lineage == "" || destinationMeta.Lineage == "" {
+			return false, nil
+		}
+		if sourceMeta.Lineage != destinationMeta.Lineage || destinationMeta.Serial < sourceMeta.Serial {
+			log.Printf("[TRACE] backendMigrateState: workspace %q is not synced (source lineage %q serial %d, destination lineage %q serial %d)",
+				name, sourceMeta.Lineage, sourceMeta.Serial, destinationMeta.Lineage, destinationMeta.Serial)
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func retrieveWorkspaces(back backend.Backend, sourceType string) ([]string, bool, error) {
+	info, err := backend.ListWorkspaces(back)
+	if err != nil {
+		return nil, false, fmt.Errorf(strings.TrimSpace(
+			errMigrateLoadStates), sourceType, err)
+	}
+
+	return info.Workspaces, info.SingleState, nil
+}
+
+// stateFileBackend adapts a loose local state file, such as an orphaned
+// terraform.tfstate, to the backend.Backend interface so that it can act as
+// the source of a state migration without constructing a local backend
+// configuration around it. It presents as a single-workspace backend whose
+// only state is the file; migration never deletes from its source, so
+// DeleteWorkspace is not supported.
+type stateFileBackend struct {
+	path string
+}
+
+var _ backend.Backend = (*stateFileBackend)(nil)
+
+func newStateFileBackend(path string) *stateFileBackend {
+	return &stateFileBackend{path: path}
+}
+
+func (b *stateFileBackend) ConfigSchema() *configschema.Block {
+	return &configschema.Block{}
+}
+
+func (b *stateFileBackend) PrepareConfig(given cty.Value) (cty.Value, tfdiags.Diagnostics) {
+	return given, nil
+}
+
+func (b *stateFileBackend) Configure(cty.Value) tfdiags.Diagnostics {
+	return nil
+}
+
+func (b *stateFileBackend) StateMgr(workspace string) (statemgr.Full, error) {
+	if workspace != backend.DefaultStateName {
+		return nil, backend.ErrWorkspacesNotSupported
+	}
+	return statemgr.NewFilesystem(b.path), nil
+}
+
+func (b *stateFileBackend) DeleteWorkspace(name string, force bool) error {
+	return backend.ErrWorkspacesNotSupported
+}
+
+func (b *stateFileBackend) Workspaces() ([]string, error) {
+	return nil, backend.ErrWorkspacesNotSupported
+}
+
+// migrationPreview summarizes how each source workspace would land in the
+// destination if a multi-workspace state migration were run: workspaces whose
+// destination state already holds resources would be overwritten, and the
+// rest would be created, either as new workspaces or into existing empty
+// ones.
+type migrationPreview struct {
+	Overwrite []string
+	Create    []string
+}
+
+// backendMigratePreview computes a migrationPreview for migrating all
+// workspaces from the source backend to the destination backend, without
+// modifying either. Both backends must support multiple workspaces;
+// backend.ErrWorkspacesNotSupported is passed through so callers can explain
+// that a single-workspace migration has nothing to preview.
+func backendMigratePreview(source, destination backend.Backend) (*migrationPreview, error) {
+	sourceWorkspaces, err := source.Workspaces()
+	if err != nil {
+		return nil, err
+	}
+	destinationWorkspaces, err := destination.Workspaces()
+	if err != nil {
+		return nil, err
+	}
+
+	exists := make(map[string]bool, len(destinationWorkspaces))
+	for _, name := range destinationWorkspaces {
+		exists[name] = true
+	}
+
+	ret := &migrationPreview{}
+	for _, name := range sourceWorkspaces {
+		if exists[name] {
+			stateMgr, err := destination.StateMgr(name)
+			if err != nil {
+				return nil, fmt.Errorf("error loading the state of destination workspace %q: %s", name, err)
+			}
+			if err := stateMgr.RefreshState(); err != nil {
+				return nil, fmt.Errorf("error loading the state of destination workspace %q: %s", name, err)
+			}
+			if state := stateMgr.State(); state != nil && !state.Empty() {
+				ret.Overwrite = append(ret.Overwrite, name)
+				continue
+			}
+		}
+		ret.Create = append(ret.Create, name)
+	}
+
+	return ret, nil
+}
+
+// backendMigrateDescribeWorkspaces returns one informational line per source
+// workspace, sorted by name, noting workspaces that have no state to copy
+// and workspaces whose same-named destination counterpart already holds
+// state that the copy would overwrite. It backs the listing shown before the
+// migrate-all-workspaces prompt, so "all workspaces" is a concrete set
+// rather than a guess.
+func backendMigrateDescribeWorkspaces(opts *backendMigrateOpts) ([]string, error) {
+	sourceWorkspaces, err := opts.Source.Workspaces()
+	if err != nil {
+		return nil, fmt.Errorf(strings.TrimSpace(
+			errMigrateLoadStates), opts.SourceType, err)
+	}
+	sort.Strings(sourceWorkspaces)
+
+	destinationWorkspaces, err := opts.Destination.Workspaces()
+	if err != nil {
+		return nil, fmt.Errorf(strings.TrimSpace(
+			errMigrateLoadStates), opts.DestinationType, err)
+	}
+	exists := make(map[string]bool, len(destinationWorkspaces))
+	for _, name := range destinationWorkspaces {
+		exists[name] = true
+	}
+
+	workspaceEmpty := func(b backend.Backend, backendType, name string) (bool, error) {
+		stateMgr, err := b.StateMgr(name)
+		if err != nil {
+			return false, fmt.Errorf(strings.TrimSpace(
+				errMigrateLoadStates), backendType, err)
+		}
+		if err := stateMgr.RefreshState(); err != nil {
+			return false, fmt.Errorf(strings.TrimSpace(
+				errMigrateLoadStates), backendType, err)
+		}
+		return stateMgr.State().Empty(), nil
+	}
+
+	lines := make([]string, 0, len(sourceWorkspaces))
+	for _, name := range sourceWorkspaces {
+		empty, err := workspaceEmpty(opts.Source, opts.SourceType, name)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case empty:
+			// Empty workspaces are never copied, so a conflict can't arise.
+			lines = append(lines, fmt.Sprintf("%s (no state; will be skipped)", name))
+		case exists[name]:
+			destinationEmpty, err := workspaceEmpty(opts.Destination, opts.DestinationType, name)
+			if err != nil {
+				return nil, err
+			}
+			if destinationEmpty {
+				lines = append(lines, name)
+			} else {
+				lines = append(lines, fmt.Sprintf("%s (will overwrite existing state in the destination)", name))
+			}
+		default:
+			lines = append(lines, name)
+		}
+	}
+
+	return lines, nil
+}
+
+// promptMigrationWorkspaceCount shows how many destination workspaces a
+// multi-workspace migration to HCP Terraform is about to create or use,
+// with a sample of the resolved names, and asks for a single approval
+// before any of them exist. Small migrations are spared the extra prompt;
+// the step exists to catch a pattern that resolves to far more workspaces
+// than intended, not to re-confirm a handful of names the per-workspace
+// prompts already cover. -force-copy skips it for automation, and the
+// combined -migrate-confirm-all prompt supersedes it, since that one
+// already lists every action individually.
+func (m *Meta) promptMigrationWorkspaceCount(opts *backendMigrateOpts, sourceWorkspaces []string, destinationNames map[string]string) error {
+	if opts.force || opts.DryRun || opts.ConfirmAll {
+		return nil
+	}
+
+	names := make([]string, 0, len(sourceWorkspaces))
+	seen := make(map[string]bool, len(sourceWorkspaces))
+	for _, name := range sourceWorkspaces {
+		resolved := destinationNames[name]
+		if seen[resolved] {
+			continue
+		}
+		seen[resolved] = true
+		names = append(names, resolved)
+	}
+	sort.Strings(names)
+
+	if len(names) < backendMigrateWorkspaceCountPromptThreshold {
+		return nil
+	}
+
+	// Large migrations get the first and last few names rather than a wall
+	// of hundreds; the count is the number that matters here.
+	const sampleEdge = 3
+	display := strings.Join(names, ", ")
+	if len(names) > sampleEdge*2 {
+		display = fmt.Sprintf("%s, ..., %s",
+			strings.Join(names[:sampleEdge], ", "),
+			strings.Join(names[len(names)-sampleEdge:], ", "))
+	}
+
+	appName := "HCP Terraform"
+	if cloudBackend, ok := opts.Destination.(*cloud.Cloud); ok {
+		appName = cloudBackend.AppName()
+	}
+
+	if !m.input {
+		log.Print("[TRACE] backendMigrateState: can't ask approval for the workspace count, so aborting migration")
+		return errors.New(strings.TrimSpace(errInteractiveInputDisabled))
+	}
+
+	m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+		"[reset][bold]This migration will create or use %d %s workspaces:[reset] %s\n",
+		len(names), appName, display)))
+
+	confirmed, err := m.confirm(&terraform.InputOpts{
+		Id:          "backend-migrate-workspace-count",
+		Query:       fmt.Sprintf("Create or use these %d workspaces?", len(names)),
+		Description: fmt.Sprintf(strings.TrimSpace(inputBackendMigrateWorkspaceCount), appName),
+	})
+	if err != nil {
+		return fmt.Errorf("Error asking for approval: %s", err)
+	}
+	if !confirmed {
+		return fmt.Errorf("Migration aborted by user.")
+	}
+	return nil
+}
+
+// applyMigrateCloudProject points the destination cloud backend's workspace
+// mapping at the project requested by opts.CloudProject, so that workspaces
+// created during the migration land in that project rather than the
+// organization's default. A project already configured in the cloud block
+// wins a conflict check here instead of being silently overridden.
+func applyMigrateCloudProject(opts *backendMigrateOpts, destination *cloud.Cloud) error {
+	if opts.CloudProject == "" {
+		return nil
+	}
+	if existing := destination.WorkspaceMapping.Project; existing != "" && existing != opts.CloudProject {
+		return fmt.Errorf(strings.TrimSpace(errMigrateCloudProjectConflict), opts.CloudProject, existing)
+	}
+	log.Printf("[INFO] backendMigrateTFC: assigning migrated workspaces to project %q", opts.CloudProject)
+	destination.WorkspaceMapping.Project = opts.CloudProject
+	return nil
+}
+
+func (m *Meta) backendMigrateTFC(ctx context.Context, opts *backendMigrateOpts) error {
+	_, sourceTFC := opts.Source.(*cloud.Cloud)
+	cloudBackendDestination, destinationTFC := opts.Destination.(*cloud.Cloud)
+
+	sourceWorkspaces, sourceSingleState, err := retrieveWorkspaces(opts.Source, opts.SourceType)
+	if err != nil {
+		return err
+	}
+	//to be used below, not yet implamented
+	// destinationWorkspaces, destinationSingleState
+	_, _, err = retrieveWorkspaces(opts.Destination, opts.SourceType)
+	if err != nil {
+		return err
+	}
+
+	// from HCP Terraform to non-TFC backend
+	if sourceTFC && !destinationTFC {
+		// From HCP Terraform to another backend. This is not yet implemented, and
+		// we recommend people to use the HCP Terraform API.
+		return fmt.Errorf(strings.TrimSpace(errTFCMigrateNotYetImplemented))
+	}
+
+	// Everything below, by the above two conditionals, now assumes that the
+	// destination is always HCP Terraform.
+	if err := applyMigrateCloudProject(opts, cloudBackendDestination); err != nil {
+		return err
+	}
+
+	sourceSingle := sourceSingleState || (len(sourceWorkspaces) == 1)
+	if sourceSingle {
+		if cloudBackendDestination.WorkspaceMapping.Strategy() == cloud.WorkspaceNameStrategy {
+			// If we know the name via WorkspaceNameStrategy, then set the
+			// destinationWorkspace to the new Name and skip the user prompt. Here the
+			// destinationWorkspace is not set to `default` thereby we will create it
+			// in HCP Terraform if it does not exist.
+			opts.destinationWorkspace = cloudBackendDestination.WorkspaceMapping.Name
+		}
 
 		currentWorkspace, err := m.Workspace()
 		if err != nil {
@@ -628,7 +3934,7 @@ func (m *Meta) backendMigrateTFC(opts *backendMigrateOpts) error {
 			return nil //skip migrating but return successfully
 		}
 
-		return m.backendMigrateState_s_s(opts)
+		return m.backendMigrateState_s_s(ctx, opts)
 	}
 
 	destinationTagsStrategy := cloudBackendDestination.WorkspaceMapping.Strategy() == cloud.WorkspaceTagsStrategy
@@ -643,21 +3949,41 @@ func (m *Meta) backendMigrateTFC(opts *backendMigrateOpts) error {
 
 		opts.sourceWorkspace = currentWorkspace
 		opts.destinationWorkspace = cloudBackendDestination.WorkspaceMapping.Name
+
+		// The destination can hold only one workspace's state, so let the
+		// user pick which source workspace to promote rather than silently
+		// assuming the currently selected one.
+		promoted, err := m.promptMultiToSingleCloudWorkspaceChoice(opts, sourceWorkspaces, currentWorkspace)
+		if err != nil {
+			return err
+		}
+		opts.sourceWorkspace = promoted
+
 		if err := m.promptMultiToSingleCloudMigration(opts); err != nil {
 			return err
 		}
 
 		log.Printf("[INFO] backendMigrateTFC: multi-to-single migration from source %s to destination %q", opts.sourceWorkspace, opts.destinationWorkspace)
 
-		return m.backendMigrateState_s_s(opts)
+		return m.backendMigrateState_s_s(ctx, opts)
 	}
 
 	// Multiple sources, and using tags strategy. So migrate every source
 	// workspace over to new one, prompt for workspace name pattern (*),
 	// and start migrating, and create tags for each workspace.
 	if multiSource && destinationTagsStrategy {
+		if opts.CurrentOnly {
+			currentWorkspace, err := m.Workspace()
+			if err != nil {
+				return err
+			}
+
+			log.Printf("[INFO] backendMigrateTFC: multi-to-multi migration restricted to current workspace %q", currentWorkspace)
+			return m.backendMigrateState_S_TFC(ctx, opts, []string{currentWorkspace})
+		}
+
 		log.Printf("[INFO] backendMigrateTFC: multi-to-multi migration from source workspaces %q", sourceWorkspaces)
-		return m.backendMigrateState_S_TFC(opts, sourceWorkspaces)
+		return m.backendMigrateState_S_TFC(ctx, opts, sourceWorkspaces)
 	}
 
 	// TODO(omar): after the check for sourceSingle is done, everything following
@@ -666,16 +3992,80 @@ func (m *Meta) backendMigrateTFC(opts *backendMigrateOpts) error {
 	return nil
 }
 
+// backendMigrateWorkspaceVariables copies the variables attached to the
+// source workspace currently being migrated onto its destination workspace.
+// Sensitive variables come back from the source with no value, so they are
+// created as empty sensitive placeholders; their keys are returned
+// separately so the caller can tell the user to re-populate them.
+func (m *Meta) backendMigrateWorkspaceVariables(opts *backendMigrateOpts) (migrated, placeholders []string, err error) {
+	lister := opts.Source.(backend.WorkspaceVariableLister)
+	writer := opts.Destination.(backend.WorkspaceVariableWriter)
+
+	variables, err := lister.ListWorkspaceVariables(opts.sourceWorkspace)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(variables) == 0 {
+		return nil, nil, nil
+	}
+
+	for i, variable := range variables {
+		if variable.Sensitive {
+			variables[i].Value = ""
+			placeholders = append(placeholders, variable.Key)
+		}
+		migrated = append(migrated, variable.Key)
+	}
+
+	log.Printf("[INFO] backendMigrateTFC: copying %d variables from workspace %q to workspace %q (%d sensitive placeholders)",
+		len(migrated), opts.sourceWorkspace, opts.destinationWorkspace, len(placeholders))
+	if err := writer.WriteWorkspaceVariables(opts.destinationWorkspace, variables); err != nil {
+		return nil, nil, err
+	}
+	return migrated, placeholders, nil
+}
+
+// workspaceTagger is implemented by destination backends that attach
+// workspace-level tags as part of a migration, which in practice is the
+// cloud backend under a tags strategy.
+type workspaceTagger interface {
+	EnsureWorkspaceTags(name string) ([]string, error)
+}
+
 // migrates a multi-state backend to HCP Terraform
-func (m *Meta) backendMigrateState_S_TFC(opts *backendMigrateOpts, sourceWorkspaces []string) error {
+func (m *Meta) backendMigrateState_S_TFC(ctx context.Context, opts *backendMigrateOpts, sourceWorkspaces []string) error {
 	log.Print("[TRACE] backendMigrateState: migrating all named workspaces")
 
+	// A filter expression narrows the migration to matching names only.
+	if opts.WorkspaceFilter != nil {
+		sourceWorkspaces = filterMigrationWorkspaces(sourceWorkspaces, opts.WorkspaceFilter)
+	}
+
 	currentWorkspace, err := m.Workspace()
 	if err != nil {
 		return err
 	}
 	newCurrentWorkspace := ""
 
+	// With a checkpoint file configured, a previous interrupted run's
+	// progress is loaded so completed workspaces are skipped and the naming
+	// decisions made back then are reused rather than prompted for again.
+	var checkpoint *backendMigrateCheckpoint
+	if opts.CheckpointPath != "" {
+		checkpoint, err = loadMigrationCheckpoint(opts.CheckpointPath)
+		if err != nil {
+			return fmt.Errorf(strings.TrimSpace(errMigrateCheckpointLoad),
+				opts.CheckpointPath, err)
+		}
+		if checkpoint != nil {
+			m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+				"[reset][bold]Resuming migration from checkpoint %s: %d workspace(s) already migrated.[reset]",
+				opts.CheckpointPath, len(checkpoint.Completed))))
+		} else {
+			checkpoint = &backendMigrateCheckpoint{Completed: map[string]string{}}
+		}
+	}
+
 	// This map is used later when doing the migration per source/destination.
 	// If a source has 'default' and has state, then we ask what the new name should be.
 	// And further down when we actually run state migration for each
@@ -685,6 +4075,19 @@ func (m *Meta) backendMigrateState_S_TFC(opts *backendMigrateOpts, sourceWorkspa
 	// do not get migrated.
 	defaultNewName := map[string]string{}
 	for i := 0; i < len(sourceWorkspaces); i++ {
+		if _, ok := opts.workspaceMap[sourceWorkspaces[i]]; ok {
+			// An explicit mapping already names the destination, so there's
+			// nothing to prompt for.
+			continue
+		}
+		if checkpoint != nil {
+			if name, ok := checkpoint.DefaultNewName[sourceWorkspaces[i]]; ok {
+				// The name was already chosen in the interrupted run, so
+				// reuse it to keep the renames consistent across resumes.
+				defaultNewName[sourceWorkspaces[i]] = name
+				continue
+			}
+		}
 		if sourceWorkspaces[i] == backend.DefaultStateName {
 			// For the default workspace we want to look to see if there is any state
 			// before we ask for a workspace name to migrate the default workspace into.
@@ -704,6 +4107,9 @@ func (m *Meta) backendMigrateState_S_TFC(opts *backendMigrateOpts, sourceWorkspa
 					return err
 				}
 				defaultNewName[sourceWorkspaces[i]] = newName
+				if checkpoint != nil {
+					checkpoint.DefaultNewName = defaultNewName
+				}
 			}
 		}
 	}
@@ -724,50 +4130,271 @@ func (m *Meta) backendMigrateState_S_TFC(opts *backendMigrateOpts, sourceWorkspa
 		if err := m.promptRemotePrefixToCloudTagsMigration(opts); err != nil {
 			return err
 		}
-		pattern = remoteBackend.WorkspaceNamePattern()
-		log.Printf("[TRACE] backendMigrateTFC: Remote backend reports workspace name pattern as: %q", pattern)
+		pattern = remoteBackend.WorkspaceNamePattern()
+		log.Printf("[TRACE] backendMigrateTFC: Remote backend reports workspace name pattern as: %q", pattern)
+	}
+
+	// Workspaces with an explicit mapping never consult the pattern, so only
+	// prompt for one when at least one workspace still needs it.
+	patternNeeded := false
+	for _, name := range sourceWorkspaces {
+		if _, ok := opts.workspaceMap[name]; !ok {
+			patternNeeded = true
+			break
+		}
+	}
+
+	if pattern == "" && checkpoint != nil && checkpoint.Pattern != "" {
+		// The pattern chosen in the interrupted run wins over prompting
+		// again, so workspaces not yet migrated resolve to the same names.
+		pattern = checkpoint.Pattern
+		log.Printf("[TRACE] backendMigrateTFC: reusing workspace name pattern %q from checkpoint", pattern)
+	}
+
+	if pattern == "" && patternNeeded {
+		var appName string
+		if cloudBackend, ok := opts.Destination.(*cloud.Cloud); ok {
+			appName = cloudBackend.AppName()
+		} else {
+			appName = "HCP Terraform"
+		}
+
+		pattern, err = m.promptMultiStateMigrationPattern(opts.SourceType, appName, opts.WorkspacePattern)
+		if err != nil {
+			return err
+		}
+	}
+	if checkpoint != nil {
+		checkpoint.Pattern = pattern
+	}
+
+	// With the pattern known, every destination name is known too. Resolve
+	// them all up front so that colliding names abort the migration before
+	// anything is copied: two source workspaces resolving to the same
+	// destination would otherwise silently overwrite each other.
+	// A pattern using template interpolation is parsed once here; a classic
+	// wildcard pattern is substituted directly below.
+	var patternExpr hcl.Expression
+	if migrationPatternIsTemplate(pattern) {
+		patternExpr, err = parseMigrationPatternTemplate(pattern)
+		if err != nil {
+			return err
+		}
+	}
+
+	destinationNames := make(map[string]string, len(sourceWorkspaces))
+	for _, name := range sourceWorkspaces {
+		if mapped, ok := opts.workspaceMap[name]; ok {
+			// An explicit mapping wins over the rename pattern.
+			destinationNames[name] = mapped
+			continue
+		}
+		resolved := name
+		if newName, ok := defaultNewName[name]; ok {
+			resolved = newName
+		}
+		if patternExpr != nil {
+			renamed, err := evalMigrationPatternTemplate(patternExpr, resolved)
+			if err != nil {
+				return fmt.Errorf(strings.TrimSpace(errMigratePatternTemplateEval),
+					pattern, name, err)
+			}
+			destinationNames[name] = renamed
+			continue
+		}
+		destinationNames[name] = strings.Replace(pattern, "*", resolved, -1)
+	}
+	// A degenerate pattern, or an oddly named source workspace, can resolve
+	// to an empty or over-long destination name that the backend would only
+	// reject later with an opaque error; report it here with the workspace
+	// that produced it instead.
+	for _, name := range sourceWorkspaces {
+		resolved := destinationNames[name]
+		if resolved == "" || len(resolved) > backendMigrateMaxWorkspaceNameLength {
+			return fmt.Errorf(strings.TrimSpace(errMigrateInvalidPatternResult),
+				name, resolved, backendMigrateMaxWorkspaceNameLength)
+		}
 	}
 
-	if pattern == "" {
-		var appName string
-		if cloudBackend, ok := opts.Destination.(*cloud.Cloud); ok {
-			appName = cloudBackend.AppName()
-		} else {
-			appName = "HCP Terraform"
+	if err := checkMigrationDestinationCollisions(destinationNames); err != nil {
+		// One naming clash needn't abort a long migration session: when
+		// input is possible, ask for an alternate name for each colliding
+		// workspace instead.
+		if !m.input || opts.force {
+			return err
+		}
+		if err := m.resolveMigrationDestinationCollisions(opts, destinationNames); err != nil {
+			return err
 		}
+	}
 
-		pattern, err = m.promptMultiStateMigrationPattern(opts.SourceType, appName)
+	// Before anything is copied or created, show how many destination
+	// workspaces the migration amounts to and get one approval for the
+	// count. Workspace counts can have billing implications, so a pattern
+	// that resolves to far more workspaces than intended should be caught
+	// here rather than discovered on an invoice. Small migrations skip it.
+	if err := m.promptMigrationWorkspaceCount(opts, sourceWorkspaces, destinationNames); err != nil {
+		return err
+	}
+
+	// A combined approval can now be offered before anything is copied. The
+	// destination isn't read here since reading an HCP Terraform workspace
+	// may create it as a side effect.
+	if opts.ConfirmAll && !opts.force && !opts.DryRun {
+		actions, err := m.backendMigrateComputeActions(opts, sourceWorkspaces, destinationNames, false)
+		if err != nil {
+			return err
+		}
+		confirmed, err := m.backendMigrateConfirmAll(opts, actions)
 		if err != nil {
 			return err
 		}
+		if !confirmed {
+			return fmt.Errorf("Migration aborted by user.")
+		}
+	}
+
+	// Variable migration needs both sides to store variables remotely, so an
+	// unsupported pairing is rejected before any state is copied.
+	if opts.MigrateVariables {
+		if _, ok := opts.Source.(backend.WorkspaceVariableLister); !ok {
+			return fmt.Errorf(strings.TrimSpace(errMigrateVariablesUnsupported),
+				opts.SourceType)
+		}
+		if _, ok := opts.Destination.(backend.WorkspaceVariableWriter); !ok {
+			return fmt.Errorf(strings.TrimSpace(errMigrateVariablesUnsupported),
+				opts.DestinationType)
+		}
 	}
 
+	// Per-workspace tag and variable results, reported together once every
+	// workspace has been copied.
+	var taggedWorkspaces []string
+	var tagFailures []string
+	var variablePlaceholders []string
+	var variableFailures []string
+
 	// Go through each and migrate
-	for _, name := range sourceWorkspaces {
+	for i, name := range sourceWorkspaces {
 
 		// Copy the same names
 		opts.sourceWorkspace = name
-		if newName, ok := defaultNewName[name]; ok {
-			// this has to be done before setting destinationWorkspace
-			name = newName
+		opts.destinationWorkspace = destinationNames[name]
+
+		if checkpoint != nil {
+			if done, ok := checkpoint.Completed[name]; ok {
+				// The interrupted run already put this workspace's state in
+				// place, so don't copy it again.
+				opts.destinationWorkspace = done
+				opts.log().decision(name, "skip", "reason", "checkpoint")
+				if opts.recorder != nil {
+					opts.recorder.recordSkipped(opts, "checkpoint")
+				}
+				if currentWorkspace == name {
+					newCurrentWorkspace = done
+				}
+				continue
+			}
 		}
-		opts.destinationWorkspace = strings.Replace(pattern, "*", name, -1)
 
 		// Force it, we confirmed above
 		opts.force = true
 
+		m.backendMigrateProgress(i+1, len(sourceWorkspaces), opts.sourceWorkspace, opts.destinationWorkspace)
+		started := time.Now()
+
 		// Perform the migration
 		log.Printf("[INFO] backendMigrateTFC: multi-to-multi migration, source workspace %q to destination workspace %q", opts.sourceWorkspace, opts.destinationWorkspace)
-		if err := m.backendMigrateState_s_s(opts); err != nil {
+		opts.hooks().PreMigrateWorkspace(opts.sourceWorkspace, opts.destinationWorkspace)
+		err := m.backendMigrateState_s_s(ctx, opts)
+		opts.hooks().PostMigrateWorkspace(opts.sourceWorkspace, opts.destinationWorkspace, err)
+		if err != nil {
 			return fmt.Errorf(strings.TrimSpace(
 				errMigrateMulti), name, opts.SourceType, opts.DestinationType, err)
 		}
+		m.backendMigrateElapsed(opts.sourceWorkspace, started)
+
+		// The state is in place, so a run interrupted from here on can skip
+		// this workspace. Failing to record that is fatal: continuing
+		// without it would defeat the point of checkpointing.
+		if checkpoint != nil {
+			checkpoint.Completed[name] = opts.destinationWorkspace
+			if err := checkpoint.save(opts.CheckpointPath); err != nil {
+				return fmt.Errorf(strings.TrimSpace(errMigrateCheckpointSave),
+					opts.CheckpointPath, err)
+			}
+		}
+
+		// With the state safely copied, make sure the destination workspace
+		// carries the configured tags. A tagging failure at this point is
+		// reported per workspace rather than aborting the migration, since
+		// the state itself is already in place.
+		if tagger, ok := opts.Destination.(workspaceTagger); ok {
+			tags, err := tagger.EnsureWorkspaceTags(opts.destinationWorkspace)
+			switch {
+			case err != nil:
+				tagFailures = append(tagFailures, fmt.Sprintf("%s: %s", opts.destinationWorkspace, err))
+			case len(tags) > 0:
+				taggedWorkspaces = append(taggedWorkspaces, fmt.Sprintf("%s: %s", opts.destinationWorkspace, strings.Join(tags, ", ")))
+				if opts.recorder != nil {
+					opts.recorder.recordTags(opts.destinationWorkspace, tags)
+				}
+			}
+		}
+
+		// Variables travel after the state for the same reason as tags: a
+		// failure here is reported per workspace rather than aborting the
+		// migration, since the state itself is already in place.
+		if opts.MigrateVariables {
+			migrated, placeholders, err := m.backendMigrateWorkspaceVariables(opts)
+			switch {
+			case err != nil:
+				variableFailures = append(variableFailures, fmt.Sprintf("%s: %s", opts.destinationWorkspace, err))
+			case len(migrated) > 0:
+				if opts.recorder != nil {
+					opts.recorder.recordVariables(opts.destinationWorkspace, migrated, placeholders)
+				}
+				if len(placeholders) > 0 {
+					variablePlaceholders = append(variablePlaceholders, fmt.Sprintf("%s: %s", opts.destinationWorkspace, strings.Join(placeholders, ", ")))
+				}
+			}
+		}
 
 		if currentWorkspace == opts.sourceWorkspace {
 			newCurrentWorkspace = opts.destinationWorkspace
 		}
 	}
 
+	if len(taggedWorkspaces) > 0 {
+		m.Ui.Output(m.Colorize().Color("[reset][bold]Tags attached to the migrated workspaces:[reset]"))
+		for _, line := range taggedWorkspaces {
+			m.Ui.Output(fmt.Sprintf("  - %s", line))
+		}
+	}
+	if len(tagFailures) > 0 {
+		m.Ui.Output(m.Colorize().Color(fmt.Sprintf(strings.TrimSpace(
+			outputBackendMigrateTagFailures), strings.Join(tagFailures, "\n  - "))))
+	}
+	if len(variablePlaceholders) > 0 {
+		m.Ui.Output(m.Colorize().Color(fmt.Sprintf(strings.TrimSpace(
+			outputBackendMigrateVariablePlaceholders), strings.Join(variablePlaceholders, "\n  - "))))
+	}
+	if len(variableFailures) > 0 {
+		m.Ui.Output(m.Colorize().Color(fmt.Sprintf(strings.TrimSpace(
+			outputBackendMigrateVariableFailures), strings.Join(variableFailures, "\n  - "))))
+	}
+
+	// Every workspace made it across, so the checkpoint has served its
+	// purpose; leaving it behind would make a future unrelated migration
+	// skip workspaces it never copied.
+	if checkpoint != nil {
+		if err := os.Remove(opts.CheckpointPath); err != nil && !os.IsNotExist(err) {
+			log.Printf("[WARN] backendMigrateTFC: failed to remove checkpoint file %s: %s", opts.CheckpointPath, err)
+		}
+	}
+
+	m.backendMigrateRenderStats(opts)
+
 	// After migrating multiple workspaces, we need to reselect the current workspace as it may
 	// have been renamed. Query the backend first to be sure it now exists.
 	workspaces, err := opts.Destination.Workspaces()
@@ -775,6 +4402,15 @@ func (m *Meta) backendMigrateState_S_TFC(opts *backendMigrateOpts, sourceWorkspa
 		return err
 	}
 
+	// The same listing doubles as a final reconciliation: every workspace
+	// that was expected to land in the destination must actually be there.
+	// A silent partial migration is worse than a loud failure here.
+	if !opts.DryRun {
+		if err := backendMigrateVerifyDestinationWorkspaces(opts, destinationNames, workspaces); err != nil {
+			return err
+		}
+	}
+
 	var workspacePresent bool
 	for _, name := range workspaces {
 		if name == newCurrentWorkspace {
@@ -837,96 +4473,622 @@ func (m *Meta) promptSingleToCloudSingleStateMigration(opts *backendMigrateOpts)
 		}
 	}
 
-	return migrate, nil
+	return migrate, nil
+}
+
+func (m *Meta) promptRemotePrefixToCloudTagsMigration(opts *backendMigrateOpts) error {
+	if !m.input {
+		log.Print("[TRACE] backendMigrateState: can't prompt for input, so aborting migration")
+		return errors.New(strings.TrimSpace(errInteractiveInputDisabled))
+	}
+	migrate := opts.force
+	if !migrate {
+		var err error
+		appName := "HCP Terraform"
+		if cloudBackend, ok := opts.Destination.(*cloud.Cloud); ok {
+			appName = cloudBackend.AppName()
+		}
+		migrate, err = m.confirm(&terraform.InputOpts{
+			Id:          "backend-migrate-remote-multistate-to-cloud",
+			Query:       "Do you wish to proceed?",
+			Description: fmt.Sprintf(strings.TrimSpace(tfcInputBackendMigrateRemoteMultiToCloud), appName),
+		})
+		if err != nil {
+			return fmt.Errorf("Error asking for state migration action: %s", err)
+		}
+	}
+
+	if !migrate {
+		return fmt.Errorf("Migration aborted by user.")
+	}
+
+	return nil
+}
+
+// Multi-state to single state.
+// promptMultiToSingleCloudWorkspaceChoice asks which source workspace's
+// state should become the state of the single name-strategy destination
+// workspace. An empty answer keeps the currently selected workspace, which
+// is also used without a prompt under -force-copy.
+func (m *Meta) promptMultiToSingleCloudWorkspaceChoice(opts *backendMigrateOpts, sourceWorkspaces []string, currentWorkspace string) (string, error) {
+	if opts.force {
+		return currentWorkspace, nil
+	}
+	if !m.input {
+		log.Print("[TRACE] backendMigrateState: can't prompt for input, so aborting migration")
+		return "", errors.New(strings.TrimSpace(errInteractiveInputDisabled))
+	}
+
+	var list strings.Builder
+	for _, name := range sourceWorkspaces {
+		if name == currentWorkspace {
+			fmt.Fprintf(&list, "  - %s (current)\n", name)
+		} else {
+			fmt.Fprintf(&list, "  - %s\n", name)
+		}
+	}
+	m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+		"[reset]Workspaces in the %q backend:\n%s", opts.SourceType, list.String())))
+
+	name, err := m.UIInput().Input(context.Background(), &terraform.InputOpts{
+		Id: "backend-migrate-multistate-to-single-choice",
+		Query: fmt.Sprintf(
+			"Which workspace's state should the %q workspace receive?",
+			opts.destinationWorkspace),
+		Description: strings.TrimSpace(inputBackendMigratePromoteWorkspace),
+	})
+	if err != nil {
+		return "", fmt.Errorf("Error asking for state migration action: %s", err)
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return currentWorkspace, nil
+	}
+	for _, candidate := range sourceWorkspaces {
+		if candidate == name {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf(strings.TrimSpace(errMigrateUnknownPromotedWorkspace),
+		name, opts.SourceType)
+}
+
+func (m *Meta) promptMultiToSingleCloudMigration(opts *backendMigrateOpts) error {
+	if !m.input {
+		log.Print("[TRACE] backendMigrateState: can't prompt for input, so aborting migration")
+		return errors.New(strings.TrimSpace(errInteractiveInputDisabled))
+	}
+	migrate := opts.force
+	if !migrate {
+		var err error
+		appName := "HCP Terraform"
+		if cloudBackend, ok := opts.Destination.(*cloud.Cloud); ok {
+			appName = cloudBackend.AppName()
+		}
+		// Ask the user if they want to migrate their existing remote state
+		migrate, err = m.confirm(&terraform.InputOpts{
+			Id:    "backend-migrate-multistate-to-single",
+			Query: fmt.Sprintf("Do you want to copy only the %q workspace?", opts.sourceWorkspace),
+			Description: fmt.Sprintf(
+				strings.TrimSpace(tfcInputBackendMigrateMultiToSingle),
+				opts.SourceType, opts.destinationWorkspace, appName, opts.sourceWorkspace),
+		})
+		if err != nil {
+			return fmt.Errorf("Error asking for state migration action: %s", err)
+		}
+	}
+
+	if !migrate {
+		return fmt.Errorf("Migration aborted by user.")
+	}
+
+	return nil
+}
+
+func (m *Meta) promptNewWorkspaceName(destinationType string) (string, error) {
+	message := fmt.Sprintf("[reset][bold][yellow]The %q backend configuration only allows "+
+		"named workspaces![reset]", destinationType)
+	if destinationType == "cloud" {
+		if !m.input {
+			log.Print("[TRACE] backendMigrateState: can't prompt for input, so aborting migration")
+			return "", errors.New(strings.TrimSpace(errInteractiveInputDisabled))
+		}
+		message = `[reset][bold][yellow]HCP Terraform and Terraform Enterprise require all workspaces to be given an explicit name.[reset]`
+	}
+	name, err := m.UIInput().Input(context.Background(), &terraform.InputOpts{
+		Id:          "new-state-name",
+		Query:       message,
+		Description: strings.TrimSpace(inputBackendNewWorkspaceName),
+	})
+	if err != nil {
+		return "", fmt.Errorf("Error asking for new state name: %s", err)
+	}
+
+	return name, nil
+}
+
+// backendMigrateRetryBaseDelay is the backoff before the first retry of a
+// transient destination error; every further retry doubles it.
+const backendMigrateRetryBaseDelay = 500 * time.Millisecond
+
+// backendMigrateIsTransientErr reports whether an error from a remote
+// backend looks transient enough to be worth retrying: a timeout, a dropped
+// connection, or a 5xx response. Authentication and conflict failures are
+// never considered transient, since retrying them would just repeat the
+// same rejection (or worse, hammer a lock held by someone else).
+func backendMigrateIsTransientErr(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, marker := range []string{"unauthorized", "forbidden", "conflict", "401", "403", "409"} {
+		if strings.Contains(message, marker) {
+			return false
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	for _, marker := range []string{
+		"timeout", "timed out", "connection reset", "broken pipe",
+		"temporarily unavailable",
+		"status code 500", "status code 502", "status code 503", "status code 504",
+		"internal server error", "bad gateway", "service unavailable", "gateway timeout",
+	} {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// backendMigrateRetry runs op, retrying up to opts.Retries extra times with
+// exponential backoff when the failure looks transient. revalidate, if
+// non-nil, runs before each retry so the caller can re-establish invariants
+// that the failed attempt may have invalidated; the state locks taken by the
+// caller remain held across every attempt.
+func (m *Meta) backendMigrateRetry(opts *backendMigrateOpts, description string, revalidate func() error, op func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := op()
+		if err == nil || attempt >= opts.Retries || !backendMigrateIsTransientErr(err) {
+			return err
+		}
+
+		delay := backendMigrateRetryBaseDelay << uint(attempt)
+		log.Printf("[WARN] backendMigrateRetry: transient error while %s: %s; retrying in %s", description, err, delay)
+		m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+			"[reset][yellow]Transient error while %s: %s\nRetrying in %s (retry %d of %d).[reset]",
+			description, err, delay, attempt+1, opts.Retries)))
+		time.Sleep(delay)
+
+		if revalidate != nil {
+			if err := revalidate(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// backendMigrateVerifyDestinationWorkspaces checks that every destination
+// workspace the migration resolved actually appears in the destination's
+// workspace list once all the copies have run. Source workspaces with empty
+// state are never copied, so their destinations are not expected to exist;
+// anything else missing means a copy silently didn't materialize, which is
+// reported as an error naming every missing workspace.
+func backendMigrateVerifyDestinationWorkspaces(opts *backendMigrateOpts, destinationNames map[string]string, present []string) error {
+	presentSet := make(map[string]bool, len(present))
+	for _, name := range present {
+		presentSet[name] = true
+	}
+
+	var missing []string
+	for source, destination := range destinationNames {
+		if presentSet[destination] {
+			continue
+		}
+		sourceState, err := opts.Source.StateMgr(source)
+		if err != nil {
+			return fmt.Errorf(strings.TrimSpace(
+				errMigrateLoadStates), opts.SourceType, err)
+		}
+		if err := sourceState.RefreshState(); err != nil {
+			return fmt.Errorf(strings.TrimSpace(
+				errMigrateLoadStates), opts.SourceType, err)
+		}
+		if sourceState.State().Empty() {
+			continue
+		}
+		missing = append(missing, fmt.Sprintf("%s (from source workspace %s)", destination, source))
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return fmt.Errorf(strings.TrimSpace(errMigrateDestinationWorkspacesMissing),
+		opts.DestinationType, strings.Join(missing, "\n  "))
+}
+
+// MigrateDiffResult classifies the workspaces of two backends ahead of a
+// state migration, as the information needed to judge whether migrating is
+// safe: what would be created, what would be left behind, and what already
+// matches or conflicts. All four slices are sorted by workspace name.
+type MigrateDiffResult struct {
+	// SourceOnly lists workspaces that exist only in the source, which a
+	// migration would create in the destination.
+	SourceOnly []string
+
+	// DestinationOnly lists workspaces that exist only in the destination,
+	// which a migration would leave untouched.
+	DestinationOnly []string
+
+	// Equal lists workspaces present on both sides whose states compare
+	// equal, so copying them would change nothing.
+	Equal []string
+
+	// Divergent lists workspaces present on both sides whose states differ,
+	// which a migration would overwrite in the destination.
+	Divergent []string
+}
+
+// MigrateDiff compares the workspace sets of two backends and classifies
+// each workspace by where it exists and, for workspaces present on both
+// sides, whether the two states are equal. A backend that holds only a
+// single state contributes just its default workspace. This is exported so
+// that callers embedding Terraform can make the same safety judgement a dry
+// run renders, without going through a migration.
+func MigrateDiff(src, dst backend.Backend) (*MigrateDiffResult, error) {
+	sourceInfo, err := backend.ListWorkspaces(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list source workspaces: %s", err)
+	}
+	destinationInfo, err := backend.ListWorkspaces(dst)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list destination workspaces: %s", err)
+	}
+
+	sourceNames := sourceInfo.Workspaces
+	if sourceInfo.SingleState {
+		sourceNames = []string{backend.DefaultStateName}
+	}
+	destinationNames := destinationInfo.Workspaces
+	if destinationInfo.SingleState {
+		destinationNames = []string{backend.DefaultStateName}
+	}
+
+	inDestination := make(map[string]bool, len(destinationNames))
+	for _, name := range destinationNames {
+		inDestination[name] = true
+	}
+	inSource := make(map[string]bool, len(sourceNames))
+	for _, name := range sourceNames {
+		inSource[name] = true
+	}
+
+	result := &MigrateDiffResult{}
+	for _, name := range destinationNames {
+		if !inSource[name] {
+			result.DestinationOnly = append(result.DestinationOnly, name)
+		}
+	}
+	for _, name := range sourceNames {
+		if !inDestination[name] {
+			result.SourceOnly = append(result.SourceOnly, name)
+			continue
+		}
+
+		sourceState, err := migrateDiffReadState(src, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read source state for workspace %q: %s", name, err)
+		}
+		destinationState, err := migrateDiffReadState(dst, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read destination state for workspace %q: %s", name, err)
+		}
+		if sourceState.Equal(destinationState) {
+			result.Equal = append(result.Equal, name)
+		} else {
+			result.Divergent = append(result.Divergent, name)
+		}
+	}
+
+	sort.Strings(result.SourceOnly)
+	sort.Strings(result.DestinationOnly)
+	sort.Strings(result.Equal)
+	sort.Strings(result.Divergent)
+	return result, nil
+}
+
+// migrateDiffReadState loads the current state snapshot of one workspace.
+func migrateDiffReadState(b backend.Backend, workspace string) (*states.State, error) {
+	stateMgr, err := b.StateMgr(workspace)
+	if err != nil {
+		return nil, err
+	}
+	if err := stateMgr.RefreshState(); err != nil {
+		return nil, err
+	}
+	return stateMgr.State(), nil
+}
+
+// backendMigrateScanEmptyWorkspaces reads each source workspace's state once
+// and partitions the names by whether the state holds anything, preserving
+// their order. This lets a multi-workspace migration skip all the empty
+// workspaces as one announced group before anything is copied, and gives a
+// dry run the same authoritative list to report. The scan only reads, so no
+// locks are taken; the per-workspace copy re-checks emptiness regardless.
+func backendMigrateScanEmptyWorkspaces(opts *backendMigrateOpts, sourceWorkspaces []string) (nonEmpty, empty []string, err error) {
+	for _, name := range sourceWorkspaces {
+		stateMgr, err := opts.Source.StateMgr(name)
+		if err != nil {
+			return nil, nil, fmt.Errorf(strings.TrimSpace(
+				errMigrateLoadStates), opts.SourceType, err)
+		}
+		if err := stateMgr.RefreshState(); err != nil {
+			return nil, nil, fmt.Errorf(strings.TrimSpace(
+				errMigrateLoadStates), opts.SourceType, err)
+		}
+		if state := stateMgr.State(); state.Empty() {
+			empty = append(empty, name)
+		} else {
+			nonEmpty = append(nonEmpty, name)
+		}
+	}
+	return nonEmpty, empty, nil
+}
+
+// backendMigrateCheckpoint is the on-disk record of a multi-workspace
+// migration's progress, written next to each workspace copy so that an
+// interrupted run can resume without recopying or re-prompting. The naming
+// inputs are recorded alongside the completed pairs because a resume that
+// chose a different pattern or default-workspace name would silently send
+// the remaining workspaces somewhere else.
+type backendMigrateCheckpoint struct {
+	// Pattern is the workspace rename pattern the run is using.
+	Pattern string `json:"pattern"`
+
+	// DefaultNewName records the prompted replacement names for source
+	// workspaces that needed one, keyed by source workspace.
+	DefaultNewName map[string]string `json:"default_new_name,omitempty"`
+
+	// Completed maps each source workspace whose state has landed in the
+	// destination to the destination workspace it was copied into.
+	Completed map[string]string `json:"completed"`
+}
+
+// loadMigrationCheckpoint reads a checkpoint file written by a previous run.
+// A missing file is not an error: it just means there is nothing to resume,
+// so the result is nil.
+func loadMigrationCheckpoint(path string) (*backendMigrateCheckpoint, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpoint backendMigrateCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, err
+	}
+	if checkpoint.Completed == nil {
+		checkpoint.Completed = map[string]string{}
+	}
+	return &checkpoint, nil
+}
+
+// save writes the checkpoint to the given path, replacing any previous
+// version atomically enough for this purpose: the file is small and written
+// in one call, so a crash leaves either the old or the new version.
+func (c *backendMigrateCheckpoint) save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0600)
 }
 
-func (m *Meta) promptRemotePrefixToCloudTagsMigration(opts *backendMigrateOpts) error {
-	if !m.input {
-		log.Print("[TRACE] backendMigrateState: can't prompt for input, so aborting migration")
-		return errors.New(strings.TrimSpace(errInteractiveInputDisabled))
+// checkMigrationDestinationCollisions fails when the resolved source-to-
+// destination workspace name mapping sends two or more source workspaces to
+// the same destination name, which would make the later copies silently
+// overwrite the earlier ones. The error names every colliding group.
+func checkMigrationDestinationCollisions(destinationNames map[string]string) error {
+	sources := make(map[string][]string, len(destinationNames))
+	for source, destination := range destinationNames {
+		sources[destination] = append(sources[destination], source)
 	}
-	migrate := opts.force
-	if !migrate {
-		var err error
-		appName := "HCP Terraform"
-		if cloudBackend, ok := opts.Destination.(*cloud.Cloud); ok {
-			appName = cloudBackend.AppName()
-		}
-		migrate, err = m.confirm(&terraform.InputOpts{
-			Id:          "backend-migrate-remote-multistate-to-cloud",
-			Query:       "Do you wish to proceed?",
-			Description: fmt.Sprintf(strings.TrimSpace(tfcInputBackendMigrateRemoteMultiToCloud), appName),
-		})
-		if err != nil {
-			return fmt.Errorf("Error asking for state migration action: %s", err)
+
+	var collisions []string
+	for destination, colliding := range sources {
+		if len(colliding) > 1 {
+			sort.Strings(colliding)
+			collisions = append(collisions, fmt.Sprintf(
+				"%s (from source workspaces %s)", destination, strings.Join(colliding, ", ")))
 		}
 	}
+	if len(collisions) == 0 {
+		return nil
+	}
+	sort.Strings(collisions)
+	return fmt.Errorf(strings.TrimSpace(errMigrateDestinationCollision),
+		strings.Join(collisions, "\n  "))
+}
 
-	if !migrate {
-		return fmt.Errorf("Migration aborted by user.")
+// resolveMigrationDestinationCollisions repairs a destination name mapping
+// that sends two or more source workspaces to the same name, by keeping the
+// name for the first source workspace in each colliding group and prompting
+// for an alternate name for the others. The caller has already checked that
+// input is enabled. A prompted name that still collides aborts with the
+// usual collision error rather than prompting again.
+func (m *Meta) resolveMigrationDestinationCollisions(opts *backendMigrateOpts, destinationNames map[string]string) error {
+	sources := make(map[string][]string, len(destinationNames))
+	for source, destination := range destinationNames {
+		sources[destination] = append(sources[destination], source)
 	}
 
-	return nil
+	var destinations []string
+	for destination, colliding := range sources {
+		if len(colliding) > 1 {
+			destinations = append(destinations, destination)
+		}
+	}
+	sort.Strings(destinations)
+
+	for _, destination := range destinations {
+		colliding := sources[destination]
+		sort.Strings(colliding)
+		for _, source := range colliding[1:] {
+			m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+				"[reset][yellow]Source workspaces %s all resolve to the destination name %q.\nChoose a different destination name for workspace %q.[reset]",
+				strings.Join(colliding, ", "), destination, source)))
+			name, err := m.promptNewWorkspaceName(opts.DestinationType)
+			if err != nil {
+				return err
+			}
+			destinationNames[source] = name
+		}
+	}
+
+	return checkMigrationDestinationCollisions(destinationNames)
 }
 
-// Multi-state to single state.
-func (m *Meta) promptMultiToSingleCloudMigration(opts *backendMigrateOpts) error {
-	if !m.input {
-		log.Print("[TRACE] backendMigrateState: can't prompt for input, so aborting migration")
-		return errors.New(strings.TrimSpace(errInteractiveInputDisabled))
-	}
-	migrate := opts.force
-	if !migrate {
-		var err error
-		appName := "HCP Terraform"
-		if cloudBackend, ok := opts.Destination.(*cloud.Cloud); ok {
-			appName = cloudBackend.AppName()
+// validateMigrationWorkspacePattern checks that a workspace rename pattern
+// contains exactly one '*', whether it came from a prompt or from the
+// -migrate-workspace-pattern flag.
+// validateMigrationDestinationRoutes checks every route before anything is
+// copied, so that a bad glob or an incomplete route aborts the migration
+// up front rather than partway through the copies.
+func validateMigrationDestinationRoutes(routes []MigrationDestinationRoute) error {
+	for i, route := range routes {
+		if _, err := path.Match(route.Pattern, ""); err != nil {
+			return fmt.Errorf(strings.TrimSpace(errMigrateInvalidRoute),
+				i+1, fmt.Sprintf("pattern %q is not a valid glob", route.Pattern))
 		}
-		// Ask the user if they want to migrate their existing remote state
-		migrate, err = m.confirm(&terraform.InputOpts{
-			Id:    "backend-migrate-multistate-to-single",
-			Query: "Do you want to copy only your current workspace?",
-			Description: fmt.Sprintf(
-				strings.TrimSpace(tfcInputBackendMigrateMultiToSingle),
-				opts.SourceType, opts.destinationWorkspace, appName),
-		})
-		if err != nil {
-			return fmt.Errorf("Error asking for state migration action: %s", err)
+		if route.Destination == nil {
+			return fmt.Errorf(strings.TrimSpace(errMigrateInvalidRoute),
+				i+1, "no destination backend is set")
+		}
+		if route.DestinationType == "" {
+			return fmt.Errorf(strings.TrimSpace(errMigrateInvalidRoute),
+				i+1, "no destination type is set")
 		}
 	}
+	return nil
+}
 
-	if !migrate {
-		return fmt.Errorf("Migration aborted by user.")
+// resolveMigrationDestinationRoute returns the backend that the named
+// workspace migrates to and its reporting name: the first route whose glob
+// matches wins, and a workspace matching no route falls back to the
+// migration's main destination.
+func resolveMigrationDestinationRoute(opts *backendMigrateOpts, name string) (backend.Backend, string) {
+	for _, route := range opts.DestinationRoutes {
+		if matched, _ := path.Match(route.Pattern, name); matched {
+			log.Printf("[TRACE] backendMigrateState: workspace %q routed to the %q backend by pattern %q", name, route.DestinationType, route.Pattern)
+			return route.Destination, route.DestinationType
+		}
 	}
+	return opts.Destination, opts.DestinationType
+}
 
+// validateMigrationWorkspacePattern checks a destination workspace rename
+// pattern without any I/O, so the same rules apply whether the pattern was
+// supplied with -migrate-workspace-pattern or entered at the interactive
+// prompt, and so the rules can be unit-tested directly.
+func validateMigrationWorkspacePattern(pattern string) error {
+	if migrationPatternIsTemplate(pattern) {
+		// Template patterns are validated by parsing them; the '*' rules
+		// below only apply to classic wildcard patterns.
+		_, err := parseMigrationPatternTemplate(pattern)
+		return err
+	}
+	if !strings.Contains(pattern, "*") {
+		return fmt.Errorf("The pattern must have an '*'")
+	}
+	if count := strings.Count(pattern, "*"); count > 1 {
+		return fmt.Errorf("The pattern '*' cannot be used more than once.")
+	}
 	return nil
 }
 
-func (m *Meta) promptNewWorkspaceName(destinationType string) (string, error) {
-	message := fmt.Sprintf("[reset][bold][yellow]The %q backend configuration only allows "+
-		"named workspaces![reset]", destinationType)
-	if destinationType == "cloud" {
-		if !m.input {
-			log.Print("[TRACE] backendMigrateState: can't prompt for input, so aborting migration")
-			return "", errors.New(strings.TrimSpace(errInteractiveInputDisabled))
-		}
-		message = `[reset][bold][yellow]HCP Terraform and Terraform Enterprise require all workspaces to be given an explicit name.[reset]`
+// migrationPatternIsTemplate distinguishes a rename pattern using HCL
+// template interpolation from a classic single-wildcard pattern.
+func migrationPatternIsTemplate(pattern string) bool {
+	return strings.Contains(pattern, "${")
+}
+
+// migrationPatternFuncs is the small set of string functions available in a
+// workspace rename pattern template. The full Terraform function table is
+// deliberately not exposed here: patterns run during init before providers
+// are even installed, and nothing beyond string manipulation makes sense
+// for deriving one workspace name from another.
+var migrationPatternFuncs = map[string]function.Function{
+	"lower":      stdlib.LowerFunc,
+	"upper":      stdlib.UpperFunc,
+	"replace":    stdlib.ReplaceFunc,
+	"trimprefix": stdlib.TrimPrefixFunc,
+	"trimsuffix": stdlib.TrimSuffixFunc,
+	"substr":     stdlib.SubstrFunc,
+}
+
+// parseMigrationPatternTemplate parses a rename pattern containing HCL
+// template interpolation, such as "app-${lower(name)}", so that a malformed
+// template is reported once up front rather than per workspace.
+func parseMigrationPatternTemplate(pattern string) (hcl.Expression, error) {
+	expr, diags := hclsyntax.ParseTemplate([]byte(pattern), "<workspace rename pattern>", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, fmt.Errorf(strings.TrimSpace(errMigrateInvalidPatternTemplate),
+			pattern, diags.Error())
 	}
-	name, err := m.UIInput().Input(context.Background(), &terraform.InputOpts{
-		Id:          "new-state-name",
-		Query:       message,
-		Description: strings.TrimSpace(inputBackendNewWorkspaceName),
-	})
+	return expr, nil
+}
+
+// evalMigrationPatternTemplate evaluates a parsed rename pattern template
+// for one source workspace, with the workspace's name bound to the "name"
+// variable and migrationPatternFuncs available.
+func evalMigrationPatternTemplate(expr hcl.Expression, name string) (string, error) {
+	evalCtx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"name": cty.StringVal(name),
+		},
+		Functions: migrationPatternFuncs,
+	}
+	v, diags := expr.Value(evalCtx)
+	if diags.HasErrors() {
+		return "", errors.New(diags.Error())
+	}
+	v, err := convert.Convert(v, cty.String)
 	if err != nil {
-		return "", fmt.Errorf("Error asking for new state name: %s", err)
+		return "", err
 	}
-
-	return name, nil
+	if v.IsNull() {
+		return "", errors.New("the template produced a null value")
+	}
+	return v.AsString(), nil
 }
 
-func (m *Meta) promptMultiStateMigrationPattern(sourceType string, appName string) (string, error) {
-	// This is not the first prompt a user would be presented with in the migration to TFC, so no
-	// guard on m.input is needed here.
+func (m *Meta) promptMultiStateMigrationPattern(sourceType string, appName string, supplied string) (string, error) {
+	// A pattern supplied on the command line is validated by the same rules
+	// as an interactive one, but skips the prompts entirely so CI-driven
+	// migrations don't block on input.
+	if supplied != "" {
+		if err := validateMigrationWorkspacePattern(supplied); err != nil {
+			return "", err
+		}
+		return supplied, nil
+	}
+
+	if !m.input {
+		// Without a supplied pattern the only way forward is to ask, and we
+		// can't.
+		return "", fmt.Errorf(strings.TrimSpace(errMigratePatternInputDisabled), appName)
+	}
+
 	renameWorkspaces, err := m.UIInput().Input(context.Background(), &terraform.InputOpts{
 		Id:          "backend-migrate-multistate-to-tfc",
 		Query:       fmt.Sprintf("[reset][bold][yellow]%s[reset]", "Would you like to rename your workspaces?"),
@@ -953,12 +5115,8 @@ func (m *Meta) promptMultiStateMigrationPattern(sourceType string, appName strin
 	if err != nil {
 		return "", fmt.Errorf("Error asking for state migration action: %s", err)
 	}
-	if !strings.Contains(pattern, "*") {
-		return "", fmt.Errorf("The pattern must have an '*'")
-	}
-
-	if count := strings.Count(pattern, "*"); count > 1 {
-		return "", fmt.Errorf("The pattern '*' cannot be used more than once.")
+	if err := validateMigrationWorkspacePattern(pattern); err != nil {
+		return "", err
 	}
 
 	return pattern, nil
@@ -974,6 +5132,16 @@ Terraform failed to load the states. The data in both the source and the
 destination remain unmodified. Please resolve the above error and try again.
 `
 
+const errMigrateSameLocation = `
+The %[1]q source backend and the %[2]q destination backend resolve to the
+same state storage location, so the "source" and "destination" are the same
+states. Migrating would lock the same state on both sides and deadlock
+against itself, so nothing has been migrated.
+
+Check the backend configuration for a path, bucket, or key that matches the
+previously-configured backend.
+`
+
 const errMigrateSingleLoadDefault = `
 Error loading state:
     %[2]s
@@ -985,6 +5153,170 @@ source and the destination remain unmodified. Please resolve the
 above error and try again.
 `
 
+const errMigrateFormatVersionTooOld = `
+The following source workspaces hold state snapshots older than the required
+state format version %[1]d:
+  %[2]s
+
+No workspaces have been migrated. Either upgrade these states first, for
+example by running "terraform refresh" against the source backend with a
+newer version of Terraform, or lower the -migrate-min-format-version
+threshold and try again.
+`
+
+const inputBackendMigrateSelectStrategy = `
+Terraform inspected the %[1]q and %[2]q backends and detected the
+%[3]s migration path for your workspaces (%[4]s).
+
+Because both backends support multiple workspaces, you may choose the
+migration mode explicitly instead. Enter 1 or 2.
+
+1. Copy all workspaces to the %[2]q backend.
+2. Copy only the current workspace, %[5]q.
+`
+
+const errMigrateWorkspaceLimit = `
+Migrating would create %[1]d new workspace(s) in a destination that already
+has %[2]d, for a projected total of %[3]d. This exceeds the limit of %[4]d
+workspaces set by %[5]s.
+
+No workspaces have been created or modified. Either migrate fewer workspaces,
+remove unused workspaces from the destination, or raise the limit and try
+again.
+`
+
+const errMigrateDestinationNewerVersion = `
+Workspace %[1]q in the destination requires Terraform %[2]s, which is newer
+than this version of Terraform (%[3]s). After migrating, this CLI would be
+unable to operate on that workspace's state.
+
+No state has been copied. Either upgrade Terraform to at least %[2]s, change
+the workspace's required version, or run again without -migrate-strict-version
+to proceed with a warning instead.
+`
+
+const errMigrateDestinationWorkspacesMissing = `
+After migrating, the following workspace(s) are missing from the %[1]q
+backend:
+  %[2]s
+
+Each listed workspace's state was copied without error, but the workspace
+does not appear in the destination's workspace list, so the migration is
+incomplete. Check the destination backend before relying on it; the source
+states have not been modified.
+`
+
+const errMigrateCloudProjectNotCloud = `
+The -migrate-cloud-project option was set, but the destination backend %q is
+not HCP Terraform or Terraform Enterprise. Projects only exist there, so the
+option has no meaning for this migration.
+`
+
+const errMigrateCloudProjectConflict = `
+The -migrate-cloud-project option requested project %[1]q, but the cloud
+configuration block already assigns workspaces to project %[2]q. Remove one of
+the two so that the migration has a single unambiguous project.
+`
+
+const errMigrateFromStateMissing = `
+Error reading the state file given to -migrate-from-state: the file %q
+could not be read: %s.
+`
+
+const errMigrateTimeout = `
+The state migration did not finish within the -migrate-timeout of %[1]s; the
+workspace in progress when the deadline hit was %[2]q.
+
+The underlying error was: %[3]s
+
+Workspaces copied before the deadline are in place in the destination. Check
+the backend that was hanging, then run the initialization command again with
+a larger timeout to migrate the remaining workspaces.
+`
+
+const errMigrateCheckpointLoad = `
+Error reading the migration checkpoint file %[1]s: %[2]s.
+
+Nothing has been copied. Fix or remove the checkpoint file and run the
+initialization command again.
+`
+
+const errMigrateCheckpointSave = `
+Error writing the migration checkpoint file %[1]s: %[2]s.
+
+The workspace that was just copied is in place, but its completion could not
+be recorded, so a resumed run would copy it again. Fix the checkpoint path
+and run the initialization command again to continue the migration.
+`
+
+const errRenameWorkspaceExists = `
+The workspace %[1]q already holds state, so workspace %[2]q cannot be renamed
+to it.
+
+Renaming never overwrites existing state. Either choose a different name or
+remove the existing workspace first.
+`
+
+const errRenameWorkspaceDelete = `
+The state of workspace %[1]q was copied to the new workspace %[2]q, but the
+old workspace could not be deleted:
+    %[3]s
+
+Both workspaces currently hold the state. Delete the old workspace manually
+once you have confirmed the new one is intact.
+`
+
+const errMigrateRenameCmdFailed = `
+The workspace rename command %q failed for the source workspace %q:
+    %s
+    %s
+
+No workspaces have been migrated. Please resolve the error in the rename
+command and try again.
+`
+
+const errMigrateRenameCmdEmpty = `
+The workspace rename command %q produced no output for the source
+workspace %q, so no destination workspace name is available for it.
+
+No workspaces have been migrated. The rename command must print the
+destination workspace name on standard output.
+`
+
+const errMigrateSuffixCollision = `
+The source workspace %q collides with an existing destination workspace, and
+the suffixed name %q already exists in the destination as well.
+
+No workspaces have been overwritten. Either choose a different
+-migrate-workspace-suffix value or remove the conflicting destination
+workspace and try again.
+`
+
+const errMigrateStateTooLarge = `
+The following source workspaces serialize to more than the destination's
+maximum state size of %[1]d bytes:
+  %[2]s
+
+Nothing has been uploaded. Either reduce the size of these states, raise the
+-migrate-max-state-size threshold, or migrate the remaining workspaces and
+handle the oversized ones separately.
+`
+
+const errMigrateMissingWorkspaces = `
+The following workspaces were requested with -migrate-workspaces but do not
+exist in the source %q backend:
+  %s
+
+Nothing has been migrated. Check the workspace names and try again.
+`
+
+const errMigrateInvalidProviderRename = `
+The -migrate-provider-rename option %q is not valid: %s.
+
+Each mapping must have the form old=new, where both sides are provider source
+addresses like "example-corp/aws". Nothing has been migrated.
+`
+
 const errMigrateMulti = `
 Error migrating the workspace %q from the previous %q backend
 to the newly configured %q backend:
@@ -999,6 +5331,208 @@ Please resolve the error above and run the initialization command again.
 This will attempt to copy (with permission) all workspaces again.
 `
 
+const errMigrateMultiParallel = `
+Error migrating some workspaces from the previous %q backend to the newly
+configured %q backend:
+  %s
+
+Because the workspaces were copied in parallel, workspaces other than the
+ones listed above may or may not have been copied. No workspaces in the
+source state have been modified.
+
+Please resolve the errors above and run the initialization command again.
+This will attempt to copy (with permission) all workspaces again.
+`
+
+const errMigrateDeleteSource = `
+Error deleting the migrated workspace %q from the previous %q backend:
+  %s
+
+The migration itself completed successfully, so the destination holds a full
+copy of every workspace. Workspaces that could not be deleted remain in the
+source backend and can be removed by hand.
+`
+
+const errMigrateInvalidWorkspaceMap = `
+Error loading the workspace name mapping file %s: %s
+
+The file must be a JSON object whose keys are source workspace names and
+whose values are the destination workspace names to use in place of the
+rename pattern, with no two sources mapped to the same destination.
+`
+
+const errMigrateInvalidManifest = `
+Error loading the migration manifest %s: %s
+
+The manifest must be a JSON object with a "workspaces" list in which every
+entry names a "source" workspace and its "destination" workspace, and may
+set "on_conflict" to one of "newest-serial", "source-wins" or "abort". The
+entries must cover exactly the workspaces that exist in the source backend.
+`
+
+// backendMigrateMaxWorkspaceNameLength is the longest destination workspace
+// name a rename pattern is allowed to produce, matching the limit HCP
+// Terraform imposes on workspace names.
+const backendMigrateMaxWorkspaceNameLength = 90
+
+// backendMigrateWorkspaceCountPromptThreshold is the number of distinct
+// destination workspaces at which a migration to HCP Terraform asks for an
+// extra confirmation of the total count before creating any of them.
+const backendMigrateWorkspaceCountPromptThreshold = 10
+
+const errMigrateRoutesTFC = `
+Routing workspaces to multiple destination backends is only supported when
+migrating between state backends. Migrations to or from HCP Terraform or
+Terraform Enterprise must use a single destination.
+`
+
+const errMigrateInvalidRoute = `
+Destination route %[1]d is not usable: %[2]s.
+
+Nothing has been copied. Every route needs a valid workspace name glob and a
+configured destination backend.
+`
+
+const errMigrateInvalidPatternTemplate = `
+The workspace rename pattern %[1]q is not a valid template:
+    %[2]s
+
+A pattern may either contain a single '*' that is replaced with each source
+workspace's name, or use template interpolation such as "app-${lower(name)}"
+with the source workspace's name bound to "name".
+`
+
+const errMigratePatternTemplateEval = `
+The workspace rename pattern %[1]q failed for source workspace %[2]q: %[3]s.
+
+Nothing has been copied. Fix the pattern and run the initialization command
+again.
+`
+
+const errMigrateInvalidPatternResult = `
+Source workspace %[1]q would be renamed to %[2]q in the destination, which
+is not a usable workspace name.
+
+Destination workspace names must be non-empty and at most %[3]d characters
+long. Adjust the rename pattern, or map this workspace to an explicit name
+with -migrate-workspace-map, then run the initialization command again.
+`
+
+const errMigrateInvalidDestinationNames = `
+The %[1]q backend rejected the following destination workspace names:
+  - %[2]s
+
+No state has been copied. Rename the offending workspaces, for example with
+the -migrate-workspace-map or -migrate-workspace-rename-cmd options, so that every
+destination name is acceptable, then run the initialization command again.
+`
+
+const errMigrateUnknownPromotedWorkspace = `
+There is no workspace named %q in the %q source backend.
+
+Answer with one of the workspace names listed above, or press enter to
+migrate the currently selected workspace.
+`
+
+const errMigratePreserveSerial = `
+Failed to preserve the source state serial on destination workspace %[1]q in
+the %[2]q backend: %[3]s.
+
+The state itself was copied successfully; only the serial rewrite failed, so
+the destination may hold the copied state under a different serial.
+`
+
+const errMigrateDeleteSourceAfterCopy = `
+Failed to delete workspace %[1]q from the %[2]q backend after copying: %[3]s.
+
+The copied state has been persisted in the destination, so no state has been
+lost; only the cleanup of the source failed. Delete the source workspace
+manually if it is no longer needed.
+`
+
+const errMigrateBackup = `
+Failed to back up the existing state of destination workspace %q: %s.
+
+No state has been overwritten. Fix the problem with the backup directory and
+run the initialization command again, or drop the -migrate-backup-dir option
+to migrate without backups.
+`
+
+const errMigrateVerify = `
+Verification of workspace %q after migration failed: re-reading it from the
+newly configured %q backend returned %s.
+
+No workspaces in the source state have been modified, but the copy in the
+destination may be truncated or stale. Please check the destination backend
+and run the initialization command again.
+`
+
+const errMigrateVariablesUnsupported = `
+The %q backend does not store workspace variables, so the -migrate-variables
+option cannot be used with it. Variable migration is only possible between
+backends that keep variables alongside the state, such as HCP Terraform.
+`
+
+const outputBackendMigrateVariablePlaceholders = `
+[reset][bold][yellow]Sensitive variables were migrated as empty placeholders:[reset]
+  - %s
+
+The values of sensitive variables cannot be read back from the source, so
+these variables exist in the destination but hold no value. Re-enter their
+values in HCP Terraform before running any operations there.
+`
+
+const outputBackendMigrateVariableFailures = `
+[reset][bold][yellow]Failed to copy variables to some migrated workspaces:[reset]
+  - %s
+
+The state of these workspaces was migrated successfully; only the variable
+copy failed. Recreate the variables in HCP Terraform, or run the
+initialization command again, to finish the job.
+`
+
+const outputBackendMigrateTagFailures = `
+[reset][bold][yellow]Failed to attach tags to some migrated workspaces:[reset]
+  - %s
+
+The state of these workspaces was migrated successfully; only the tag update
+failed. Add the tags in HCP Terraform, or run the initialization command
+again, to finish the job.
+`
+
+const errMigrateDestinationCollision = `
+More than one source workspace would be migrated to the same destination
+workspace name:
+  %s
+
+Nothing has been copied. Adjust the rename pattern or the workspace name
+mapping so that every source workspace resolves to a unique destination
+name, then run the initialization command again.
+`
+
+const errMigratePatternInputDisabled = `
+Migrating multiple workspaces to %s requires a destination workspace naming
+pattern, and interactive input is disabled.
+
+Re-run the initialization command with -migrate-workspace-pattern to supply
+the pattern non-interactively, or enable input.
+`
+
+const errMigrateConflictAbort = `
+Workspace %q in the newly configured %q backend already has state, and the
+"abort" conflict strategy is in effect.
+
+No state has been copied. Either resolve the conflict by hand or re-run the
+initialization command with a different -migrate-conflict strategy.
+`
+
+const errMigrateConflictNoMeta = `
+Either the previous %q backend or the newly configured %q backend does not
+expose state snapshot metadata, so the %q conflict strategy cannot compare
+serials. Re-run the initialization command with a different -migrate-conflict
+strategy, or resolve the conflict interactively.
+`
+
 const errBackendStateCopy = `
 Error copying state from the previous %q backend to the newly configured
 %q backend:
@@ -1027,6 +5561,10 @@ previous names. The asterisk represents the current workspace name.
 
 For example, if a workspace is currently named 'prod', the pattern 'app-*' would yield
 'app-prod' for a new workspace name; 'app-*-region1' would  yield 'app-prod-region1'.
+
+Alternatively, enter a template interpolation with the current name bound to
+"name" and the lower, upper, replace, trimprefix, trimsuffix and substr
+functions available. For example, 'app-${lower(replace(name,"/","-"))}'.
 `
 
 // Done
@@ -1050,9 +5588,9 @@ would you like to rename your workspaces? Enter 1 or 2.
 const tfcInputBackendMigrateMultiToSingle = `
 The previous backend %[1]q has multiple workspaces, but %[3]s has
 been configured to use a single workspace (%[2]q). By continuing, you will
-only migrate your current workspace. If you wish to migrate all workspaces
-from the previous backend, you may cancel this operation and use the 'tags'
-strategy in your workspace configuration block instead.
+only migrate the state of the %[4]q workspace. If you wish to migrate all
+workspaces from the previous backend, you may cancel this operation and use
+the 'tags' strategy in your workspace configuration block instead.
 
 Enter "yes" to proceed or "no" to cancel.
 `
@@ -1100,6 +5638,13 @@ No existing state was found in %[2]s. Do you want to copy this state to %[2]s?
 Enter "yes" to copy and "no" to start with an empty state.
 `
 
+const inputBackendMigrateEmptyDelete = `
+As a third option you can enter "copy-and-delete": once the copied state has
+been successfully persisted in the destination, workspace %[1]q will be
+PERMANENTLY DELETED from the %[2]q backend. Only choose this for an
+intentional move; the deletion cannot be undone.
+`
+
 const inputBackendMigrateNonEmpty = `
 Pre-existing state was found while migrating the previous %q backend to the
 newly configured %q backend. An existing non-empty state already exists in
@@ -1114,6 +5659,13 @@ Enter "yes" to copy and "no" to start with the existing state in the newly
 configured %[2]q backend.
 `
 
+const outputBackendMigrateLineageMismatch = `
+[reset][bold][red]Warning: the source and destination states have unrelated lineages.[reset]
+They do not appear to be snapshots of the same environment, so continuing will
+overwrite workspace %q in the %q backend with state from a different
+environment. Make very sure this is what you want before answering "yes".
+`
+
 // Done
 const inputBackendMigrateNonEmptyCloud = `
 Pre-existing state was found while migrating the previous %q backend to
@@ -1128,6 +5680,12 @@ Do you want to overwrite the state in %[4]s with the previous state?
 Enter "yes" to copy and "no" to start with the existing state in %.
 `
 
+const inputBackendMigratePromoteWorkspace = `
+Enter the name of the source workspace whose state should be migrated into
+the single destination workspace, or press enter to use the currently
+selected workspace. The other source workspaces won't be copied or modified.
+`
+
 const inputBackendMigrateMultiToSingle = `
 The existing %[1]q backend supports workspaces and you currently are
 using more than one. The newly configured %[2]q backend doesn't support
@@ -1143,14 +5701,43 @@ support workspaces. When migrating between backends, Terraform will copy
 all workspaces (with the same names). THIS WILL OVERWRITE any conflicting
 states in the destination.
 
-Terraform initialization doesn't currently migrate only select workspaces.
-If you want to migrate a select number of workspaces, you must manually
-pull and push those states.
+If you want to migrate only your current workspace, answer "no" and re-run
+init with the -migrate-current-only option; for any other subset of
+workspaces, use the -migrate-workspaces option.
 
 If you answer "yes", Terraform will migrate all states. If you answer
 "no", Terraform will abort.
 `
 
+const inputBackendMigrateConfirmAll = `
+The actions listed above are everything this migration from the %[1]q backend
+to the %[2]q backend will do; no further confirmation will be asked for any of
+them.
+
+If you answer "yes", Terraform will perform all of the listed actions. If you
+answer "no", Terraform will abort and nothing will be copied.
+`
+
+const inputBackendMigrateWorkspaceCount = `
+Each listed name is a workspace that will be created in %[1]s if it does not
+exist yet. Check that the count and the names are what you expect before
+continuing.
+
+If you answer "yes", Terraform will migrate all states. If you answer
+"no", Terraform will abort and nothing will be copied or created.
+`
+
+const inputBackendMigrateMultiToMultiCurrent = `
+Both the existing %[1]q backend and the newly configured %[2]q backend
+support workspaces, but the -migrate-current-only option is set, so
+Terraform will copy ONLY your current workspace %[3]q to the new backend.
+Your other workspaces in the source backend won't be copied or modified,
+and they won't be available from the new backend.
+
+If you answer "yes", Terraform will migrate only the current workspace. If
+you answer "no", Terraform will abort.
+`
+
 const inputBackendNewWorkspaceName = `
 Please provide a new workspace name (e.g. dev, test) that will be used
 to migrate the existing default workspace.
@@ -4,7 +4,37 @@
 package command
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/hashicorp/cli"
+
+	"github.com/hashicorp/terraform/internal/addrs"
+	"github.com/hashicorp/terraform/internal/backend"
+	backendLocal "github.com/hashicorp/terraform/internal/backend/local"
+	"github.com/hashicorp/terraform/internal/cloud"
+	"github.com/hashicorp/terraform/internal/schemarepo"
+	"github.com/hashicorp/terraform/internal/states"
+	"github.com/hashicorp/terraform/internal/states/statemgr"
+	"github.com/hashicorp/terraform/internal/terraform"
+	"github.com/hashicorp/terraform/internal/tfdiags"
+	tfversion "github.com/hashicorp/terraform/version"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestBackendMigrate_promptMultiStatePattern(t *testing.T) {
@@ -41,6 +71,7 @@ func TestBackendMigrate_promptMultiStatePattern(t *testing.T) {
 	for name, tc := range cases {
 		t.Log("Test: ", name)
 		m := testMetaBackend(t, nil)
+		m.input = true
 		input := map[string]string{}
 		cleanup := testInputMap(t, input)
 		if tc.renamePrompt != "" {
@@ -51,7 +82,7 @@ func TestBackendMigrate_promptMultiStatePattern(t *testing.T) {
 		}
 
 		sourceType := "cloud"
-		_, err := m.promptMultiStateMigrationPattern(sourceType, "HCP Terraform")
+		_, err := m.promptMultiStateMigrationPattern(sourceType, "HCP Terraform", "")
 		if tc.expectedErr == "" && err != nil {
 			t.Fatalf("expected error to be nil, but was %s", err.Error())
 		}
@@ -61,4 +92,5227 @@ func TestBackendMigrate_promptMultiStatePattern(t *testing.T) {
 
 		cleanup()
 	}
+
+	// A pattern supplied on the command line skips the prompts entirely, so
+	// no input is configured for these cases at all.
+	t.Run("supplied pattern", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		pattern, err := m.promptMultiStateMigrationPattern("cloud", "HCP Terraform", "app-*")
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if pattern != "app-*" {
+			t.Fatalf("wrong pattern %q; want %q", pattern, "app-*")
+		}
+	})
+
+	t.Run("supplied pattern is validated", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		_, err := m.promptMultiStateMigrationPattern("cloud", "HCP Terraform", "app")
+		if err == nil || err.Error() != "The pattern must have an '*'" {
+			t.Fatalf("wrong error %v", err)
+		}
+		_, err = m.promptMultiStateMigrationPattern("cloud", "HCP Terraform", "*-app-*")
+		if err == nil || err.Error() != "The pattern '*' cannot be used more than once." {
+			t.Fatalf("wrong error %v", err)
+		}
+	})
+
+	t.Run("input disabled without a pattern", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		m.input = false
+		_, err := m.promptMultiStateMigrationPattern("cloud", "HCP Terraform", "")
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "-migrate-workspace-pattern") {
+			t.Fatalf("error does not mention the flag: %s", err)
+		}
+	})
+}
+
+func TestBackendMigrate_recordProvenance(t *testing.T) {
+	m := testMetaBackend(t, nil)
+	ui := cli.NewMockUi()
+	m.Ui = ui
+	opts := &backendMigrateOpts{
+		SourceType:       "consul",
+		DestinationType:  "local",
+		RecordProvenance: true,
+	}
+
+	// The filesystem state manager supports annotations, so migrating into
+	// it must record a provenance note.
+	statePath := filepath.Join(t.TempDir(), "terraform.tfstate")
+	destination := statemgr.NewFilesystem(statePath)
+	m.recordMigrationProvenance(destination, opts)
+
+	data, err := ioutil.ReadFile(statePath + ".annotations.json")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	annotations := map[string]string{}
+	if err := json.Unmarshal(data, &annotations); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	note, ok := annotations["migration-provenance"]
+	if !ok {
+		t.Fatalf("no migration-provenance annotation recorded: %#v", annotations)
+	}
+	if !strings.Contains(note, `migrated from "consul"`) {
+		t.Fatalf("provenance note does not mention the source backend: %q", note)
+	}
+
+	if output := ui.OutputWriter.String(); output != "" {
+		t.Fatalf("unexpected output: %s", output)
+	}
+}
+
+func TestBackendMigrate_recordProvenanceUnsupported(t *testing.T) {
+	m := testMetaBackend(t, nil)
+	ui := cli.NewMockUi()
+	m.Ui = ui
+	opts := &backendMigrateOpts{
+		SourceType:       "consul",
+		DestinationType:  "inmem",
+		RecordProvenance: true,
+
+		// Normally populated by backendMigrateState.
+		provenanceWarnOnce: new(sync.Once),
+	}
+
+	// A state manager without annotation support must produce a warning
+	// rather than an error, and only once even if several workspaces are
+	// migrated with the same opts.
+	destination := statemgr.NewFullFake(nil, nil)
+	m.recordMigrationProvenance(destination, opts)
+	m.recordMigrationProvenance(destination, opts)
+
+	output := ui.OutputWriter.String()
+	if !strings.Contains(output, "does not support state metadata") {
+		t.Fatalf("missing warning about unsupported metadata:\n%s", output)
+	}
+	if strings.Count(output, "does not support state metadata") != 1 {
+		t.Fatalf("warning should be emitted only once:\n%s", output)
+	}
+}
+
+func TestBackendMigrate_chooseStrategy(t *testing.T) {
+	cases := map[string]struct {
+		sourceTFC, destinationTFC                 bool
+		sourceSingleState, destinationSingleState bool
+		sourceWorkspaces                          []string
+		want                                      backendMigrateStrategy
+	}{
+		"tfc destination": {
+			destinationTFC:   true,
+			sourceWorkspaces: []string{"default", "dev"},
+			want:             migrateStrategyTFC,
+		},
+		"tfc source": {
+			sourceTFC: true,
+			want:      migrateStrategyTFC,
+		},
+		"single to single": {
+			sourceSingleState:      true,
+			destinationSingleState: true,
+			want:                   migrateStrategySingle,
+		},
+		"single to multi": {
+			sourceSingleState: true,
+			want:              migrateStrategySingle,
+		},
+		"multi with only default to single": {
+			destinationSingleState: true,
+			sourceWorkspaces:       []string{"default"},
+			want:                   migrateStrategySingle,
+		},
+		"multi to single": {
+			destinationSingleState: true,
+			sourceWorkspaces:       []string{"default", "dev"},
+			want:                   migrateStrategyMultiToSingle,
+		},
+		"multi with only default to multi": {
+			sourceWorkspaces: []string{"default"},
+			want:             migrateStrategySingle,
+		},
+		"multi to multi": {
+			sourceWorkspaces: []string{"default", "dev"},
+			want:             migrateStrategyMultiToMulti,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := backendMigrateChooseStrategy(tc.sourceTFC, tc.destinationTFC,
+				tc.sourceSingleState, tc.destinationSingleState, tc.sourceWorkspaces)
+			if got != tc.want {
+				t.Fatalf("wrong strategy %q; want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// stateMgrBackend is a backend.Backend serving pre-built state managers by
+// workspace name. The embedded interface is left nil; only StateMgr and
+// Workspaces may be called.
+type stateMgrBackend struct {
+	backend.Backend
+	mgrs map[string]statemgr.Full
+}
+
+func (b *stateMgrBackend) StateMgr(workspace string) (statemgr.Full, error) {
+	mgr, ok := b.mgrs[workspace]
+	if !ok {
+		return nil, fmt.Errorf("no such workspace %q", workspace)
+	}
+	return mgr, nil
+}
+
+func (b *stateMgrBackend) Workspaces() ([]string, error) {
+	var names []string
+	for name := range b.mgrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func TestBackendMigrate_checkFormatVersions(t *testing.T) {
+	td := t.TempDir()
+	writeState := func(name, content string) statemgr.Full {
+		path := filepath.Join(td, name+".tfstate")
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		return statemgr.NewFilesystem(path)
+	}
+
+	m := testMetaBackend(t, nil)
+	m.migrateMinFormatVersion = 4
+	opts := &backendMigrateOpts{
+		SourceType:      "consul",
+		DestinationType: "local",
+		Source: &stateMgrBackend{mgrs: map[string]statemgr.Full{
+			"modern": writeState("modern", `{"version": 4}`),
+			"legacy": writeState("legacy", `{"version": 3}`),
+			"older":  writeState("older", `{"version": 1}`),
+		}},
+	}
+
+	err := m.backendMigrateCheckFormatVersions(opts, []string{"modern", "legacy", "older"}, false)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "legacy (format version 3)") {
+		t.Fatalf("error does not report the legacy workspace: %s", err)
+	}
+	if !strings.Contains(err.Error(), "older (format version 1)") {
+		t.Fatalf("error does not report the older workspace: %s", err)
+	}
+	if strings.Contains(err.Error(), "modern") {
+		t.Fatalf("error should not mention compliant workspaces: %s", err)
+	}
+
+	// With a threshold every workspace meets, the check passes.
+	m.migrateMinFormatVersion = 1
+	if err := m.backendMigrateCheckFormatVersions(opts, []string{"modern", "legacy", "older"}, false); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestBackendMigrate_selectStrategy(t *testing.T) {
+	cases := map[string]struct {
+		prompt      string
+		want        backendMigrateStrategy
+		wantSource  string
+		expectedErr string
+	}{
+		"copy all workspaces": {
+			prompt:     "1",
+			want:       migrateStrategyMultiToMulti,
+			wantSource: backend.DefaultStateName,
+		},
+		"copy only current workspace": {
+			prompt:     "2",
+			want:       migrateStrategySingle,
+			wantSource: "default",
+		},
+		"invalid selection": {
+			prompt:      "3",
+			expectedErr: "Please select 1 or 2 as part of this option.",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := testMetaBackend(t, nil)
+			m.input = true
+			cleanup := testInputMap(t, map[string]string{
+				"backend-migrate-select-strategy": tc.prompt,
+			})
+			defer cleanup()
+
+			opts := &backendMigrateOpts{
+				SourceType:           "consul",
+				DestinationType:      "local",
+				sourceWorkspace:      backend.DefaultStateName,
+				destinationWorkspace: backend.DefaultStateName,
+			}
+			got, err := m.backendMigrateSelectStrategy(opts, migrateStrategyMultiToMulti,
+				false, false, false, false, []string{"default", "dev"})
+			if tc.expectedErr != "" {
+				if err == nil || err.Error() != tc.expectedErr {
+					t.Fatalf("wrong error %v; want %s", err, tc.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			if got != tc.want {
+				t.Fatalf("wrong strategy %q; want %q", got, tc.want)
+			}
+			if opts.sourceWorkspace != tc.wantSource {
+				t.Fatalf("wrong source workspace %q; want %q", opts.sourceWorkspace, tc.wantSource)
+			}
+		})
+	}
+
+	// Unambiguous situations never prompt, even with no input configured.
+	t.Run("single-state source keeps detected strategy", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		opts := &backendMigrateOpts{SourceType: "consul", DestinationType: "local"}
+		got, err := m.backendMigrateSelectStrategy(opts, migrateStrategySingle,
+			false, false, true, false, nil)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if got != migrateStrategySingle {
+			t.Fatalf("wrong strategy %q; want %q", got, migrateStrategySingle)
+		}
+	})
+}
+
+func TestBackendMigrate_parseProviderRenames(t *testing.T) {
+	t.Run("valid mappings", func(t *testing.T) {
+		renames, err := parseProviderRenames([]string{
+			"old-corp/aws=new-corp/aws",
+			"hashicorp/null=example/null",
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if len(renames) != 2 {
+			t.Fatalf("wrong number of renames %d; want 2", len(renames))
+		}
+		oldAddr := addrs.MustParseProviderSourceString("old-corp/aws")
+		newAddr, ok := renames[oldAddr]
+		if !ok {
+			t.Fatalf("no mapping for %s: %#v", oldAddr, renames)
+		}
+		if want := addrs.MustParseProviderSourceString("new-corp/aws"); !newAddr.Equals(want) {
+			t.Fatalf("wrong mapping %s; want %s", newAddr, want)
+		}
+	})
+
+	for name, mapping := range map[string]string{
+		"missing separator":   "old-corp/aws",
+		"empty new side":      "old-corp/aws=",
+		"invalid old address": "not a provider=new-corp/aws",
+		"invalid new address": "old-corp/aws=not a provider",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseProviderRenames([]string{mapping}); err == nil {
+				t.Fatalf("expected error for %q, got nil", mapping)
+			}
+		})
+	}
+
+	t.Run("duplicate old address", func(t *testing.T) {
+		_, err := parseProviderRenames([]string{
+			"old-corp/aws=new-corp/aws",
+			"old-corp/aws=other-corp/aws",
+		})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "mapped more than once") {
+			t.Fatalf("wrong error: %s", err)
+		}
+	})
+}
+
+func TestBackendMigrate_renameStateProviders(t *testing.T) {
+	providerConfig := func(source string) addrs.AbsProviderConfig {
+		return addrs.AbsProviderConfig{
+			Module:   addrs.RootModule,
+			Provider: addrs.MustParseProviderSourceString(source),
+		}
+	}
+
+	state := states.NewState()
+	root := state.EnsureModule(addrs.RootModuleInstance)
+	root.SetResourceInstanceCurrent(addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "aws_instance",
+		Name: "renamed",
+	}.Instance(addrs.NoKey), &states.ResourceInstanceObjectSrc{
+		Status:    states.ObjectReady,
+		AttrsJSON: []byte(`{"id":"a"}`),
+	}, providerConfig("old-corp/aws"))
+	root.SetResourceInstanceCurrent(addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "null_resource",
+		Name: "untouched",
+	}.Instance(addrs.NoKey), &states.ResourceInstanceObjectSrc{
+		Status:    states.ObjectReady,
+		AttrsJSON: []byte(`{"id":"b"}`),
+	}, providerConfig("hashicorp/null"))
+
+	renames := map[addrs.Provider]addrs.Provider{
+		addrs.MustParseProviderSourceString("old-corp/aws"): addrs.MustParseProviderSourceString("new-corp/aws"),
+	}
+	applied := renameStateProviders(state, renames)
+	if len(applied) != 1 {
+		t.Fatalf("wrong number of applied renames %d; want 1: %#v", len(applied), applied)
+	}
+	if !strings.Contains(applied[0], "aws_instance.renamed") {
+		t.Fatalf("wrong rename description: %s", applied[0])
+	}
+
+	renamed := state.Resource(addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "aws_instance",
+		Name: "renamed",
+	}.Absolute(addrs.RootModuleInstance))
+	if got, want := renamed.ProviderConfig.Provider, addrs.MustParseProviderSourceString("new-corp/aws"); !got.Equals(want) {
+		t.Fatalf("wrong provider %s; want %s", got, want)
+	}
+	untouched := state.Resource(addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "null_resource",
+		Name: "untouched",
+	}.Absolute(addrs.RootModuleInstance))
+	if got, want := untouched.ProviderConfig.Provider, addrs.MustParseProviderSourceString("hashicorp/null"); !got.Equals(want) {
+		t.Fatalf("wrong provider %s; want %s", got, want)
+	}
+}
+
+func TestBackendMigrate_redactSensitiveState(t *testing.T) {
+	providerAddr := addrs.AbsProviderConfig{
+		Module:   addrs.RootModule,
+		Provider: addrs.NewDefaultProvider("test"),
+	}
+
+	state := states.NewState()
+	root := state.EnsureModule(addrs.RootModuleInstance)
+	root.SetResourceInstanceCurrent(addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_thing",
+		Name: "secretive",
+	}.Instance(addrs.NoKey), &states.ResourceInstanceObjectSrc{
+		Status:    states.ObjectReady,
+		AttrsJSON: []byte(`{"id":"a","password":"hunter2","nested":{"tokens":["t0","t1"]}}`),
+		AttrSensitivePaths: []cty.Path{
+			cty.GetAttrPath("password"),
+			cty.GetAttrPath("nested").GetAttr("tokens").IndexInt(1),
+			cty.GetAttrPath("absent"),
+		},
+	}, providerAddr)
+	state.SetOutputValue(
+		addrs.OutputValue{Name: "secret"}.Absolute(addrs.RootModuleInstance),
+		cty.StringVal("hunter2"), true,
+	)
+	state.SetOutputValue(
+		addrs.OutputValue{Name: "plain"}.Absolute(addrs.RootModuleInstance),
+		cty.StringVal("visible"), false,
+	)
+
+	redacted := redactSensitiveState(state)
+
+	instance := redacted.ResourceInstance(addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_thing",
+		Name: "secretive",
+	}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance))
+	var attrs map[string]interface{}
+	if err := json.Unmarshal(instance.Current.AttrsJSON, &attrs); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := attrs["password"]; got != redactedValuePlaceholder {
+		t.Errorf("wrong password value %#v; want the placeholder", got)
+	}
+	if got := attrs["id"]; got != "a" {
+		t.Errorf("non-sensitive attribute changed: %#v", got)
+	}
+	tokens := attrs["nested"].(map[string]interface{})["tokens"].([]interface{})
+	if got := tokens[0]; got != "t0" {
+		t.Errorf("non-sensitive element changed: %#v", got)
+	}
+	if got := tokens[1]; got != redactedValuePlaceholder {
+		t.Errorf("wrong sensitive element %#v; want the placeholder", got)
+	}
+
+	if got := redacted.RootOutputValues["secret"].Value; got != cty.StringVal(redactedValuePlaceholder) {
+		t.Errorf("wrong sensitive output %#v; want the placeholder", got)
+	}
+	if got := redacted.RootOutputValues["plain"].Value; got != cty.StringVal("visible") {
+		t.Errorf("non-sensitive output changed: %#v", got)
+	}
+
+	// The original state must be untouched.
+	original := state.ResourceInstance(addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_thing",
+		Name: "secretive",
+	}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance))
+	if !strings.Contains(string(original.Current.AttrsJSON), "hunter2") {
+		t.Error("redaction modified the original state")
+	}
+}
+
+func TestBackendMigrate_formatStateMigrationDiff(t *testing.T) {
+	providerAddr := addrs.AbsProviderConfig{
+		Module:   addrs.RootModule,
+		Provider: addrs.NewDefaultProvider("test"),
+	}
+	buildState := func(objs map[string]string) *states.State {
+		state := states.NewState()
+		root := state.EnsureModule(addrs.RootModuleInstance)
+		for name, attrs := range objs {
+			root.SetResourceInstanceCurrent(addrs.Resource{
+				Mode: addrs.ManagedResourceMode,
+				Type: "test_instance",
+				Name: name,
+			}.Instance(addrs.NoKey), &states.ResourceInstanceObjectSrc{
+				Status:    states.ObjectReady,
+				AttrsJSON: []byte(attrs),
+			}, providerAddr)
+		}
+		return state
+	}
+
+	source := buildState(map[string]string{
+		"added":   `{"id":"a"}`,
+		"changed": `{"id":"b","value":"new"}`,
+		"same":    `{"id":"c"}`,
+	})
+	destination := buildState(map[string]string{
+		"removed": `{"id":"d"}`,
+		"changed": `{"id":"b","value":"old"}`,
+		"same":    `{"id":"c"}`,
+	})
+
+	diff := formatStateMigrationDiff(source, destination)
+	for _, want := range []string{
+		"+[reset] test_instance.added",
+		"-[reset] test_instance.removed",
+		"~[reset] test_instance.changed",
+	} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff is missing %q:\n%s", want, diff)
+		}
+	}
+	if strings.Contains(diff, "test_instance.same") {
+		t.Errorf("diff mentions unchanged resource:\n%s", diff)
+	}
+
+	// Equal states produce the no-differences note instead of an empty body.
+	if diff := formatStateMigrationDiff(source, source.DeepCopy()); !strings.Contains(diff, "no resource-level differences") {
+		t.Errorf("missing no-differences note:\n%s", diff)
+	}
+
+	// A huge state truncates to the cap plus a summary of what was left out.
+	bigObjs := map[string]string{}
+	for i := 0; i < formatStateMigrationDiffMaxChanges+7; i++ {
+		bigObjs[fmt.Sprintf("obj%03d", i)] = `{"id":"x"}`
+	}
+	diff = formatStateMigrationDiff(buildState(bigObjs), states.NewState())
+	if !strings.Contains(diff, "... and 7 more change(s) not shown.") {
+		t.Errorf("missing truncation note:\n%s", diff)
+	}
+	if got := strings.Count(diff, "+[reset]"); got != formatStateMigrationDiffMaxChanges {
+		t.Errorf("wrong number of rendered changes %d; want %d", got, formatStateMigrationDiffMaxChanges)
+	}
+}
+
+// limitedBackend is a backend.Backend reporting a workspace count limit. The
+// embedded interface is left nil; only WorkspaceLimit may be called.
+type limitedBackend struct {
+	backend.Backend
+	limit int
+}
+
+func (b *limitedBackend) WorkspaceLimit() int {
+	return b.limit
+}
+
+func TestBackendMigrate_checkWorkspaceLimit(t *testing.T) {
+	sourceWorkspaces := []string{"default", "dev", "prod", "stage"}
+	destinationWorkspaces := []string{"default", "ops"}
+
+	m := testMetaBackend(t, nil)
+	opts := &backendMigrateOpts{
+		SourceType:      "consul",
+		DestinationType: "remote",
+		Destination:     &limitedBackend{limit: 4},
+	}
+
+	// Three of the source workspaces are new, so the destination would end up
+	// with five, exceeding the backend-reported limit of four.
+	err := m.backendMigrateCheckWorkspaceLimit(opts, sourceWorkspaces, destinationWorkspaces)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "projected total of 5") {
+		t.Fatalf("error does not report the projected count: %s", err)
+	}
+	if !strings.Contains(err.Error(), `the "remote" backend`) {
+		t.Fatalf("error does not attribute the limit: %s", err)
+	}
+
+	// An explicit -migrate-workspace-limit takes precedence over the
+	// backend-reported limit.
+	m.migrateWorkspaceLimit = 5
+	if err := m.backendMigrateCheckWorkspaceLimit(opts, sourceWorkspaces, destinationWorkspaces); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	m.migrateWorkspaceLimit = 2
+	err = m.backendMigrateCheckWorkspaceLimit(opts, sourceWorkspaces, destinationWorkspaces)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "-migrate-workspace-limit") {
+		t.Fatalf("error does not attribute the limit: %s", err)
+	}
+
+	// A destination with no limit at all never aborts.
+	m.migrateWorkspaceLimit = 0
+	opts.Destination = &limitedBackend{limit: 0}
+	if err := m.backendMigrateCheckWorkspaceLimit(opts, sourceWorkspaces, destinationWorkspaces); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+// versionedBackend reports a fixed Terraform version for each workspace, for
+// exercising the destination version check. The embedded interface is left
+// nil.
+type versionedBackend struct {
+	backend.Backend
+	versions map[string]string
+}
+
+func (b *versionedBackend) WorkspaceTerraformVersion(workspace string) (string, error) {
+	return b.versions[workspace], nil
+}
+
+func TestBackendMigrate_checkDestinationVersions(t *testing.T) {
+	m := testMetaBackend(t, nil)
+	ui := cli.NewMockUi()
+	m.Ui = ui
+	opts := &backendMigrateOpts{
+		SourceType:      "consul",
+		DestinationType: "cloud",
+		Destination: &versionedBackend{versions: map[string]string{
+			"prod": "99.0.0",
+		}},
+	}
+
+	// A destination workspace pinned to a newer Terraform than this CLI
+	// warns by default without aborting. Workspaces with no pinned version
+	// are skipped.
+	err := m.backendMigrateCheckDestinationVersions(opts, []string{"dev", "prod"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	output := ui.OutputWriter.String()
+	if !strings.Contains(output, `"prod"`) || !strings.Contains(output, "99.0.0") {
+		t.Fatalf("warning does not name the workspace and its version: %s", output)
+	}
+	if !strings.Contains(output, tfversion.String()) {
+		t.Fatalf("warning does not name the local version: %s", output)
+	}
+
+	// Under -migrate-strict-version the same pairing aborts instead.
+	m.migrateStrictVersion = true
+	err = m.backendMigrateCheckDestinationVersions(opts, []string{"prod"})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), `"prod"`) ||
+		!strings.Contains(err.Error(), "99.0.0") ||
+		!strings.Contains(err.Error(), tfversion.String()) {
+		t.Fatalf("error does not name the workspace and both versions: %s", err)
+	}
+
+	// A destination pinned at or below the local version is fine even in
+	// strict mode, as is a destination that can't report versions at all.
+	opts.Destination = &versionedBackend{versions: map[string]string{
+		"prod": tfversion.String(),
+	}}
+	if err := m.backendMigrateCheckDestinationVersions(opts, []string{"prod"}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	opts.Destination = &limitedBackend{}
+	if err := m.backendMigrateCheckDestinationVersions(opts, []string{"prod"}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+// renameableBackend is a backend.Backend storing filesystem states in a
+// directory, for exercising in-place workspace renames and multi-workspace
+// migrations. The embedded interface is left nil; only StateMgr, Workspaces
+// and DeleteWorkspace may be called.
+type renameableBackend struct {
+	backend.Backend
+	dir     string
+	deleted []string
+}
+
+func (b *renameableBackend) StateMgr(workspace string) (statemgr.Full, error) {
+	return statemgr.NewFilesystem(filepath.Join(b.dir, workspace+".tfstate")), nil
+}
+
+func (b *renameableBackend) Workspaces() ([]string, error) {
+	entries, err := ioutil.ReadDir(b.dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tfstate") {
+			names = append(names, strings.TrimSuffix(entry.Name(), ".tfstate"))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (b *renameableBackend) DeleteWorkspace(name string, force bool) error {
+	b.deleted = append(b.deleted, name)
+	return os.Remove(filepath.Join(b.dir, name+".tfstate"))
+}
+
+func TestBackendMigrate_preview(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+	emptyStateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"66666666-7777-8888-9999-000000000000","outputs":{},"resources":[]}`
+
+	source := &renameableBackend{dir: t.TempDir()}
+	for _, name := range []string{"default", "dev", "stage"} {
+		if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(stateJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	// The destination has state for "default", an empty state for "dev", and
+	// no "stage" at all: only "default" would actually be overwritten.
+	destination := &renameableBackend{dir: t.TempDir()}
+	if err := ioutil.WriteFile(filepath.Join(destination.dir, "default.tfstate"), []byte(stateJSON), 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(destination.dir, "dev.tfstate"), []byte(emptyStateJSON), 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	preview, err := backendMigratePreview(source, destination)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if want := []string{"default"}; !reflect.DeepEqual(preview.Overwrite, want) {
+		t.Errorf("wrong overwrite set %#v; want %#v", preview.Overwrite, want)
+	}
+	if want := []string{"dev", "stage"}; !reflect.DeepEqual(preview.Create, want) {
+		t.Errorf("wrong create set %#v; want %#v", preview.Create, want)
+	}
+}
+
+func TestBackendMigrate_renameWorkspace(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	newBackend := func(t *testing.T) *renameableBackend {
+		b := &renameableBackend{dir: t.TempDir()}
+		if err := ioutil.WriteFile(filepath.Join(b.dir, "alpha.tfstate"), []byte(stateJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		return b
+	}
+
+	t.Run("rename and delete old", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		b := newBackend(t)
+		if err := m.backendRenameWorkspace(b, "local", "alpha", "beta", true); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		destination := statemgr.NewFilesystem(filepath.Join(b.dir, "beta.tfstate"))
+		if err := destination.RefreshState(); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if destination.State().Empty() {
+			t.Fatal("renamed workspace has no state")
+		}
+		if len(b.deleted) != 1 || b.deleted[0] != "alpha" {
+			t.Fatalf("wrong deleted workspaces %#v; want [alpha]", b.deleted)
+		}
+		if _, err := os.Stat(filepath.Join(b.dir, "alpha.tfstate")); !os.IsNotExist(err) {
+			t.Fatal("old workspace state still exists")
+		}
+	})
+
+	t.Run("keep old when not deleting", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		b := newBackend(t)
+		if err := m.backendRenameWorkspace(b, "local", "alpha", "beta", false); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if len(b.deleted) != 0 {
+			t.Fatalf("unexpected deletions: %#v", b.deleted)
+		}
+	})
+
+	t.Run("refuses to rename onto existing state", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		b := newBackend(t)
+		if err := ioutil.WriteFile(filepath.Join(b.dir, "beta.tfstate"), []byte(stateJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		err := m.backendRenameWorkspace(b, "local", "alpha", "beta", true)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "already holds state") {
+			t.Fatalf("wrong error: %s", err)
+		}
+	})
+
+	t.Run("refuses to rename to itself", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		b := newBackend(t)
+		if err := m.backendRenameWorkspace(b, "local", "alpha", "alpha", false); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestBackendMigrate_cancelledContext(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	source := &renameableBackend{dir: t.TempDir()}
+	if err := ioutil.WriteFile(filepath.Join(source.dir, "default.tfstate"), []byte(stateJSON), 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	destination := &renameableBackend{dir: t.TempDir()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	m := testMetaBackend(t, nil)
+	err := m.backendMigrateState_s_s(ctx, &backendMigrateOpts{
+		SourceType:           "consul",
+		DestinationType:      "local",
+		Source:               source,
+		Destination:          destination,
+		sourceWorkspace:      backend.DefaultStateName,
+		destinationWorkspace: backend.DefaultStateName,
+		force:                true,
+	})
+	if err != context.Canceled {
+		t.Fatalf("wrong error %v; want %v", err, context.Canceled)
+	}
+
+	// Nothing may have been copied after the cancellation.
+	if _, err := os.Stat(filepath.Join(destination.dir, "default.tfstate")); !os.IsNotExist(err) {
+		t.Fatal("destination was written after the context was cancelled")
+	}
+}
+
+// lockHoldingBackend wraps renameableBackend so its state managers report a
+// held lock until released, standing in for a concurrent run against a busy
+// remote backend. The filesystem backend's fcntl locks never conflict within
+// a single process, so lock contention tests need this fake instead.
+type lockHoldingBackend struct {
+	*renameableBackend
+	mu   sync.Mutex
+	held bool
+}
+
+func (b *lockHoldingBackend) hold() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.held = true
+}
+
+func (b *lockHoldingBackend) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.held = false
+}
+
+func (b *lockHoldingBackend) StateMgr(workspace string) (statemgr.Full, error) {
+	inner, err := b.renameableBackend.StateMgr(workspace)
+	if err != nil {
+		return nil, err
+	}
+	return &lockHoldingStateManager{Full: inner, backend: b}, nil
+}
+
+type lockHoldingStateManager struct {
+	statemgr.Full
+	backend *lockHoldingBackend
+}
+
+func (s *lockHoldingStateManager) Lock(info *statemgr.LockInfo) (string, error) {
+	s.backend.mu.Lock()
+	defer s.backend.mu.Unlock()
+	if s.backend.held {
+		return "", &statemgr.LockError{
+			Info: &statemgr.LockInfo{ID: "held", Who: "someone-else", Operation: "apply"},
+			Err:  errors.New("state is locked"),
+		}
+	}
+	return s.Full.Lock(info)
+}
+
+func TestBackendMigrate_perSideLockTimeout(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	newSource := func(t *testing.T) *renameableBackend {
+		source := &renameableBackend{dir: t.TempDir()}
+		if err := ioutil.WriteFile(filepath.Join(source.dir, "default.tfstate"), []byte(stateJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		return source
+	}
+
+	t.Run("destination override waits out a held lock", func(t *testing.T) {
+		source := newSource(t)
+		destination := &lockHoldingBackend{renameableBackend: &renameableBackend{dir: t.TempDir()}}
+
+		destination.hold()
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			destination.release()
+		}()
+
+		m := testMetaBackend(t, nil)
+		m.forceInitCopy = true
+		m.stateLock = true
+		m.migrateDestLockTimeout = 10 * time.Second
+		if err := m.backendMigrateState(&backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     destination,
+		}); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	})
+
+	t.Run("source override waits out a held lock", func(t *testing.T) {
+		source := &lockHoldingBackend{renameableBackend: newSource(t)}
+		destination := &renameableBackend{dir: t.TempDir()}
+
+		source.hold()
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			source.release()
+		}()
+
+		m := testMetaBackend(t, nil)
+		m.forceInitCopy = true
+		m.stateLock = true
+		m.migrateSourceLockTimeout = 10 * time.Second
+		if err := m.backendMigrateState(&backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     destination,
+		}); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	})
+
+	t.Run("a short destination override still fails", func(t *testing.T) {
+		source := newSource(t)
+		destination := &lockHoldingBackend{renameableBackend: &renameableBackend{dir: t.TempDir()}}
+
+		destination.hold()
+		defer destination.release()
+
+		m := testMetaBackend(t, nil)
+		m.forceInitCopy = true
+		m.stateLock = true
+		m.migrateDestLockTimeout = 10 * time.Millisecond
+		err := m.backendMigrateState(&backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     destination,
+		})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "lock") {
+			t.Fatalf("error does not mention the lock: %s", err)
+		}
+	})
+}
+
+func TestBackendMigrate_timeout(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	source := &renameableBackend{dir: t.TempDir()}
+	if err := ioutil.WriteFile(filepath.Join(source.dir, "default.tfstate"), []byte(stateJSON), 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	destination := &renameableBackend{dir: t.TempDir()}
+
+	m := testMetaBackend(t, nil)
+	m.forceInitCopy = true
+
+	// A deadline that has effectively already passed cancels the copy, and
+	// the error names the deadline and the workspace that was in progress.
+	err := m.backendMigrateState(&backendMigrateOpts{
+		SourceType:      "consul",
+		DestinationType: "local",
+		Source:          source,
+		Destination:     destination,
+		Timeout:         time.Nanosecond,
+	})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "-migrate-timeout") {
+		t.Fatalf("error does not mention the timeout: %s", err)
+	}
+	if !strings.Contains(err.Error(), `"default"`) {
+		t.Fatalf("error does not name the workspace in progress: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(destination.dir, "default.tfstate")); !os.IsNotExist(err) {
+		t.Fatal("destination was written after the deadline")
+	}
+
+	// With a generous deadline the same migration goes through.
+	if err := m.backendMigrateState(&backendMigrateOpts{
+		SourceType:      "consul",
+		DestinationType: "local",
+		Source:          source,
+		Destination:     destination,
+		Timeout:         time.Minute,
+	}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(destination.dir, "default.tfstate")); err != nil {
+		t.Fatalf("destination was not written: %s", err)
+	}
+}
+
+func TestBackendMigrate_cloudProject(t *testing.T) {
+	t.Run("non-cloud destination", func(t *testing.T) {
+		stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+		source := &renameableBackend{dir: t.TempDir()}
+		if err := ioutil.WriteFile(filepath.Join(source.dir, "default.tfstate"), []byte(stateJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		destination := &renameableBackend{dir: t.TempDir()}
+
+		m := testMetaBackend(t, nil)
+		m.forceInitCopy = true
+
+		err := m.backendMigrateState(&backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     destination,
+			CloudProject:    "networking",
+		})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "-migrate-cloud-project") || !strings.Contains(err.Error(), `"local"`) {
+			t.Fatalf("error does not explain the destination requirement: %s", err)
+		}
+		if _, err := os.Stat(filepath.Join(destination.dir, "default.tfstate")); !os.IsNotExist(err) {
+			t.Fatal("destination was written despite the failed check")
+		}
+	})
+
+	t.Run("assigns project", func(t *testing.T) {
+		destination := &cloud.Cloud{}
+		opts := &backendMigrateOpts{CloudProject: "networking"}
+		if err := applyMigrateCloudProject(opts, destination); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if got, want := destination.WorkspaceMapping.Project, "networking"; got != want {
+			t.Fatalf("wrong project %q; want %q", got, want)
+		}
+	})
+
+	t.Run("matching configured project", func(t *testing.T) {
+		destination := &cloud.Cloud{}
+		destination.WorkspaceMapping.Project = "networking"
+		opts := &backendMigrateOpts{CloudProject: "networking"}
+		if err := applyMigrateCloudProject(opts, destination); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	})
+
+	t.Run("conflicting configured project", func(t *testing.T) {
+		destination := &cloud.Cloud{}
+		destination.WorkspaceMapping.Project = "platform"
+		opts := &backendMigrateOpts{CloudProject: "networking"}
+		err := applyMigrateCloudProject(opts, destination)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), `"networking"`) || !strings.Contains(err.Error(), `"platform"`) {
+			t.Fatalf("error does not name both projects: %s", err)
+		}
+		if got, want := destination.WorkspaceMapping.Project, "platform"; got != want {
+			t.Fatalf("configured project was overridden to %q", got)
+		}
+	})
+}
+
+// workspacelessBackend is a backend that refuses to enumerate workspaces, as
+// single-workspace backends do.
+type workspacelessBackend struct {
+	backend.Backend
+}
+
+func (b *workspacelessBackend) Workspaces() ([]string, error) {
+	return nil, backend.ErrWorkspacesNotSupported
+}
+
+func TestBackendMigrate_copyAndDeleteSource(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	t.Run("deletes the source after a successful copy", func(t *testing.T) {
+		source := &renameableBackend{dir: t.TempDir()}
+		if err := ioutil.WriteFile(filepath.Join(source.dir, "dev.tfstate"), []byte(stateJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		destination := &renameableBackend{dir: t.TempDir()}
+
+		m := testMetaBackend(t, nil)
+		ui := cli.NewMockUi()
+		m.Ui = ui
+		defer testInputMap(t, map[string]string{
+			"backend-migrate-copy-to-empty": "copy-and-delete",
+		})()
+
+		err := m.backendMigrateState_s_s(context.Background(), &backendMigrateOpts{
+			SourceType:           "consul",
+			DestinationType:      "local",
+			Source:               source,
+			Destination:          destination,
+			sourceWorkspace:      "dev",
+			destinationWorkspace: "dev",
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(destination.dir, "dev.tfstate")); err != nil {
+			t.Fatalf("destination was not written: %s", err)
+		}
+		if _, err := os.Stat(filepath.Join(source.dir, "dev.tfstate")); !os.IsNotExist(err) {
+			t.Fatal("source workspace was not deleted")
+		}
+		if output := ui.OutputWriter.String(); !strings.Contains(output, `Deleted workspace "dev"`) {
+			t.Fatalf("missing deletion report: %s", output)
+		}
+	})
+
+	t.Run("not offered for the default workspace", func(t *testing.T) {
+		source := &renameableBackend{dir: t.TempDir()}
+		opts := &backendMigrateOpts{
+			Source:          source,
+			sourceWorkspace: backend.DefaultStateName,
+		}
+		if backendMigrateCanDeleteSource(opts) {
+			t.Fatal("deletion was offered for the default workspace")
+		}
+
+		opts.sourceWorkspace = "dev"
+		if !backendMigrateCanDeleteSource(opts) {
+			t.Fatal("deletion was not offered for a non-default workspace")
+		}
+
+		// A source that can't even enumerate workspaces can't delete one.
+		opts.Source = &workspacelessBackend{}
+		if backendMigrateCanDeleteSource(opts) {
+			t.Fatal("deletion was offered for a single-workspace source")
+		}
+	})
+}
+
+// plainStateManager hides the optional Migrator and PersistentMeta
+// extensions of the wrapped state manager, as managers without metadata
+// support present themselves.
+type plainStateManager struct {
+	inner statemgr.Full
+}
+
+func (p *plainStateManager) State() *states.State             { return p.inner.State() }
+func (p *plainStateManager) WriteState(s *states.State) error { return p.inner.WriteState(s) }
+func (p *plainStateManager) RefreshState() error              { return p.inner.RefreshState() }
+func (p *plainStateManager) PersistState(schemas *schemarepo.Schemas) error {
+	return p.inner.PersistState(schemas)
+}
+func (p *plainStateManager) Lock(info *statemgr.LockInfo) (string, error) { return p.inner.Lock(info) }
+func (p *plainStateManager) Unlock(id string) error                       { return p.inner.Unlock(id) }
+func (p *plainStateManager) GetRootOutputValues(ctx context.Context) (map[string]*states.OutputValue, error) {
+	return p.inner.GetRootOutputValues(ctx)
+}
+
+func TestBackendMigrate_preserveSerial(t *testing.T) {
+	sourceJSON := `{"version":4,"terraform_version":"1.0.0","serial":7,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	newSourceState := func(t *testing.T) statemgr.Full {
+		path := filepath.Join(t.TempDir(), "source.tfstate")
+		if err := ioutil.WriteFile(path, []byte(sourceJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		mgr := statemgr.NewFilesystem(path)
+		if err := mgr.RefreshState(); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		return mgr
+	}
+
+	t.Run("rewrites a bumped serial", func(t *testing.T) {
+		sourceState := newSourceState(t)
+
+		destinationPath := filepath.Join(t.TempDir(), "destination.tfstate")
+		destinationJSON := `{"version":4,"terraform_version":"1.0.0","serial":3,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+		if err := ioutil.WriteFile(destinationPath, []byte(destinationJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		destinationState := statemgr.NewFilesystem(destinationPath)
+		if err := destinationState.RefreshState(); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+		opts := &backendMigrateOpts{
+			SourceType:           "consul",
+			DestinationType:      "local",
+			destinationWorkspace: backend.DefaultStateName,
+		}
+		if err := m.backendMigratePreserveSerial(opts, sourceState, destinationState); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if got := destinationState.StateSnapshotMeta().Serial; got != 7 {
+			t.Fatalf("wrong destination serial %d; want 7", got)
+		}
+	})
+
+	t.Run("warns when the destination has no metadata support", func(t *testing.T) {
+		sourceState := newSourceState(t)
+		destinationState := &plainStateManager{inner: statemgr.NewFilesystem(filepath.Join(t.TempDir(), "destination.tfstate"))}
+
+		m := testMetaBackend(t, nil)
+		ui := cli.NewMockUi()
+		m.Ui = ui
+		opts := &backendMigrateOpts{
+			SourceType:           "consul",
+			DestinationType:      "acme",
+			destinationWorkspace: backend.DefaultStateName,
+		}
+		if err := m.backendMigratePreserveSerial(opts, sourceState, destinationState); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		output := ui.OutputWriter.String()
+		if !strings.Contains(output, "does not support setting the state serial") {
+			t.Fatalf("missing warning: %s", output)
+		}
+		if !strings.Contains(output, "serial 7") {
+			t.Fatalf("warning does not name the source serial: %s", output)
+		}
+	})
+
+	t.Run("end to end", func(t *testing.T) {
+		source := &renameableBackend{dir: t.TempDir()}
+		if err := ioutil.WriteFile(filepath.Join(source.dir, "default.tfstate"), []byte(sourceJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		destination := &renameableBackend{dir: t.TempDir()}
+
+		m := testMetaBackend(t, nil)
+		m.forceInitCopy = true
+		if err := m.backendMigrateState(&backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     destination,
+			PreserveSerial:  true,
+		}); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(destination.dir, "default.tfstate"))
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		var written struct {
+			Serial uint64 `json:"serial"`
+		}
+		if err := json.Unmarshal(data, &written); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if written.Serial != 7 {
+			t.Fatalf("wrong destination serial %d; want 7", written.Serial)
+		}
+	})
+}
+
+func TestBackendMigrate_fromStateFile(t *testing.T) {
+	sourceJSON := `{"version":4,"terraform_version":"1.0.0","serial":2,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	t.Run("imports a loose state file", func(t *testing.T) {
+		sourcePath := filepath.Join(t.TempDir(), "orphaned.tfstate")
+		if err := ioutil.WriteFile(sourcePath, []byte(sourceJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		destination := &renameableBackend{dir: t.TempDir()}
+
+		m := testMetaBackend(t, nil)
+		m.forceInitCopy = true
+		m.migrateFromStatePath = sourcePath
+		if err := m.backendMigrateState(&backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Destination:     destination,
+		}); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(destination.dir, "default.tfstate"))
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !strings.Contains(string(data), "11111111-2222-3333-4444-555555555555") {
+			t.Fatalf("destination state does not carry the source lineage: %s", data)
+		}
+	})
+
+	t.Run("errors when the file does not exist", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		m.forceInitCopy = true
+		m.migrateFromStatePath = filepath.Join(t.TempDir(), "nope.tfstate")
+		err := m.backendMigrateState(&backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Destination:     &renameableBackend{dir: t.TempDir()},
+		})
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if !strings.Contains(err.Error(), "could not be read") {
+			t.Fatalf("wrong error: %s", err)
+		}
+	})
+
+	t.Run("adapter only serves the default workspace", func(t *testing.T) {
+		b := newStateFileBackend(filepath.Join(t.TempDir(), "orphaned.tfstate"))
+		if _, err := b.Workspaces(); err != backend.ErrWorkspacesNotSupported {
+			t.Fatalf("wrong Workspaces error: %v", err)
+		}
+		if _, err := b.StateMgr("other"); err != backend.ErrWorkspacesNotSupported {
+			t.Fatalf("wrong StateMgr error: %v", err)
+		}
+		if err := b.DeleteWorkspace(backend.DefaultStateName, false); err != backend.ErrWorkspacesNotSupported {
+			t.Fatalf("wrong DeleteWorkspace error: %v", err)
+		}
+		if _, err := b.StateMgr(backend.DefaultStateName); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	})
+}
+
+func TestBackendMigrate_destinationRoutes(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	t.Run("fans workspaces out by glob", func(t *testing.T) {
+		source := &renameableBackend{dir: t.TempDir()}
+		for _, name := range []string{"app-1", "app-2", "default", "infra-core"} {
+			if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(stateJSON), 0600); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}
+		appDestination := &renameableBackend{dir: t.TempDir()}
+		infraDestination := &renameableBackend{dir: t.TempDir()}
+		fallback := &renameableBackend{dir: t.TempDir()}
+
+		m := testMetaBackend(t, nil)
+		ui := cli.NewMockUi()
+		m.Ui = ui
+		m.forceInitCopy = true
+
+		if err := m.backendMigrateState(&backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     fallback,
+			DestinationRoutes: []MigrationDestinationRoute{
+				{Pattern: "app-*", DestinationType: "s3", Destination: appDestination},
+				{Pattern: "infra-*", DestinationType: "gcs", Destination: infraDestination},
+			},
+		}); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		wantPlacement := map[*renameableBackend][]string{
+			appDestination:   {"app-1", "app-2"},
+			infraDestination: {"infra-core"},
+			fallback:         {"default"},
+		}
+		for destination, want := range wantPlacement {
+			names, err := destination.Workspaces()
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, want) {
+				t.Fatalf("wrong workspaces %#v; want %#v", names, want)
+			}
+		}
+
+		output := ui.OutputWriter.String()
+		if !strings.Contains(output, "Workspaces migrated per destination:") {
+			t.Fatalf("missing per-destination report: %s", output)
+		}
+		for _, line := range []string{"s3: 2 workspace(s)", "gcs: 1 workspace(s)", "local: 1 workspace(s)"} {
+			if !strings.Contains(output, line) {
+				t.Fatalf("missing %q in report: %s", line, output)
+			}
+		}
+	})
+
+	t.Run("invalid glob", func(t *testing.T) {
+		source := &renameableBackend{dir: t.TempDir()}
+		if err := ioutil.WriteFile(filepath.Join(source.dir, "default.tfstate"), []byte(stateJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		m := testMetaBackend(t, nil)
+		m.forceInitCopy = true
+
+		err := m.backendMigrateState(&backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     &renameableBackend{dir: t.TempDir()},
+			DestinationRoutes: []MigrationDestinationRoute{
+				{Pattern: "[", DestinationType: "s3", Destination: &renameableBackend{dir: t.TempDir()}},
+			},
+		})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "not a valid glob") {
+			t.Fatalf("wrong error: %s", err)
+		}
+	})
+
+	t.Run("rejected for cloud destinations", func(t *testing.T) {
+		source := &renameableBackend{dir: t.TempDir()}
+		if err := ioutil.WriteFile(filepath.Join(source.dir, "default.tfstate"), []byte(stateJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		m := testMetaBackend(t, nil)
+		m.forceInitCopy = true
+
+		err := m.backendMigrateState(&backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "cloud",
+			Source:          source,
+			Destination:     &cloud.Cloud{},
+			DestinationRoutes: []MigrationDestinationRoute{
+				{Pattern: "*", DestinationType: "s3", Destination: &renameableBackend{dir: t.TempDir()}},
+			},
+		})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "single destination") {
+			t.Fatalf("wrong error: %s", err)
+		}
+	})
+}
+
+func TestBackendMigrate_patternTemplate(t *testing.T) {
+	t.Run("validation", func(t *testing.T) {
+		// Classic wildcard rules, checked directly with no UI involved.
+		if err := validateMigrationWorkspacePattern("app-*"); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if err := validateMigrationWorkspacePattern("app"); err == nil {
+			t.Fatal("expected error for a pattern without '*', got nil")
+		}
+		if err := validateMigrationWorkspacePattern("*-app-*"); err == nil {
+			t.Fatal("expected error for a pattern with two '*', got nil")
+		}
+
+		if err := validateMigrationWorkspacePattern("app-${lower(name)}"); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		err := validateMigrationWorkspacePattern("app-${lower(name)")
+		if err == nil {
+			t.Fatal("expected error for an unterminated template, got nil")
+		}
+		if !strings.Contains(err.Error(), "not a valid template") {
+			t.Fatalf("wrong error: %s", err)
+		}
+	})
+
+	t.Run("evaluation", func(t *testing.T) {
+		tests := map[string]struct {
+			pattern string
+			name    string
+			want    string
+		}{
+			"lower":      {"app-${lower(name)}", "PROD", "app-prod"},
+			"replace":    {`${replace(name, "/", "-")}`, "teams/alpha", "teams-alpha"},
+			"trimprefix": {`${trimprefix(name, "env-")}`, "env-dev", "dev"},
+			"composed":   {`${lower(trimsuffix(name, "-OLD"))}-v2`, "Stage-OLD", "stage-v2"},
+		}
+		for testName, test := range tests {
+			t.Run(testName, func(t *testing.T) {
+				expr, err := parseMigrationPatternTemplate(test.pattern)
+				if err != nil {
+					t.Fatalf("err: %s", err)
+				}
+				got, err := evalMigrationPatternTemplate(expr, test.name)
+				if err != nil {
+					t.Fatalf("err: %s", err)
+				}
+				if got != test.want {
+					t.Fatalf("wrong result %q; want %q", got, test.want)
+				}
+			})
+		}
+	})
+
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	newMigration := func(t *testing.T, pattern string) (*Meta, *renameableBackend, *backendMigrateOpts) {
+		source := &renameableBackend{dir: t.TempDir()}
+		for _, name := range []string{"ENV-Dev", "ENV-Prod"} {
+			if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(stateJSON), 0600); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}
+		destination := &renameableBackend{dir: t.TempDir()}
+
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+		if err := m.SetWorkspace("ENV-Dev"); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		t.Cleanup(func() { m.SetWorkspace(backend.DefaultStateName) })
+
+		return m, destination, &backendMigrateOpts{
+			SourceType:       "consul",
+			DestinationType:  "cloud",
+			Source:           source,
+			Destination:      destination,
+			WorkspacePattern: pattern,
+		}
+	}
+
+	t.Run("multi-to-multi migration", func(t *testing.T) {
+		m, destination, opts := newMigration(t, `${trimprefix(lower(name), "env-")}`)
+		if err := m.backendMigrateState_S_TFC(context.Background(), opts, []string{"ENV-Dev", "ENV-Prod"}); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		names, err := destination.Workspaces()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		sort.Strings(names)
+		if want := []string{"dev", "prod"}; !reflect.DeepEqual(names, want) {
+			t.Fatalf("wrong destination workspaces %#v; want %#v", names, want)
+		}
+	})
+
+	t.Run("evaluation failure names the workspace", func(t *testing.T) {
+		m, destination, opts := newMigration(t, "${nope(name)}")
+		err := m.backendMigrateState_S_TFC(context.Background(), opts, []string{"ENV-Dev", "ENV-Prod"})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), `"ENV-Dev"`) {
+			t.Fatalf("error does not name the source workspace: %s", err)
+		}
+
+		names, err := destination.Workspaces()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if len(names) != 0 {
+			t.Fatalf("workspaces were created despite the failed pattern: %#v", names)
+		}
+	})
+}
+
+func TestBackendMigrate_workspaceCountPrompt(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	// Enough workspaces to cross the count-confirmation threshold.
+	var workspaces []string
+	for i := 1; i <= backendMigrateWorkspaceCountPromptThreshold+2; i++ {
+		workspaces = append(workspaces, fmt.Sprintf("team%02d", i))
+	}
+
+	newSource := func(t *testing.T) *renameableBackend {
+		source := &renameableBackend{dir: t.TempDir()}
+		for _, name := range workspaces {
+			if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(stateJSON), 0600); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}
+		return source
+	}
+
+	migrate := func(t *testing.T, answer string) (*cli.MockUi, *renameableBackend, error) {
+		source := newSource(t)
+		destination := &renameableBackend{dir: t.TempDir()}
+
+		m := testMetaBackend(t, nil)
+		ui := cli.NewMockUi()
+		m.Ui = ui
+		if err := m.SetWorkspace(workspaces[0]); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		defer m.SetWorkspace(backend.DefaultStateName)
+
+		cleanup := testInputMap(t, map[string]string{
+			"backend-migrate-workspace-count": answer,
+		})
+		defer cleanup()
+
+		opts := &backendMigrateOpts{
+			SourceType:       "consul",
+			DestinationType:  "cloud",
+			Source:           source,
+			Destination:      destination,
+			WorkspacePattern: "app-*",
+		}
+		err := m.backendMigrateState_S_TFC(context.Background(), opts, workspaces)
+		return ui, destination, err
+	}
+
+	t.Run("declined", func(t *testing.T) {
+		ui, destination, err := migrate(t, "no")
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "aborted by user") {
+			t.Fatalf("wrong error: %s", err)
+		}
+
+		output := ui.OutputWriter.String()
+		if !strings.Contains(output, fmt.Sprintf("create or use %d", len(workspaces))) {
+			t.Fatalf("missing workspace count in output: %s", output)
+		}
+		// The sample elides the middle of the sorted name list.
+		if !strings.Contains(output, "app-team01, app-team02, app-team03, ...,") {
+			t.Fatalf("missing sample names in output: %s", output)
+		}
+
+		names, err := destination.Workspaces()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if len(names) != 0 {
+			t.Fatalf("workspaces were created despite the declined prompt: %#v", names)
+		}
+	})
+
+	t.Run("approved", func(t *testing.T) {
+		_, destination, err := migrate(t, "yes")
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		names, err := destination.Workspaces()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if len(names) != len(workspaces) {
+			t.Fatalf("wrong destination workspaces %#v", names)
+		}
+	})
+}
+
+func TestBackendMigrate_describeWorkspaces(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+	emptyStateJSON := `{"version":4,"terraform_version":"1.0.0","serial":0,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{},"resources":[]}`
+	conflictJSON := `{"version":4,"terraform_version":"1.0.0","serial":3,"lineage":"66666666-2222-3333-4444-555555555555","outputs":{"name":{"value":"b","type":"string"}},"resources":[]}`
+
+	source := &renameableBackend{dir: t.TempDir()}
+	for name, content := range map[string]string{
+		"default": stateJSON,
+		"prod":    stateJSON,
+		"scratch": emptyStateJSON,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(content), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+	destination := &renameableBackend{dir: t.TempDir()}
+	if err := ioutil.WriteFile(filepath.Join(destination.dir, "prod.tfstate"), []byte(conflictJSON), 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	opts := &backendMigrateOpts{
+		SourceType:      "consul",
+		DestinationType: "local",
+		Source:          source,
+		Destination:     destination,
+	}
+	lines, err := backendMigrateDescribeWorkspaces(opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	want := []string{
+		"default",
+		"prod (will overwrite existing state in the destination)",
+		"scratch (no state; will be skipped)",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Fatalf("wrong listing %#v; want %#v", lines, want)
+	}
+
+	t.Run("shown before the confirm prompt", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		ui := cli.NewMockUi()
+		m.Ui = ui
+		m.input = true
+		defer testInputMap(t, map[string]string{
+			"backend-migrate-multistate-to-multistate": "yes",
+		})()
+
+		if err := m.backendMigrateState_S_S(context.Background(), opts); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		output := ui.OutputWriter.String()
+		if !strings.Contains(output, `Workspaces in the "consul" backend:`) {
+			t.Fatalf("missing listing header: %s", output)
+		}
+		for _, line := range want {
+			if !strings.Contains(output, "  - "+line) {
+				t.Fatalf("missing listing line %q: %s", line, output)
+			}
+		}
+	})
+}
+
+func TestBackendMigrate_multiToSingleRestoresWorkspace(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	newSource := func(t *testing.T) *renameableBackend {
+		b := &renameableBackend{dir: t.TempDir()}
+		if err := ioutil.WriteFile(filepath.Join(b.dir, "dev.tfstate"), []byte(stateJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		return b
+	}
+
+	t.Run("failed migration restores the selection", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		if err := m.SetWorkspace("dev"); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		defer m.SetWorkspace(backend.DefaultStateName)
+
+		// A destination with no workspaces at all fails every StateMgr
+		// call, so the copy aborts after the workspace switch.
+		err := m.backendMigrateState_S_s(context.Background(), &backendMigrateOpts{
+			SourceType:           "consul",
+			DestinationType:      "local",
+			Source:               newSource(t),
+			Destination:          &stateMgrBackend{mgrs: map[string]statemgr.Full{}},
+			destinationWorkspace: backend.DefaultStateName,
+			force:                true,
+		})
+		if err == nil {
+			t.Fatal("expected error, got none")
+		}
+
+		workspace, err := m.Workspace()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if workspace != "dev" {
+			t.Fatalf("workspace selection is %q after a failed migration; want %q", workspace, "dev")
+		}
+	})
+
+	t.Run("successful migration keeps the default", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		if err := m.SetWorkspace("dev"); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		defer m.SetWorkspace(backend.DefaultStateName)
+
+		err := m.backendMigrateState_S_s(context.Background(), &backendMigrateOpts{
+			SourceType:           "consul",
+			DestinationType:      "local",
+			Source:               newSource(t),
+			Destination:          &renameableBackend{dir: t.TempDir()},
+			destinationWorkspace: backend.DefaultStateName,
+			force:                true,
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		workspace, err := m.Workspace()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if workspace != backend.DefaultStateName {
+			t.Fatalf("workspace selection is %q after a successful migration; want %q", workspace, backend.DefaultStateName)
+		}
+	})
+}
+
+func TestBackendMigrate_allSynced(t *testing.T) {
+	stateForSerial := func(serial int) string {
+		return fmt.Sprintf(`{"version":4,"terraform_version":"1.0.0","serial":%d,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`, serial)
+	}
+
+	newBackend := func(t *testing.T, states map[string]string) *renameableBackend {
+		b := &renameableBackend{dir: t.TempDir()}
+		for name, content := range states {
+			if err := ioutil.WriteFile(filepath.Join(b.dir, name+".tfstate"), []byte(content), 0600); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}
+		return b
+	}
+
+	m := testMetaBackend(t, nil)
+
+	t.Run("all workspaces synced", func(t *testing.T) {
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          newBackend(t, map[string]string{"default": stateForSerial(1), "dev": stateForSerial(2)}),
+			Destination:     newBackend(t, map[string]string{"default": stateForSerial(1), "dev": stateForSerial(2)}),
+		}
+		synced, err := m.backendMigrateAllSynced(opts, []string{"default", "dev"}, []string{"default", "dev"}, false, false)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !synced {
+			t.Fatal("want synced")
+		}
+	})
+
+	t.Run("destination ahead still counts as synced", func(t *testing.T) {
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          newBackend(t, map[string]string{"default": stateForSerial(1)}),
+			Destination:     newBackend(t, map[string]string{"default": stateForSerial(5)}),
+		}
+		synced, err := m.backendMigrateAllSynced(opts, []string{"default"}, []string{"default"}, false, false)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !synced {
+			t.Fatal("want synced")
+		}
+	})
+
+	t.Run("destination behind is not synced", func(t *testing.T) {
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          newBackend(t, map[string]string{"default": stateForSerial(5)}),
+			Destination:     newBackend(t, map[string]string{"default": stateForSerial(1)}),
+		}
+		synced, err := m.backendMigrateAllSynced(opts, []string{"default"}, []string{"default"}, false, false)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if synced {
+			t.Fatal("want not synced")
+		}
+	})
+
+	t.Run("missing destination workspace is not synced", func(t *testing.T) {
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          newBackend(t, map[string]string{"default": stateForSerial(1), "dev": stateForSerial(1)}),
+			Destination:     newBackend(t, map[string]string{"default": stateForSerial(1)}),
+		}
+		synced, err := m.backendMigrateAllSynced(opts, []string{"default", "dev"}, []string{"default"}, false, false)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if synced {
+			t.Fatal("want not synced")
+		}
+	})
+
+	t.Run("empty source workspaces are synced by definition", func(t *testing.T) {
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          newBackend(t, nil),
+			Destination:     newBackend(t, nil),
+		}
+		synced, err := m.backendMigrateAllSynced(opts, []string{"default"}, nil, false, false)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !synced {
+			t.Fatal("want synced")
+		}
+	})
+}
+
+func TestBackendMigrate_confirmAll(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+	otherStateJSON := `{"version":4,"terraform_version":"1.0.0","serial":3,"lineage":"99999999-2222-3333-4444-555555555555","outputs":{"name":{"value":"b","type":"string"}},"resources":[]}`
+
+	newBackend := func(t *testing.T, states map[string]string) *renameableBackend {
+		b := &renameableBackend{dir: t.TempDir()}
+		for name, content := range states {
+			if err := ioutil.WriteFile(filepath.Join(b.dir, name+".tfstate"), []byte(content), 0600); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}
+		return b
+	}
+
+	t.Run("approved", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		ui := cli.NewMockUi()
+		m.Ui = ui
+		defer testInputMap(t, map[string]string{
+			"backend-migrate-confirm-all": "yes",
+		})()
+
+		source := newBackend(t, map[string]string{"default": stateJSON, "dev": stateJSON})
+		destination := newBackend(t, map[string]string{"dev": otherStateJSON})
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     destination,
+			ConfirmAll:      true,
+		}
+		if err := m.backendMigrateState_S_S(context.Background(), opts); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		output := ui.OutputWriter.String()
+		if !strings.Contains(output, `1. Copy workspace "default" to "default"`) {
+			t.Errorf("missing first action in output:\n%s", output)
+		}
+		if !strings.Contains(output, "OVERWRITING") {
+			t.Errorf("overwrite of the dev workspace is not called out:\n%s", output)
+		}
+
+		copied := statemgr.NewFilesystem(filepath.Join(destination.dir, "default.tfstate"))
+		if err := copied.RefreshState(); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if copied.State().Empty() {
+			t.Fatal("default workspace was not copied")
+		}
+	})
+
+	t.Run("declined", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+		defer testInputMap(t, map[string]string{
+			"backend-migrate-confirm-all": "no",
+		})()
+
+		source := newBackend(t, map[string]string{"default": stateJSON})
+		destination := newBackend(t, nil)
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     destination,
+			ConfirmAll:      true,
+		}
+		err := m.backendMigrateState_S_S(context.Background(), opts)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "aborted by user") {
+			t.Fatalf("wrong error: %s", err)
+		}
+		if _, err := os.Stat(filepath.Join(destination.dir, "default.tfstate")); !os.IsNotExist(err) {
+			t.Fatal("workspace was copied despite declined approval")
+		}
+	})
+
+	t.Run("non-interactive aborts", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+		m.input = false
+
+		opts := &backendMigrateOpts{SourceType: "consul", DestinationType: "local", ConfirmAll: true}
+		_, err := m.backendMigrateConfirmAll(opts, []string{`Copy workspace "default" to "default"`})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+
+	t.Run("compute actions", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		source := newBackend(t, map[string]string{"default": stateJSON, "dev": stateJSON, "empty": `{"version":4,"serial":1,"lineage":"11111111-2222-3333-4444-555555555555","resources":[]}`})
+		destination := newBackend(t, map[string]string{"default": otherStateJSON})
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     destination,
+		}
+		actions, err := m.backendMigrateComputeActions(opts,
+			[]string{"default", "dev", "empty"},
+			map[string]string{"dev": "dev-renamed"}, true)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		want := []string{
+			`Copy workspace "default" to "default", OVERWRITING its existing state`,
+			`Copy workspace "dev" to "dev-renamed" (renamed)`,
+			`Skip workspace "empty", which has no state`,
+		}
+		if len(actions) != len(want) {
+			t.Fatalf("wrong actions %#v; want %#v", actions, want)
+		}
+		for i := range want {
+			if actions[i] != want[i] {
+				t.Errorf("wrong action %d: %q; want %q", i, actions[i], want[i])
+			}
+		}
+	})
+}
+
+func TestBackendMigrate_selectWorkspaces(t *testing.T) {
+	t.Run("filters to the requested names", func(t *testing.T) {
+		selected, err := selectMigrationWorkspaces(
+			[]string{"default", "dev", "prod", "staging"},
+			[]string{"prod", "dev"}, "consul")
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if want := []string{"dev", "prod"}; !reflect.DeepEqual(selected, want) {
+			t.Fatalf("wrong selection %#v; want %#v", selected, want)
+		}
+	})
+
+	t.Run("missing workspaces are an error", func(t *testing.T) {
+		_, err := selectMigrationWorkspaces(
+			[]string{"default", "dev"},
+			[]string{"dev", "prod", "staging"}, "consul")
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "prod, staging") {
+			t.Fatalf("error does not list the missing workspaces: %s", err)
+		}
+	})
+
+	t.Run("end to end", func(t *testing.T) {
+		stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+		source := &renameableBackend{dir: t.TempDir()}
+		for _, name := range []string{"default", "dev", "prod"} {
+			if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(stateJSON), 0600); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}
+		destination := &renameableBackend{dir: t.TempDir()}
+
+		m := testMetaBackend(t, nil)
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     destination,
+			Workspaces:      []string{"prod"},
+			force:           true,
+		}
+		if err := m.backendMigrateState_S_S(context.Background(), opts); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		names, err := destination.Workspaces()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if want := []string{"prod"}; !reflect.DeepEqual(names, want) {
+			t.Fatalf("wrong destination workspaces %#v; want %#v", names, want)
+		}
+	})
+}
+
+func TestBackendMigrate_currentOnly(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	source := &renameableBackend{dir: t.TempDir()}
+	for _, name := range []string{"default", "alpha"} {
+		if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(stateJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+	destination := &renameableBackend{dir: t.TempDir()}
+
+	m := testMetaBackend(t, nil)
+	defer testInputMap(t, map[string]string{
+		"backend-migrate-multistate-to-multistate-current": "yes",
+	})()
+
+	opts := &backendMigrateOpts{
+		SourceType:      "local",
+		DestinationType: "local",
+		Source:          source,
+		Destination:     destination,
+		CurrentOnly:     true,
+	}
+	if err := m.backendMigrateState_S_S_current(context.Background(), opts); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Only the current workspace may have been copied.
+	copied := statemgr.NewFilesystem(filepath.Join(destination.dir, "default.tfstate"))
+	if err := copied.RefreshState(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if copied.State().Empty() {
+		t.Fatal("current workspace was not copied")
+	}
+	if _, err := os.Stat(filepath.Join(destination.dir, "alpha.tfstate")); !os.IsNotExist(err) {
+		t.Fatal("non-current workspace was copied")
+	}
+}
+
+func TestBackendMigrate_runWorkspaceRenameCmd(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+	td := t.TempDir()
+	writeScript := func(name, content string) string {
+		path := filepath.Join(td, name)
+		if err := ioutil.WriteFile(path, []byte("#!/bin/sh\n"+content), 0755); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		return path
+	}
+
+	t.Run("renames from stdout", func(t *testing.T) {
+		script := writeScript("rename.sh", `echo "$1-renamed"`)
+		got, err := runWorkspaceRenameCmd(script, "dev")
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if got != "dev-renamed" {
+			t.Fatalf("wrong name %q; want %q", got, "dev-renamed")
+		}
+	})
+
+	t.Run("empty output is an error", func(t *testing.T) {
+		script := writeScript("empty.sh", `exit 0`)
+		_, err := runWorkspaceRenameCmd(script, "dev")
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "produced no output") {
+			t.Fatalf("wrong error: %s", err)
+		}
+	})
+
+	t.Run("failure is an error", func(t *testing.T) {
+		script := writeScript("fail.sh", `echo "no such workspace" >&2; exit 1`)
+		_, err := runWorkspaceRenameCmd(script, "dev")
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "no such workspace") {
+			t.Fatalf("error does not include the command's stderr: %s", err)
+		}
+	})
+}
+
+func TestBackendMigrate_resolveWorkspaceSuffix(t *testing.T) {
+	existing := map[string]bool{
+		"prod":             true,
+		"stage":            true,
+		"stage-migrated":   true,
+		"default-migrated": true,
+	}
+
+	cases := map[string]struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		"no collision": {
+			name: "dev",
+			want: "dev",
+		},
+		"collision gets the suffix": {
+			name: "prod",
+			want: "prod-migrated",
+		},
+		"suffixed name also collides": {
+			name:    "stage",
+			wantErr: true,
+		},
+		"suffixed name exists but original is free": {
+			name: "default",
+			want: "default",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := resolveWorkspaceSuffix(tc.name, "-migrated", existing)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", got)
+				}
+				if !strings.Contains(err.Error(), "stage-migrated") {
+					t.Fatalf("error does not name the colliding workspace: %s", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			if got != tc.want {
+				t.Fatalf("wrong name %q; want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBackendMigrate_pruneDeposedObjects(t *testing.T) {
+	instAddr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_instance",
+		Name: "foo",
+	}.Instance(addrs.NoKey)
+	providerAddr := addrs.AbsProviderConfig{
+		Module:   addrs.RootModule,
+		Provider: addrs.NewDefaultProvider("test"),
+	}
+
+	state := states.NewState()
+	root := state.EnsureModule(addrs.RootModuleInstance)
+	root.SetResourceInstanceCurrent(instAddr, &states.ResourceInstanceObjectSrc{
+		Status:    states.ObjectReady,
+		AttrsJSON: []byte(`{"id":"current"}`),
+	}, providerAddr)
+	root.SetResourceInstanceDeposed(instAddr, states.DeposedKey("00000001"), &states.ResourceInstanceObjectSrc{
+		Status:    states.ObjectReady,
+		AttrsJSON: []byte(`{"id":"deposed"}`),
+	}, providerAddr)
+
+	pruned := pruneDeposedObjects(state)
+	if len(pruned) != 1 {
+		t.Fatalf("wrong number of pruned objects %d; want 1\n%#v", len(pruned), pruned)
+	}
+	if want := "test_instance.foo (deposed object 00000001)"; pruned[0] != want {
+		t.Fatalf("wrong pruned description %q; want %q", pruned[0], want)
+	}
+
+	// The current object must survive, and the deposed object must be gone.
+	is := state.ResourceInstance(instAddr.Absolute(addrs.RootModuleInstance))
+	if is == nil || is.Current == nil {
+		t.Fatal("current object was removed")
+	}
+	if len(is.Deposed) != 0 {
+		t.Fatalf("deposed objects remain: %#v", is.Deposed)
+	}
+
+	// Pruning again is a no-op.
+	if pruned := pruneDeposedObjects(state); len(pruned) != 0 {
+		t.Fatalf("second prune removed objects: %#v", pruned)
+	}
+}
+
+// sizeLimitedBackend is a backend.Backend reporting a maximum serialized
+// state size. The embedded interface is left nil; only StateSizeLimit may be
+// called.
+type sizeLimitedBackend struct {
+	backend.Backend
+	limit int64
+}
+
+func (b *sizeLimitedBackend) StateSizeLimit() int64 {
+	return b.limit
+}
+
+func TestBackendMigrate_checkStateSizes(t *testing.T) {
+	td := t.TempDir()
+	writeState := func(name, content string) statemgr.Full {
+		path := filepath.Join(td, name+".tfstate")
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		return statemgr.NewFilesystem(path)
+	}
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	m := testMetaBackend(t, nil)
+	opts := &backendMigrateOpts{
+		SourceType:      "consul",
+		DestinationType: "remote",
+		Source: &stateMgrBackend{mgrs: map[string]statemgr.Full{
+			"dev":  writeState("dev", stateJSON),
+			"prod": writeState("prod", stateJSON),
+		}},
+		Destination: &sizeLimitedBackend{limit: 16},
+	}
+
+	// Both workspaces serialize to well over the backend-reported 16 bytes.
+	err := m.backendMigrateCheckStateSizes(opts, []string{"dev", "prod"}, false)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	for _, workspace := range []string{"dev (", "prod ("} {
+		if !strings.Contains(err.Error(), workspace) {
+			t.Fatalf("error does not report workspace %q: %s", workspace, err)
+		}
+	}
+
+	// An explicit -migrate-max-state-size takes precedence over the
+	// backend-reported limit.
+	m.migrateMaxStateSize = 1024 * 1024
+	if err := m.backendMigrateCheckStateSizes(opts, []string{"dev", "prod"}, false); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// No limit anywhere means no checking at all.
+	m.migrateMaxStateSize = 0
+	opts.Destination = &sizeLimitedBackend{limit: 0}
+	if err := m.backendMigrateCheckStateSizes(opts, []string{"dev", "prod"}, false); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+// workspacesErrorBackend is a backend.Backend whose Workspaces method always
+// fails, for exercising inspection error handling. The embedded interface is
+// left nil; only Workspaces may be called.
+type workspacesErrorBackend struct {
+	backend.Backend
+	err error
+}
+
+func (b *workspacesErrorBackend) Workspaces() ([]string, error) {
+	return nil, b.err
+}
+
+func TestBackendMigrate_retrieveWorkspacesErrorAttribution(t *testing.T) {
+	// When inspecting the source and destination ahead of a migration, a
+	// failure must name the backend that failed so the user knows which
+	// side to look at.
+	back := &workspacesErrorBackend{err: errors.New("network unreachable")}
+
+	_, _, err := retrieveWorkspaces(back, "consul")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), `"consul" backend`) {
+		t.Fatalf("error does not name the failing backend: %s", err)
+	}
+	if !strings.Contains(err.Error(), "network unreachable") {
+		t.Fatalf("error does not include the underlying cause: %s", err)
+	}
+}
+
+func TestBackendMigrate_retrieveWorkspacesSingleState(t *testing.T) {
+	// A backend that doesn't support named workspaces is a single-state
+	// backend, not an error; backend.ListWorkspaces normalizes the sentinel
+	// for us.
+	back := &workspacesErrorBackend{err: backend.ErrWorkspacesNotSupported}
+
+	workspaces, singleState, err := retrieveWorkspaces(back, "artifactory")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !singleState {
+		t.Fatal("backend was not reported as single-state")
+	}
+	if len(workspaces) != 0 {
+		t.Fatalf("unexpected workspaces %v for a single-state backend", workspaces)
+	}
+}
+
+func TestBackendMigrate_dryRun(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":2,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+	otherStateJSON := `{"version":4,"terraform_version":"1.0.0","serial":5,"lineage":"99999999-2222-3333-4444-555555555555","outputs":{"name":{"value":"b","type":"string"}},"resources":[]}`
+
+	newBackend := func(t *testing.T, states map[string]string) *renameableBackend {
+		b := &renameableBackend{dir: t.TempDir()}
+		for name, content := range states {
+			if err := ioutil.WriteFile(filepath.Join(b.dir, name+".tfstate"), []byte(content), 0600); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}
+		return b
+	}
+
+	t.Run("multi to multi", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		ui := cli.NewMockUi()
+		m.Ui = ui
+
+		source := newBackend(t, map[string]string{"default": stateJSON, "dev": stateJSON})
+		destination := newBackend(t, map[string]string{"dev": otherStateJSON})
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     destination,
+			DryRun:          true,
+		}
+		if err := m.backendMigrateState_S_S(context.Background(), opts); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		output := ui.OutputWriter.String()
+		if !strings.Contains(output, `would copy workspace "default" in "consul" to workspace "default" in "local"`) {
+			t.Errorf("missing default workspace report:\n%s", output)
+		}
+		if !strings.Contains(output, "Source serial: 2, lineage: 11111111-2222-3333-4444-555555555555") {
+			t.Errorf("missing source serial and lineage:\n%s", output)
+		}
+		if !strings.Contains(output, "plain copy") {
+			t.Errorf("copy into the empty default workspace is not called out:\n%s", output)
+		}
+		if !strings.Contains(output, "OVERWRITTEN") {
+			t.Errorf("overwrite of the dev workspace is not called out:\n%s", output)
+		}
+
+		// Nothing may have been written to the destination.
+		names, err := destination.Workspaces()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if want := []string{"dev"}; !reflect.DeepEqual(names, want) {
+			t.Fatalf("destination workspaces changed: %#v; want %#v", names, want)
+		}
+		content, err := ioutil.ReadFile(filepath.Join(destination.dir, "dev.tfstate"))
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if string(content) != otherStateJSON {
+			t.Fatal("dev workspace state was modified by the dry run")
+		}
+	})
+
+	t.Run("empty source workspace", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		ui := cli.NewMockUi()
+		m.Ui = ui
+
+		emptyStateJSON := `{"version":4,"terraform_version":"1.0.0","serial":0,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{},"resources":[]}`
+		source := newBackend(t, map[string]string{"default": emptyStateJSON})
+		destination := newBackend(t, nil)
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     destination,
+			DryRun:          true,
+		}
+		if err := m.backendMigrateState_S_S(context.Background(), opts); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		output := ui.OutputWriter.String()
+		if !strings.Contains(output, `would skip workspace "default" in "consul", which has no state`) {
+			t.Errorf("missing skip report for the empty workspace:\n%s", output)
+		}
+		names, err := destination.Workspaces()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if len(names) != 0 {
+			t.Fatalf("destination unexpectedly has workspaces: %#v", names)
+		}
+	})
+}
+
+func TestBackendMigrate_summary(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":2,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+	emptyStateJSON := `{"version":4,"terraform_version":"1.0.0","serial":0,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{},"resources":[]}`
+
+	newBackend := func(t *testing.T, states map[string]string) *renameableBackend {
+		b := &renameableBackend{dir: t.TempDir()}
+		for name, content := range states {
+			if err := ioutil.WriteFile(filepath.Join(b.dir, name+".tfstate"), []byte(content), 0600); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}
+		return b
+	}
+
+	t.Run("to file", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+		m.forceInitCopy = true
+
+		source := newBackend(t, map[string]string{
+			"default": stateJSON,
+			"dev":     stateJSON,
+			"empty":   emptyStateJSON,
+		})
+		destination := newBackend(t, nil)
+		summaryPath := filepath.Join(t.TempDir(), "summary.json")
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     destination,
+			SummaryPath:     summaryPath,
+		}
+		if err := m.backendMigrateState(opts); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		data, err := ioutil.ReadFile(summaryPath)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		var summary backendMigrateSummary
+		if err := json.Unmarshal(data, &summary); err != nil {
+			t.Fatalf("summary is not valid JSON: %s", err)
+		}
+		if summary.SourceType != "consul" || summary.DestinationType != "local" {
+			t.Fatalf("wrong backend types in summary: %+v", summary)
+		}
+		if len(summary.Workspaces) != 3 {
+			t.Fatalf("wrong number of workspace records: %+v", summary.Workspaces)
+		}
+		for i, want := range []string{"default", "dev", "empty"} {
+			if summary.Workspaces[i].SourceName != want {
+				t.Fatalf("records are not sorted by source name: %+v", summary.Workspaces)
+			}
+		}
+		for _, record := range summary.Workspaces[:2] {
+			if record.Skipped {
+				t.Fatalf("workspace %q unexpectedly skipped", record.SourceName)
+			}
+			if record.DestinationName != record.SourceName {
+				t.Fatalf("wrong destination name in record: %+v", record)
+			}
+			if record.BytesCopied == 0 {
+				t.Fatalf("no bytes recorded for workspace %q", record.SourceName)
+			}
+			if record.Lineage != "11111111-2222-3333-4444-555555555555" {
+				t.Fatalf("wrong lineage in record: %+v", record)
+			}
+		}
+		if !summary.Workspaces[2].Skipped {
+			t.Fatalf("empty workspace is not marked skipped: %+v", summary.Workspaces[2])
+		}
+		if summary.Workspaces[2].SkipReason != "empty" {
+			t.Fatalf("wrong skip reason in record: %+v", summary.Workspaces[2])
+		}
+	})
+
+	t.Run("to stdout", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		ui := cli.NewMockUi()
+		m.Ui = ui
+		m.forceInitCopy = true
+
+		source := newBackend(t, map[string]string{"default": stateJSON})
+		destination := newBackend(t, nil)
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     destination,
+			SummaryPath:     "-",
+		}
+		if err := m.backendMigrateState(opts); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		output := ui.OutputWriter.String()
+		if !strings.Contains(output, `"source_type": "consul"`) {
+			t.Fatalf("summary not written to stdout:\n%s", output)
+		}
+	})
+}
+
+func TestBackendMigrate_parallelism(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":2,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	t.Run("copies every workspace", func(t *testing.T) {
+		source := &renameableBackend{dir: t.TempDir()}
+		names := []string{"a", "b", "c", "d", "default", "e"}
+		for _, name := range names {
+			if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(stateJSON), 0600); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}
+		destination := &renameableBackend{dir: t.TempDir()}
+
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+		m.forceInitCopy = true
+
+		summaryPath := filepath.Join(t.TempDir(), "summary.json")
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     destination,
+			Parallelism:     4,
+			SummaryPath:     summaryPath,
+		}
+		if err := m.backendMigrateState(opts); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		copied, err := destination.Workspaces()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !reflect.DeepEqual(copied, names) {
+			t.Fatalf("wrong destination workspaces %#v; want %#v", copied, names)
+		}
+
+		// The summary must stay alphabetical even though the copies may have
+		// finished out of order.
+		data, err := ioutil.ReadFile(summaryPath)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		var summary backendMigrateSummary
+		if err := json.Unmarshal(data, &summary); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		var recorded []string
+		for _, record := range summary.Workspaces {
+			recorded = append(recorded, record.SourceName)
+		}
+		if !reflect.DeepEqual(recorded, names) {
+			t.Fatalf("summary records are not alphabetical: %#v", recorded)
+		}
+	})
+
+	t.Run("aggregates failures", func(t *testing.T) {
+		source := &renameableBackend{dir: t.TempDir()}
+		for _, name := range []string{"a", "b", "c"} {
+			if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(stateJSON), 0600); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}
+		// The destination only knows workspaces "a" and "c", so copying "b"
+		// fails while the others succeed.
+		td := t.TempDir()
+		destination := &stateMgrBackend{mgrs: map[string]statemgr.Full{
+			"a": statemgr.NewFilesystem(filepath.Join(td, "a.tfstate")),
+			"c": statemgr.NewFilesystem(filepath.Join(td, "c.tfstate")),
+		}}
+
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     destination,
+			Parallelism:     2,
+			force:           true,
+		}
+		err := m.backendMigrateState_S_S(context.Background(), opts)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), `no such workspace "b"`) {
+			t.Fatalf("error does not name the failed workspace: %s", err)
+		}
+		if !strings.Contains(err.Error(), "copied in parallel") {
+			t.Fatalf("error does not explain the parallel semantics: %s", err)
+		}
+
+		for _, name := range []string{"a", "c"} {
+			mgr := statemgr.NewFilesystem(filepath.Join(td, name+".tfstate"))
+			if err := mgr.RefreshState(); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			if mgr.State().Empty() {
+				t.Fatalf("workspace %q was not copied", name)
+			}
+		}
+	})
+}
+
+func TestBackendMigrate_continueOnError(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":2,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	source := &renameableBackend{dir: t.TempDir()}
+	for _, name := range []string{"a", "b", "c"} {
+		if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(stateJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+	// The destination only knows workspaces "a" and "c", so copying "b"
+	// fails while the others succeed.
+	td := t.TempDir()
+	destination := &stateMgrBackend{mgrs: map[string]statemgr.Full{
+		"a": statemgr.NewFilesystem(filepath.Join(td, "a.tfstate")),
+		"c": statemgr.NewFilesystem(filepath.Join(td, "c.tfstate")),
+	}}
+
+	m := testMetaBackend(t, nil)
+	ui := cli.NewMockUi()
+	m.Ui = ui
+
+	opts := &backendMigrateOpts{
+		SourceType:      "consul",
+		DestinationType: "local",
+		Source:          source,
+		Destination:     destination,
+		ContinueOnError: true,
+		force:           true,
+	}
+	err := m.backendMigrateState_S_S(context.Background(), opts)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), `no such workspace "b"`) {
+		t.Fatalf("error does not name the failed workspace: %s", err)
+	}
+
+	// The later workspace "c" must still have been copied.
+	for _, name := range []string{"a", "c"} {
+		mgr := statemgr.NewFilesystem(filepath.Join(td, name+".tfstate"))
+		if err := mgr.RefreshState(); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if mgr.State().Empty() {
+			t.Fatalf("workspace %q was not copied", name)
+		}
+	}
+
+	output := ui.OutputWriter.String()
+	if !strings.Contains(output, "Succeeded: a, c") {
+		t.Fatalf("report does not list the successful workspaces:\n%s", output)
+	}
+	if !strings.Contains(output, "Failed:    b") {
+		t.Fatalf("report does not list the failed workspace:\n%s", output)
+	}
+}
+
+// corruptingStateMgr wraps a filesystem state manager so that PersistState
+// appears to succeed but leaves different content on disk, the way a flaky
+// remote backend might accept a write and store truncated data.
+type corruptingStateMgr struct {
+	statemgr.Full
+	path    string
+	corrupt string
+}
+
+func (s *corruptingStateMgr) PersistState(schemas *terraform.Schemas) error {
+	if err := s.Full.PersistState(schemas); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.path, []byte(s.corrupt), 0600)
+}
+
+func TestBackendMigrate_verify(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":2,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+	otherStateJSON := `{"version":4,"terraform_version":"1.0.0","serial":5,"lineage":"99999999-2222-3333-4444-555555555555","outputs":{"name":{"value":"b","type":"string"}},"resources":[]}`
+
+	t.Run("intact copy passes", func(t *testing.T) {
+		source := &renameableBackend{dir: t.TempDir()}
+		if err := ioutil.WriteFile(filepath.Join(source.dir, "default.tfstate"), []byte(stateJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		destination := &renameableBackend{dir: t.TempDir()}
+
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     destination,
+			Verify:          true,
+			force:           true,
+		}
+		if err := m.backendMigrateState_S_S(context.Background(), opts); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	})
+
+	t.Run("corrupted copy is reported", func(t *testing.T) {
+		source := &renameableBackend{dir: t.TempDir()}
+		if err := ioutil.WriteFile(filepath.Join(source.dir, "default.tfstate"), []byte(stateJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		destinationPath := filepath.Join(t.TempDir(), "default.tfstate")
+		destination := &stateMgrBackend{mgrs: map[string]statemgr.Full{
+			"default": &corruptingStateMgr{
+				Full:    statemgr.NewFilesystem(destinationPath),
+				path:    destinationPath,
+				corrupt: otherStateJSON,
+			},
+		}}
+
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+
+		opts := &backendMigrateOpts{
+			SourceType:           "consul",
+			DestinationType:      "local",
+			Source:               source,
+			Destination:          destination,
+			Verify:               true,
+			force:                true,
+			sourceWorkspace:      "default",
+			destinationWorkspace: "default",
+		}
+		err := m.backendMigrateState_s_s(context.Background(), opts)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "Verification of workspace") {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !strings.Contains(err.Error(), "differs from what was written") {
+			t.Fatalf("error does not describe the divergence: %s", err)
+		}
+	})
+}
+
+func TestBackendMigrate_workspaceFilter(t *testing.T) {
+	t.Run("filters to matching names", func(t *testing.T) {
+		filtered := filterMigrationWorkspaces(
+			[]string{"default", "team-a-dev", "team-a-prod", "team-b-dev"},
+			regexp.MustCompile(`^team-a-`))
+		if want := []string{"team-a-dev", "team-a-prod"}; !reflect.DeepEqual(filtered, want) {
+			t.Fatalf("wrong selection %#v; want %#v", filtered, want)
+		}
+	})
+
+	t.Run("end to end", func(t *testing.T) {
+		stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+		source := &renameableBackend{dir: t.TempDir()}
+		for _, name := range []string{"default", "team-a-dev", "team-b-dev"} {
+			if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(stateJSON), 0600); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}
+		destination := &renameableBackend{dir: t.TempDir()}
+
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     destination,
+			WorkspaceFilter: regexp.MustCompile(`^team-a-`),
+			force:           true,
+		}
+		if err := m.backendMigrateState_S_S(context.Background(), opts); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		names, err := destination.Workspaces()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if want := []string{"team-a-dev"}; !reflect.DeepEqual(names, want) {
+			t.Fatalf("wrong destination workspaces %#v; want %#v", names, want)
+		}
+	})
+}
+
+func TestBackendMigrate_parseWorkspaceMapFile(t *testing.T) {
+	writeMap := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "map.json")
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		return path
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		mapping, err := parseWorkspaceMapFile(writeMap(t, `{"default": "app-prod", "dev": "app-dev"}`))
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		want := map[string]string{"default": "app-prod", "dev": "app-dev"}
+		if !reflect.DeepEqual(mapping, want) {
+			t.Fatalf("wrong mapping %#v; want %#v", mapping, want)
+		}
+	})
+
+	t.Run("not JSON", func(t *testing.T) {
+		_, err := parseWorkspaceMapFile(writeMap(t, `default = app-prod`))
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+
+	t.Run("duplicate destination", func(t *testing.T) {
+		_, err := parseWorkspaceMapFile(writeMap(t, `{"default": "app", "dev": "app"}`))
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), `both mapped to "app"`) {
+			t.Fatalf("error does not name the colliding destination: %s", err)
+		}
+	})
+
+	t.Run("empty destination", func(t *testing.T) {
+		_, err := parseWorkspaceMapFile(writeMap(t, `{"default": ""}`))
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "must not be empty") {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := parseWorkspaceMapFile(filepath.Join(t.TempDir(), "nope.json"))
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestBackendMigrate_workspaceMap(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	source := &renameableBackend{dir: t.TempDir()}
+	for _, name := range []string{"default", "dev"} {
+		if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(stateJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+	destination := &renameableBackend{dir: t.TempDir()}
+
+	m := testMetaBackend(t, nil)
+	m.Ui = cli.NewMockUi()
+
+	// Every workspace has an explicit mapping, so no pattern prompt is
+	// needed at all.
+	opts := &backendMigrateOpts{
+		SourceType:      "consul",
+		DestinationType: "cloud",
+		Source:          source,
+		Destination:     destination,
+		workspaceMap: map[string]string{
+			"default": "app-prod",
+			"dev":     "app-dev",
+		},
+	}
+	// Migrating the current workspace reselects it under its new name, so
+	// put the selection back for the benefit of later tests.
+	defer func() {
+		if err := m.SetWorkspace(backend.DefaultStateName); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}()
+
+	if err := m.backendMigrateState_S_TFC(context.Background(), opts, []string{"default", "dev"}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	names, err := destination.Workspaces()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if want := []string{"app-dev", "app-prod"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("wrong destination workspaces %#v; want %#v", names, want)
+	}
+}
+
+// variableBackend is a multi-workspace backend that also stores per-workspace
+// variables, standing in for the cloud and remote backends in variable
+// migration tests.
+type variableBackend struct {
+	*renameableBackend
+	variables map[string][]backend.WorkspaceVariable
+	written   map[string][]backend.WorkspaceVariable
+}
+
+func (b *variableBackend) ListWorkspaceVariables(name string) ([]backend.WorkspaceVariable, error) {
+	return b.variables[name], nil
+}
+
+func (b *variableBackend) WriteWorkspaceVariables(name string, variables []backend.WorkspaceVariable) error {
+	if b.written == nil {
+		b.written = map[string][]backend.WorkspaceVariable{}
+	}
+	b.written[name] = append(b.written[name], variables...)
+	return nil
+}
+
+func TestBackendMigrate_variables(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	newSource := func(t *testing.T) *variableBackend {
+		source := &variableBackend{renameableBackend: &renameableBackend{dir: t.TempDir()}}
+		if err := ioutil.WriteFile(filepath.Join(source.dir, "default.tfstate"), []byte(stateJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		source.variables = map[string][]backend.WorkspaceVariable{
+			"default": {
+				{Key: "region", Value: "us-east-1", Category: "terraform"},
+				{Key: "API_TOKEN", Sensitive: true, Category: "env"},
+			},
+		}
+		return source
+	}
+
+	t.Run("copies variables with sensitive placeholders", func(t *testing.T) {
+		source := newSource(t)
+		destination := &variableBackend{renameableBackend: &renameableBackend{dir: t.TempDir()}}
+
+		m := testMetaBackend(t, nil)
+		ui := cli.NewMockUi()
+		m.Ui = ui
+		defer func() {
+			if err := m.SetWorkspace(backend.DefaultStateName); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}()
+
+		opts := &backendMigrateOpts{
+			SourceType:       "cloud",
+			DestinationType:  "cloud",
+			Source:           source,
+			Destination:      destination,
+			MigrateVariables: true,
+			workspaceMap: map[string]string{
+				"default": "app-default",
+			},
+		}
+		if err := m.backendMigrateState_S_TFC(context.Background(), opts, []string{"default"}); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		written := destination.written["app-default"]
+		if len(written) != 2 {
+			t.Fatalf("wrong variables written: %#v", written)
+		}
+		if written[0].Key != "region" || written[0].Value != "us-east-1" {
+			t.Fatalf("plain variable was not copied intact: %#v", written[0])
+		}
+		if written[1].Key != "API_TOKEN" || written[1].Value != "" || !written[1].Sensitive {
+			t.Fatalf("sensitive variable was not written as an empty placeholder: %#v", written[1])
+		}
+		if output := ui.OutputWriter.String(); !strings.Contains(output, "app-default: API_TOKEN") {
+			t.Fatalf("missing sensitive placeholder note: %s", output)
+		}
+	})
+
+	t.Run("unsupported destination", func(t *testing.T) {
+		source := newSource(t)
+		destination := &renameableBackend{dir: t.TempDir()}
+
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+
+		opts := &backendMigrateOpts{
+			SourceType:       "cloud",
+			DestinationType:  "local",
+			Source:           source,
+			Destination:      destination,
+			MigrateVariables: true,
+			workspaceMap: map[string]string{
+				"default": "app-default",
+			},
+		}
+		err := m.backendMigrateState_S_TFC(context.Background(), opts, []string{"default"})
+		if err == nil {
+			t.Fatal("expected error, got none")
+		}
+		if !strings.Contains(err.Error(), "does not store workspace variables") {
+			t.Fatalf("wrong error: %s", err)
+		}
+
+		names, err := destination.Workspaces()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if len(names) != 0 {
+			t.Fatalf("workspaces were copied despite the unsupported pairing: %#v", names)
+		}
+	})
+}
+
+func TestMigrateDiff(t *testing.T) {
+	stateA := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+	stateB := `{"version":4,"terraform_version":"1.0.0","serial":2,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"b","type":"string"}},"resources":[]}`
+
+	source := &renameableBackend{dir: t.TempDir()}
+	destination := &renameableBackend{dir: t.TempDir()}
+	writes := []struct {
+		backend *renameableBackend
+		name    string
+		content string
+	}{
+		{source, "only-src", stateA},
+		{source, "same", stateA},
+		{source, "diverged", stateA},
+		{destination, "only-dst", stateA},
+		{destination, "same", stateA},
+		{destination, "diverged", stateB},
+	}
+	for _, write := range writes {
+		if err := ioutil.WriteFile(filepath.Join(write.backend.dir, write.name+".tfstate"), []byte(write.content), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	diff, err := MigrateDiff(source, destination)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	want := &MigrateDiffResult{
+		SourceOnly:      []string{"only-src"},
+		DestinationOnly: []string{"only-dst"},
+		Equal:           []string{"same"},
+		Divergent:       []string{"diverged"},
+	}
+	if !reflect.DeepEqual(diff, want) {
+		t.Fatalf("wrong diff\ngot:  %#v\nwant: %#v", diff, want)
+	}
+}
+
+func TestBackendMigrate_skipEmpty(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+	emptyJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{},"resources":[]}`
+
+	source := &renameableBackend{dir: t.TempDir()}
+	for name, content := range map[string]string{
+		"dev":   stateJSON,
+		"empty": emptyJSON,
+		"prod":  stateJSON,
+		"stale": emptyJSON,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(content), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+	destination := &renameableBackend{dir: t.TempDir()}
+
+	m := testMetaBackend(t, nil)
+	ui := cli.NewMockUi()
+	m.Ui = ui
+
+	opts := &backendMigrateOpts{
+		SourceType:      "consul",
+		DestinationType: "consul",
+		Source:          source,
+		Destination:     destination,
+		SkipEmpty:       true,
+		force:           true,
+		recorder:        &backendMigrateRecorder{},
+	}
+	if err := m.backendMigrateState_S_S(context.Background(), opts); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The empty workspaces are announced as one group before anything is
+	// copied, and only the non-empty ones arrive in the destination.
+	output := ui.OutputWriter.String()
+	if !strings.Contains(output, "Skipping 2 workspace(s) with empty state:") {
+		t.Fatalf("missing consolidated skip announcement: %s", output)
+	}
+	for _, name := range []string{"empty", "stale"} {
+		if !strings.Contains(output, "- "+name) {
+			t.Fatalf("skip announcement does not list %q: %s", name, output)
+		}
+	}
+	names, err := destination.Workspaces()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(names, []string{"dev", "prod"}) {
+		t.Fatalf("wrong destination workspaces: %#v", names)
+	}
+
+	// The summary still accounts for the skipped workspaces.
+	skipped := map[string]string{}
+	for _, record := range opts.recorder.records() {
+		if record.Skipped {
+			skipped[record.SourceName] = record.SkipReason
+		}
+	}
+	if !reflect.DeepEqual(skipped, map[string]string{"empty": "empty", "stale": "empty"}) {
+		t.Fatalf("wrong skipped records: %#v", skipped)
+	}
+}
+
+// failingStateBackend wraps a renameableBackend and fails StateMgr for one
+// workspace name, for exercising partial-failure recovery.
+type failingStateBackend struct {
+	*renameableBackend
+	failName string
+}
+
+func (b *failingStateBackend) StateMgr(name string) (statemgr.Full, error) {
+	if name == b.failName {
+		return nil, fmt.Errorf("transient backend failure")
+	}
+	return b.renameableBackend.StateMgr(name)
+}
+
+// hidingBackend wraps a renameableBackend but omits one workspace from its
+// Workspaces listing, for exercising the post-migration reconciliation.
+type hidingBackend struct {
+	*renameableBackend
+	hide string
+}
+
+func (b *hidingBackend) Workspaces() ([]string, error) {
+	names, err := b.renameableBackend.Workspaces()
+	if err != nil {
+		return nil, err
+	}
+	kept := names[:0]
+	for _, name := range names {
+		if name != b.hide {
+			kept = append(kept, name)
+		}
+	}
+	return kept, nil
+}
+
+func TestBackendMigrate_verifyDestinationWorkspaces(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+	emptyJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{},"resources":[]}`
+
+	source := &renameableBackend{dir: t.TempDir()}
+	for name, content := range map[string]string{
+		"dev":   stateJSON,
+		"prod":  stateJSON,
+		"empty": emptyJSON,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(content), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	t.Run("unit", func(t *testing.T) {
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "cloud",
+			Source:          source,
+		}
+		destinationNames := map[string]string{
+			"dev":   "app-dev",
+			"prod":  "app-prod",
+			"empty": "app-empty",
+		}
+
+		// The empty source workspace was never copied, so only its absence
+		// is tolerated.
+		err := backendMigrateVerifyDestinationWorkspaces(opts, destinationNames, []string{"app-dev", "app-prod"})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		err = backendMigrateVerifyDestinationWorkspaces(opts, destinationNames, []string{"app-dev"})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "app-prod (from source workspace prod)") {
+			t.Fatalf("error does not name the missing workspace: %s", err)
+		}
+	})
+
+	t.Run("multi-to-multi migration", func(t *testing.T) {
+		destination := &hidingBackend{&renameableBackend{dir: t.TempDir()}, "app-prod"}
+
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+
+		opts := &backendMigrateOpts{
+			SourceType:       "consul",
+			DestinationType:  "cloud",
+			Source:           source,
+			Destination:      destination,
+			WorkspacePattern: "app-*",
+		}
+		err := m.backendMigrateState_S_TFC(context.Background(), opts, []string{"dev", "prod"})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "app-prod (from source workspace prod)") {
+			t.Fatalf("error does not name the missing workspace: %s", err)
+		}
+	})
+}
+
+func TestBackendMigrate_checkpoint(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	source := &renameableBackend{dir: t.TempDir()}
+	for _, name := range []string{"dev", "prod"} {
+		if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(stateJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+	destination := &renameableBackend{dir: t.TempDir()}
+	checkpointPath := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	m := testMetaBackend(t, nil)
+	m.Ui = cli.NewMockUi()
+	if err := m.SetWorkspace("dev"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer m.SetWorkspace(backend.DefaultStateName)
+
+	// The first run fails while copying "prod", leaving a checkpoint that
+	// records the completed "dev" copy and the pattern in use.
+	opts := &backendMigrateOpts{
+		SourceType:       "consul",
+		DestinationType:  "cloud",
+		Source:           source,
+		Destination:      &failingStateBackend{destination, "app-prod"},
+		WorkspacePattern: "app-*",
+		CheckpointPath:   checkpointPath,
+	}
+	err := m.backendMigrateState_S_TFC(context.Background(), opts, []string{"dev", "prod"})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "transient backend failure") {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, err := ioutil.ReadFile(checkpointPath)
+	if err != nil {
+		t.Fatalf("checkpoint was not written: %s", err)
+	}
+	var checkpoint backendMigrateCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if checkpoint.Pattern != "app-*" {
+		t.Fatalf("checkpoint does not record the pattern: %#v", checkpoint)
+	}
+	if !reflect.DeepEqual(checkpoint.Completed, map[string]string{"dev": "app-dev"}) {
+		t.Fatalf("wrong completed workspaces: %#v", checkpoint.Completed)
+	}
+
+	// Changing the source state now distinguishes a resumed run from a
+	// recopy: the resume must leave the already-migrated destination alone.
+	changedJSON := strings.Replace(stateJSON, `"serial":1`, `"serial":9`, 1)
+	changedJSON = strings.Replace(changedJSON, `"value":"a"`, `"value":"changed"`, 1)
+	if err := ioutil.WriteFile(filepath.Join(source.dir, "dev.tfstate"), []byte(changedJSON), 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The second run has no pattern supplied and input disabled, so it can
+	// only succeed by reusing the checkpointed pattern; it must skip "dev"
+	// and copy only "prod".
+	m.input = false
+	opts = &backendMigrateOpts{
+		SourceType:      "consul",
+		DestinationType: "cloud",
+		Source:          source,
+		Destination:     destination,
+		CheckpointPath:  checkpointPath,
+	}
+	if err := m.backendMigrateState_S_TFC(context.Background(), opts, []string{"dev", "prod"}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	names, err := destination.Workspaces()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(names, []string{"app-dev", "app-prod"}) {
+		t.Fatalf("wrong destination workspaces: %#v", names)
+	}
+	devState, err := ioutil.ReadFile(filepath.Join(destination.dir, "app-dev.tfstate"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if strings.Contains(string(devState), "changed") {
+		t.Fatalf("already-migrated workspace was recopied: %s", devState)
+	}
+
+	// A completed migration cleans up after itself.
+	if _, err := os.Stat(checkpointPath); !os.IsNotExist(err) {
+		t.Fatalf("checkpoint file was not removed: %v", err)
+	}
+}
+
+func TestBackendMigrate_parseManifest(t *testing.T) {
+	valid := `{"workspaces":[
+		{"source":"default","destination":"app-prod"},
+		{"source":"dev","destination":"app-dev","on_conflict":"newest-serial"}
+	]}`
+
+	cases := map[string]struct {
+		content     string
+		expectedErr string
+	}{
+		"valid": {
+			content: valid,
+		},
+		"not JSON": {
+			content:     "workspaces: nope",
+			expectedErr: "invalid character",
+		},
+		"no workspaces": {
+			content:     `{"workspaces":[]}`,
+			expectedErr: "names no workspaces",
+		},
+		"empty destination": {
+			content:     `{"workspaces":[{"source":"default","destination":""}]}`,
+			expectedErr: "must not be empty",
+		},
+		"duplicate source": {
+			content:     `{"workspaces":[{"source":"dev","destination":"a"},{"source":"dev","destination":"b"}]}`,
+			expectedErr: `workspace "dev" has more than one entry`,
+		},
+		"duplicate destination": {
+			content:     `{"workspaces":[{"source":"default","destination":"a"},{"source":"dev","destination":"a"}]}`,
+			expectedErr: `workspaces "default" and "dev" are both mapped to "a"`,
+		},
+		"bad conflict policy": {
+			content:     `{"workspaces":[{"source":"dev","destination":"a","on_conflict":"ask-nicely"}]}`,
+			expectedErr: `unsupported conflict policy "ask-nicely"`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "manifest.json")
+			if err := ioutil.WriteFile(path, []byte(tc.content), 0600); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			manifest, err := parseMigrationManifest(path)
+			if tc.expectedErr != "" {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				if !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Fatalf("wrong error %q; want %q", err, tc.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			want := map[string]string{"default": "app-prod", "dev": "app-dev"}
+			if got := manifest.destinations(); !reflect.DeepEqual(got, want) {
+				t.Fatalf("wrong destinations %#v; want %#v", got, want)
+			}
+			if got := manifest.conflictPolicyFor("dev"); got != "newest-serial" {
+				t.Fatalf("wrong conflict policy %q for dev; want %q", got, "newest-serial")
+			}
+			if got := manifest.conflictPolicyFor("default"); got != "" {
+				t.Fatalf("unexpected conflict policy %q for default", got)
+			}
+
+			if err := manifest.validateAgainst([]string{"default", "dev"}, path); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			if err := manifest.validateAgainst([]string{"default"}, path); err == nil || !strings.Contains(err.Error(), "do not exist in the source backend: dev") {
+				t.Fatalf("wrong unknown-workspace error: %s", err)
+			}
+			if err := manifest.validateAgainst([]string{"default", "dev", "stage"}, path); err == nil || !strings.Contains(err.Error(), "no entry for these source workspaces: stage") {
+				t.Fatalf("wrong missing-workspace error: %s", err)
+			}
+		})
+	}
+}
+
+func TestBackendMigrate_manifest(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+	newerJSON := `{"version":4,"terraform_version":"1.0.0","serial":5,"lineage":"22222222-2222-3333-4444-555555555555","outputs":{"name":{"value":"b","type":"string"}},"resources":[]}`
+
+	source := &renameableBackend{dir: t.TempDir()}
+	for _, name := range []string{"default", "dev"} {
+		if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(stateJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+	destination := &renameableBackend{dir: t.TempDir()}
+	if err := ioutil.WriteFile(filepath.Join(destination.dir, "app-dev.tfstate"), []byte(newerJSON), 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	manifestPath := filepath.Join(t.TempDir(), "manifest.json")
+	manifestJSON := `{"workspaces":[
+		{"source":"default","destination":"app-prod"},
+		{"source":"dev","destination":"app-dev","on_conflict":"newest-serial"}
+	]}`
+	if err := ioutil.WriteFile(manifestPath, []byte(manifestJSON), 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// No testInputMap here: the manifest must carry the migration through
+	// without a single prompt.
+	m := testMetaBackend(t, nil)
+	ui := cli.NewMockUi()
+	m.Ui = ui
+
+	opts := &backendMigrateOpts{
+		SourceType:      "consul",
+		DestinationType: "local",
+		Source:          source,
+		Destination:     destination,
+		ManifestPath:    manifestPath,
+	}
+	if err := m.backendMigrateState(opts); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The default workspace was copied under its manifest name.
+	copied := statemgr.NewFilesystem(filepath.Join(destination.dir, "app-prod.tfstate"))
+	if err := copied.RefreshState(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if copied.State().Empty() {
+		t.Fatal("manifest destination workspace has no state")
+	}
+
+	// The dev workspace hit its per-workspace newest-serial policy and was
+	// skipped, leaving the newer destination state untouched.
+	kept, err := ioutil.ReadFile(filepath.Join(destination.dir, "app-dev.tfstate"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !strings.Contains(string(kept), "22222222-2222-3333-4444-555555555555") {
+		t.Fatal("conflicting destination state was overwritten despite the newest-serial policy")
+	}
+	if output := ui.OutputWriter.String(); !strings.Contains(output, `Skipped workspace "dev"`) {
+		t.Fatalf("missing conflict skip note: %s", output)
+	}
+
+	t.Run("workspace mismatch", func(t *testing.T) {
+		partialPath := filepath.Join(t.TempDir(), "manifest.json")
+		if err := ioutil.WriteFile(partialPath, []byte(`{"workspaces":[{"source":"default","destination":"app-prod"}]}`), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+		err := m.backendMigrateState(&backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     &renameableBackend{dir: t.TempDir()},
+			ManifestPath:    partialPath,
+		})
+		if err == nil {
+			t.Fatal("expected error, got none")
+		}
+		if !strings.Contains(err.Error(), "no entry for these source workspaces: dev") {
+			t.Fatalf("wrong error: %s", err)
+		}
+	})
+}
+
+func TestBackendMigrate_resultAuditLog(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":5,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	source := &renameableBackend{dir: t.TempDir()}
+	if err := ioutil.WriteFile(filepath.Join(source.dir, "default.tfstate"), []byte(stateJSON), 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	destination := &renameableBackend{dir: t.TempDir()}
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	m := testMetaBackend(t, nil)
+	m.forceInitCopy = true
+	if err := m.backendMigrateState(&backendMigrateOpts{
+		SourceType:      "consul",
+		DestinationType: "local",
+		Source:          source,
+		Destination:     destination,
+	}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	output := buf.String()
+	want := `wrote workspace "default" to the "local" backend with lineage "11111111-2222-3333-4444-555555555555" serial 5`
+	if !strings.Contains(output, want) {
+		t.Fatalf("missing %q in log output: %s", want, output)
+	}
+}
+
+func TestBackendMigrate_decisionLogger(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	opts := &backendMigrateOpts{
+		SourceType:      "consul",
+		DestinationType: "local",
+		sourceWorkspace: "dev",
+	}
+	opts.log().decision("dev", "skip", "reason", "empty-source")
+	opts.log().decision("dev", "copy")
+
+	output := buf.String()
+	if want := `decision="skip" workspace="dev" source="consul" destination="local" reason="empty-source"`; !strings.Contains(output, want) {
+		t.Fatalf("missing %q in log output: %s", want, output)
+	}
+	if want := `decision="copy" workspace="dev" source="consul" destination="local"`; !strings.Contains(output, want) {
+		t.Fatalf("missing %q in log output: %s", want, output)
+	}
+}
+
+func TestBackendMigrate_promoteWorkspaceChoice(t *testing.T) {
+	sourceWorkspaces := []string{"default", "dev", "prod"}
+
+	prompt := func(t *testing.T, answer string) (string, string, error) {
+		t.Helper()
+
+		m := testMetaBackend(t, nil)
+		ui := cli.NewMockUi()
+		m.Ui = ui
+		m.input = true
+		cleanup := testInputMap(t, map[string]string{
+			"backend-migrate-multistate-to-single-choice": answer,
+		})
+		defer cleanup()
+
+		opts := &backendMigrateOpts{
+			SourceType:           "consul",
+			DestinationType:      "cloud",
+			destinationWorkspace: "app",
+		}
+		promoted, err := m.promptMultiToSingleCloudWorkspaceChoice(opts, sourceWorkspaces, "default")
+		return promoted, ui.OutputWriter.String(), err
+	}
+
+	t.Run("picks the named workspace", func(t *testing.T) {
+		promoted, output, err := prompt(t, "prod")
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if promoted != "prod" {
+			t.Fatalf("wrong workspace %q; want %q", promoted, "prod")
+		}
+		if !strings.Contains(output, "- default (current)") || !strings.Contains(output, "- prod") {
+			t.Fatalf("missing workspace listing: %s", output)
+		}
+	})
+
+	t.Run("empty answer keeps the current workspace", func(t *testing.T) {
+		promoted, _, err := prompt(t, "")
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if promoted != "default" {
+			t.Fatalf("wrong workspace %q; want %q", promoted, "default")
+		}
+	})
+
+	t.Run("unknown workspace is an error", func(t *testing.T) {
+		_, _, err := prompt(t, "staging")
+		if err == nil {
+			t.Fatal("expected error, got none")
+		}
+		if !strings.Contains(err.Error(), `no workspace named "staging"`) {
+			t.Fatalf("wrong error: %s", err)
+		}
+	})
+
+	t.Run("force keeps the current workspace without prompting", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+
+		opts := &backendMigrateOpts{
+			SourceType:           "consul",
+			DestinationType:      "cloud",
+			destinationWorkspace: "app",
+			force:                true,
+		}
+		promoted, err := m.promptMultiToSingleCloudWorkspaceChoice(opts, sourceWorkspaces, "dev")
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if promoted != "dev" {
+			t.Fatalf("wrong workspace %q; want %q", promoted, "dev")
+		}
+	})
+}
+
+// nameValidatingBackend is a multi-workspace backend that rejects workspace
+// names containing dots, standing in for a destination with naming rules
+// such as HCP Terraform.
+type nameValidatingBackend struct {
+	*renameableBackend
+}
+
+func (b *nameValidatingBackend) ValidateWorkspaceName(name string) error {
+	if strings.Contains(name, ".") {
+		return errors.New("workspace names may not contain dots")
+	}
+	return nil
+}
+
+func TestBackendMigrate_validateDestinationNames(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	source := &renameableBackend{dir: t.TempDir()}
+	for _, name := range []string{"default", "app.dev", "app.prod"} {
+		if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(stateJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+	destination := &nameValidatingBackend{&renameableBackend{dir: t.TempDir()}}
+
+	m := testMetaBackend(t, nil)
+	m.Ui = cli.NewMockUi()
+	m.forceInitCopy = true
+
+	err := m.backendMigrateState(&backendMigrateOpts{
+		SourceType:      "consul",
+		DestinationType: "cloud",
+		Source:          source,
+		Destination:     destination,
+	})
+	if err == nil {
+		t.Fatal("expected error, got none")
+	}
+
+	// Both bad names are reported together, before anything is copied.
+	for _, want := range []string{"app.dev: workspace names may not contain dots", "app.prod: workspace names may not contain dots"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("missing %q in error: %s", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "default:") {
+		t.Fatalf("valid name was reported as invalid: %s", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(destination.dir, "default.tfstate")); !os.IsNotExist(statErr) {
+		t.Fatal("state was copied despite the naming failure")
+	}
+}
+
+func TestBackendMigrate_backupDir(t *testing.T) {
+	sourceJSON := `{"version":4,"terraform_version":"1.0.0","serial":7,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+	destinationJSON := `{"version":4,"terraform_version":"1.0.0","serial":3,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"b","type":"string"}},"resources":[]}`
+
+	source := &renameableBackend{dir: t.TempDir()}
+	for _, name := range []string{"default", "dev"} {
+		if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(sourceJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+	destination := &renameableBackend{dir: t.TempDir()}
+	if err := ioutil.WriteFile(filepath.Join(destination.dir, "default.tfstate"), []byte(destinationJSON), 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	backupDir := filepath.Join(t.TempDir(), "backups")
+
+	m := testMetaBackend(t, nil)
+	ui := cli.NewMockUi()
+	m.Ui = ui
+	m.forceInitCopy = true
+
+	opts := &backendMigrateOpts{
+		SourceType:      "consul",
+		DestinationType: "local",
+		Source:          source,
+		Destination:     destination,
+		BackupDir:       backupDir,
+	}
+	if err := m.backendMigrateState(opts); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The overwritten default workspace left its old state behind as a
+	// backup, while the overwrite itself still happened.
+	backup, err := ioutil.ReadFile(filepath.Join(backupDir, "terraform.default.backup.tfstate"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !strings.Contains(string(backup), `"value": "b"`) {
+		t.Fatalf("backup does not hold the pre-overwrite destination state: %s", backup)
+	}
+	overwritten, err := ioutil.ReadFile(filepath.Join(destination.dir, "default.tfstate"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !strings.Contains(string(overwritten), `"value": "a"`) {
+		t.Fatalf("destination was not overwritten with the source state: %s", overwritten)
+	}
+	if output := ui.OutputWriter.String(); !strings.Contains(output, `Backed up the previous state of workspace "default"`) {
+		t.Fatalf("missing backup note: %s", output)
+	}
+
+	// The dev workspace had no destination state, so it produced no backup.
+	if _, err := os.Stat(filepath.Join(backupDir, "terraform.dev.backup.tfstate")); !os.IsNotExist(err) {
+		t.Fatalf("unexpected backup for workspace with an empty destination: %s", err)
+	}
+}
+
+func TestBackendMigrate_forceCopyEmpty(t *testing.T) {
+	sourceJSON := `{"version":4,"terraform_version":"1.0.0","serial":7,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+	destinationJSON := `{"version":4,"terraform_version":"1.0.0","serial":3,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"b","type":"string"}},"resources":[]}`
+
+	t.Run("auto-confirms a copy into an empty destination", func(t *testing.T) {
+		source := &renameableBackend{dir: t.TempDir()}
+		if err := ioutil.WriteFile(filepath.Join(source.dir, "default.tfstate"), []byte(sourceJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		destination := &renameableBackend{dir: t.TempDir()}
+
+		// No input is configured, so any prompt would fail the migration.
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+		m.forceInitCopyEmpty = true
+
+		err := m.backendMigrateState(&backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     destination,
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		copied, err := ioutil.ReadFile(filepath.Join(destination.dir, "default.tfstate"))
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !strings.Contains(string(copied), `"value": "a"`) {
+			t.Fatalf("destination does not hold the source state: %s", copied)
+		}
+	})
+
+	t.Run("still requires confirmation for an overwrite", func(t *testing.T) {
+		source := &renameableBackend{dir: t.TempDir()}
+		if err := ioutil.WriteFile(filepath.Join(source.dir, "default.tfstate"), []byte(sourceJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		destination := &renameableBackend{dir: t.TempDir()}
+		if err := ioutil.WriteFile(filepath.Join(destination.dir, "default.tfstate"), []byte(destinationJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+		m.forceInitCopyEmpty = true
+
+		err := m.backendMigrateState(&backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     destination,
+		})
+		if err == nil {
+			t.Fatal("expected error, got none")
+		}
+		if !strings.Contains(err.Error(), "input is disabled") {
+			t.Fatalf("wrong error: %s", err)
+		}
+
+		// The destination still holds its own state.
+		kept, readErr := ioutil.ReadFile(filepath.Join(destination.dir, "default.tfstate"))
+		if readErr != nil {
+			t.Fatalf("err: %s", readErr)
+		}
+		if strings.Contains(string(kept), `"value": "a"`) {
+			t.Fatalf("destination state was overwritten: %s", kept)
+		}
+	})
+}
+
+func TestBackendMigrate_deleteSource(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+	emptyStateJSON := `{"version":4,"terraform_version":"1.0.0","serial":0,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{},"resources":[]}`
+
+	t.Run("deletes migrated workspaces only", func(t *testing.T) {
+		source := &renameableBackend{dir: t.TempDir()}
+		for name, content := range map[string]string{
+			"default": stateJSON,
+			"dev":     stateJSON,
+			"empty":   emptyStateJSON,
+		} {
+			if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(content), 0600); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}
+		destination := &renameableBackend{dir: t.TempDir()}
+
+		m := testMetaBackend(t, nil)
+		ui := cli.NewMockUi()
+		m.Ui = ui
+		m.forceInitCopy = true
+
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     destination,
+			DeleteSource:    true,
+		}
+		if err := m.backendMigrateState(opts); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		// Only "dev" may be deleted: "default" never is, and "empty" was
+		// skipped rather than migrated.
+		remaining, err := source.Workspaces()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if want := []string{"default", "empty"}; !reflect.DeepEqual(remaining, want) {
+			t.Fatalf("wrong source workspaces remain %#v; want %#v", remaining, want)
+		}
+		if !strings.Contains(ui.OutputWriter.String(), "Deleted 1 migrated workspace(s)") {
+			t.Fatalf("deletion was not reported:\n%s", ui.OutputWriter.String())
+		}
+	})
+
+	t.Run("refuses after a failure", func(t *testing.T) {
+		source := &renameableBackend{dir: t.TempDir()}
+		for _, name := range []string{"default", "dev"} {
+			if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(stateJSON), 0600); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}
+		// The destination doesn't know "dev", so that workspace fails.
+		td := t.TempDir()
+		destination := &stateMgrBackend{mgrs: map[string]statemgr.Full{
+			"default": statemgr.NewFilesystem(filepath.Join(td, "default.tfstate")),
+		}}
+
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+		m.forceInitCopy = true
+
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     destination,
+			DeleteSource:    true,
+		}
+		if err := m.backendMigrateState(opts); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+
+		// Nothing may have been deleted, not even successfully copied
+		// workspaces.
+		remaining, err := source.Workspaces()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if want := []string{"default", "dev"}; !reflect.DeepEqual(remaining, want) {
+			t.Fatalf("source workspaces were deleted after a failure: %#v", remaining)
+		}
+	})
+}
+
+func TestBackendMigrate_progress(t *testing.T) {
+	t.Run("announces each workspace", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		ui := cli.NewMockUi()
+		m.Ui = ui
+		m.input = true
+
+		m.backendMigrateProgress(3, 17, "prod", "app-prod")
+		m.backendMigrateProgress(4, 17, "dev", "dev")
+
+		output := ui.OutputWriter.String()
+		if !strings.Contains(output, "Migrating workspace 3/17: prod -> app-prod") {
+			t.Fatalf("missing renamed-workspace progress line: %s", output)
+		}
+		if !strings.Contains(output, "Migrating workspace 4/17: dev\n") {
+			t.Fatalf("missing same-name progress line: %s", output)
+		}
+		if strings.Contains(output, "dev -> dev") {
+			t.Fatalf("same-name workspace should not show an arrow: %s", output)
+		}
+	})
+
+	t.Run("suppressed without input", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		ui := cli.NewMockUi()
+		m.Ui = ui
+		m.input = false
+
+		m.backendMigrateProgress(1, 2, "prod", "app-prod")
+		m.backendMigrateElapsed("prod", time.Now().Add(-time.Minute))
+
+		if output := ui.OutputWriter.String(); output != "" {
+			t.Fatalf("expected no output when input is disabled, got: %s", output)
+		}
+	})
+
+	t.Run("elapsed time only when slow", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		ui := cli.NewMockUi()
+		m.Ui = ui
+		m.input = true
+
+		m.backendMigrateElapsed("fast", time.Now())
+		if output := ui.OutputWriter.String(); output != "" {
+			t.Fatalf("fast workspace should not report elapsed time: %s", output)
+		}
+
+		m.backendMigrateElapsed("slow", time.Now().Add(-10*time.Second))
+		if output := ui.OutputWriter.String(); !strings.Contains(output, `Workspace "slow" took 10s.`) {
+			t.Fatalf("missing elapsed-time note: %s", output)
+		}
+	})
+
+	t.Run("multi-to-multi migration", func(t *testing.T) {
+		stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":2,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+		source := &renameableBackend{dir: t.TempDir()}
+		for _, name := range []string{"default", "dev"} {
+			if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(stateJSON), 0600); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}
+		destination := &renameableBackend{dir: t.TempDir()}
+
+		m := testMetaBackend(t, nil)
+		ui := cli.NewMockUi()
+		m.Ui = ui
+		m.input = true
+		m.forceInitCopy = true
+
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Source:          source,
+			Destination:     destination,
+		}
+		if err := m.backendMigrateState(opts); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		output := ui.OutputWriter.String()
+		if !strings.Contains(output, "Migrating workspace 1/2: default") {
+			t.Fatalf("missing progress for first workspace: %s", output)
+		}
+		if !strings.Contains(output, "Migrating workspace 2/2: dev") {
+			t.Fatalf("missing progress for second workspace: %s", output)
+		}
+	})
+}
+
+// racingBackend hands out a different pre-built state on every StateMgr
+// call, simulating a remote backend whose reads race a concurrent writer so
+// the source and destination snapshots of the same storage don't compare
+// equal.
+type racingBackend struct {
+	*renameableBackend
+	states []*states.State
+	calls  int
+}
+
+func (b *racingBackend) StateMgr(name string) (statemgr.Full, error) {
+	s := b.states[b.calls%len(b.states)]
+	b.calls++
+	return statemgr.NewFullFake(statemgr.NewTransientInMemory(s), s), nil
+}
+
+func TestBackendMigrate_sameLocation(t *testing.T) {
+	t.Run("same instance and workspace", func(t *testing.T) {
+		b := &renameableBackend{dir: t.TempDir()}
+		opts := &backendMigrateOpts{
+			Source:               b,
+			Destination:          b,
+			sourceWorkspace:      "prod",
+			destinationWorkspace: "prod",
+		}
+		if !backendMigrateSameLocation(opts) {
+			t.Fatal("the same workspace of the same instance should count as the same location")
+		}
+	})
+
+	t.Run("same instance, distinct workspaces", func(t *testing.T) {
+		b := &renameableBackend{dir: t.TempDir()}
+		opts := &backendMigrateOpts{
+			Source:               b,
+			Destination:          b,
+			sourceWorkspace:      "alpha",
+			destinationWorkspace: "beta",
+		}
+		if backendMigrateSameLocation(opts) {
+			t.Fatal("distinct workspaces of the same instance should not count as the same location")
+		}
+	})
+
+	t.Run("local backends sharing a path", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "terraform.tfstate")
+		opts := &backendMigrateOpts{
+			Source:               &backendLocal.Local{StatePath: path},
+			Destination:          &backendLocal.Local{StatePath: path},
+			sourceWorkspace:      backend.DefaultStateName,
+			destinationWorkspace: backend.DefaultStateName,
+		}
+		if !backendMigrateSameLocation(opts) {
+			t.Fatal("local backends with the same state path should count as the same location")
+		}
+	})
+
+	t.Run("local backends with distinct paths", func(t *testing.T) {
+		opts := &backendMigrateOpts{
+			Source:               &backendLocal.Local{StatePath: filepath.Join(t.TempDir(), "a.tfstate")},
+			Destination:          &backendLocal.Local{StatePath: filepath.Join(t.TempDir(), "b.tfstate")},
+			sourceWorkspace:      backend.DefaultStateName,
+			destinationWorkspace: backend.DefaultStateName,
+		}
+		if backendMigrateSameLocation(opts) {
+			t.Fatal("local backends with distinct state paths should not count as the same location")
+		}
+	})
+
+	t.Run("distinct backends", func(t *testing.T) {
+		opts := &backendMigrateOpts{
+			Source:               &renameableBackend{dir: t.TempDir()},
+			Destination:          &renameableBackend{dir: t.TempDir()},
+			sourceWorkspace:      backend.DefaultStateName,
+			destinationWorkspace: backend.DefaultStateName,
+		}
+		if backendMigrateSameLocation(opts) {
+			t.Fatal("distinct backend instances of an opaque type should not count as the same location")
+		}
+	})
+
+	t.Run("aborts a copy onto the same storage", func(t *testing.T) {
+		stateA := states.NewState()
+		stateA.SetOutputValue(
+			addrs.OutputValue{Name: "name"}.Absolute(addrs.RootModuleInstance),
+			cty.StringVal("a"), false,
+		)
+		stateB := states.NewState()
+		stateB.SetOutputValue(
+			addrs.OutputValue{Name: "name"}.Absolute(addrs.RootModuleInstance),
+			cty.StringVal("b"), false,
+		)
+
+		b := &racingBackend{
+			renameableBackend: &renameableBackend{dir: t.TempDir()},
+			states:            []*states.State{stateA, stateB},
+		}
+
+		m := testMetaBackend(t, nil)
+		err := m.backendMigrateState_s_s(context.Background(), &backendMigrateOpts{
+			SourceType:           "s3",
+			DestinationType:      "s3",
+			Source:               b,
+			Destination:          b,
+			sourceWorkspace:      backend.DefaultStateName,
+			destinationWorkspace: backend.DefaultStateName,
+			force:                true,
+		})
+		if err == nil {
+			t.Fatal("expected error, got none")
+		}
+		if !strings.Contains(err.Error(), "same state storage location") {
+			t.Fatalf("wrong error: %s", err)
+		}
+	})
+}
+
+type recordingMigrationHooks struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (h *recordingMigrationHooks) PreMigrateWorkspace(source, destination string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls = append(h.calls, fmt.Sprintf("pre %s->%s", source, destination))
+}
+
+func (h *recordingMigrationHooks) PostMigrateWorkspace(source, destination string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls = append(h.calls, fmt.Sprintf("post %s->%s err=%v", source, destination, err))
+}
+
+func TestBackendMigrate_hooks(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":2,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	source := &renameableBackend{dir: t.TempDir()}
+	for _, name := range []string{"default", "dev"} {
+		if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(stateJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+	destination := &renameableBackend{dir: t.TempDir()}
+
+	m := testMetaBackend(t, nil)
+	hooks := &recordingMigrationHooks{}
+	opts := &backendMigrateOpts{
+		SourceType:      "consul",
+		DestinationType: "local",
+		Source:          source,
+		Destination:     destination,
+		Hooks:           hooks,
+		force:           true,
+	}
+	if err := m.backendMigrateState_S_S(context.Background(), opts); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	want := []string{
+		"pre default->default",
+		"post default->default err=<nil>",
+		"pre dev->dev",
+		"post dev->dev err=<nil>",
+	}
+	if !reflect.DeepEqual(hooks.calls, want) {
+		t.Fatalf("wrong hook calls %#v; want %#v", hooks.calls, want)
+	}
+}
+
+func TestBackendMigrate_stats(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":2,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	path := filepath.Join(t.TempDir(), "terraform.tfstate")
+	if err := ioutil.WriteFile(path, []byte(stateJSON), 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	sourceState := statemgr.NewFilesystem(path)
+	if err := sourceState.RefreshState(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	stats := &backendMigrateStats{}
+	opts := &backendMigrateOpts{
+		sourceWorkspace:      "prod",
+		destinationWorkspace: "app-prod",
+		stats:                stats,
+	}
+	stats.record(opts, sourceState, 1500*time.Millisecond)
+	opts.sourceWorkspace, opts.destinationWorkspace = "dev", "app-dev"
+	stats.record(opts, sourceState, 500*time.Millisecond)
+
+	records := stats.snapshot()
+	if got := len(records); got != 2 {
+		t.Fatalf("wrong number of records %d; want 2", got)
+	}
+	if records[0].Bytes == 0 {
+		t.Fatal("serialized state size was not measured")
+	}
+
+	m := testMetaBackend(t, nil)
+	ui := cli.NewMockUi()
+	m.Ui = ui
+	m.backendMigrateRenderStats(opts)
+
+	output := ui.OutputWriter.String()
+	if !strings.Contains(output, "Migration statistics:") {
+		t.Fatalf("missing statistics header: %s", output)
+	}
+	if want := fmt.Sprintf("prod -> app-prod: %d bytes in 1.5s", records[0].Bytes); !strings.Contains(output, want) {
+		t.Fatalf("missing per-workspace line %q: %s", want, output)
+	}
+	if want := fmt.Sprintf("Total: %d bytes across 2 workspace(s) in 2s", records[0].Bytes+records[1].Bytes); !strings.Contains(output, want) {
+		t.Fatalf("missing totals line %q: %s", want, output)
+	}
+
+	// No report at all when nothing was copied.
+	ui = cli.NewMockUi()
+	m.Ui = ui
+	m.backendMigrateRenderStats(&backendMigrateOpts{stats: &backendMigrateStats{}})
+	if output := ui.OutputWriter.String(); output != "" {
+		t.Fatalf("expected no output for an empty report, got: %s", output)
+	}
+}
+
+func TestBackendMigrate_lineageMismatch(t *testing.T) {
+	sourceJSON := `{"version":4,"terraform_version":"1.0.0","serial":7,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+	destinationJSON := `{"version":4,"terraform_version":"1.0.0","serial":3,"lineage":"99999999-8888-7777-6666-555555555555","outputs":{"name":{"value":"b","type":"string"}},"resources":[]}`
+	sameLineageJSON := `{"version":4,"terraform_version":"1.0.0","serial":3,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"b","type":"string"}},"resources":[]}`
+
+	confirm := func(t *testing.T, destinationJSON string) string {
+		t.Helper()
+
+		td := t.TempDir()
+		sourcePath := filepath.Join(td, "source.tfstate")
+		destinationPath := filepath.Join(td, "destination.tfstate")
+		if err := ioutil.WriteFile(sourcePath, []byte(sourceJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if err := ioutil.WriteFile(destinationPath, []byte(destinationJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		sourceState := statemgr.NewFilesystem(sourcePath)
+		destinationState := statemgr.NewFilesystem(destinationPath)
+		if err := sourceState.RefreshState(); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if err := destinationState.RefreshState(); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		m := testMetaBackend(t, nil)
+		ui := cli.NewMockUi()
+		m.Ui = ui
+		m.input = true
+		cleanup := testInputMap(t, map[string]string{
+			"backend-migrate-to-backend": "no",
+		})
+		defer cleanup()
+
+		opts := &backendMigrateOpts{
+			SourceType:           "consul",
+			DestinationType:      "local",
+			sourceWorkspace:      backend.DefaultStateName,
+			destinationWorkspace: backend.DefaultStateName,
+		}
+		if _, err := m.backendMigrateNonEmptyConfirm(sourceState, destinationState, opts); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		return ui.OutputWriter.String()
+	}
+
+	t.Run("unrelated lineages", func(t *testing.T) {
+		output := confirm(t, destinationJSON)
+		if !strings.Contains(output, `Source state:      lineage "11111111-2222-3333-4444-555555555555", serial 7`) {
+			t.Fatalf("missing source lineage line: %s", output)
+		}
+		if !strings.Contains(output, `Destination state: lineage "99999999-8888-7777-6666-555555555555", serial 3`) {
+			t.Fatalf("missing destination lineage line: %s", output)
+		}
+		if !strings.Contains(output, "unrelated lineages") {
+			t.Fatalf("missing lineage mismatch warning: %s", output)
+		}
+		if !strings.Contains(output, "Overwriting would change the destination state") {
+			t.Fatalf("missing inline diff: %s", output)
+		}
+	})
+
+	t.Run("same lineage", func(t *testing.T) {
+		output := confirm(t, sameLineageJSON)
+		if !strings.Contains(output, `Destination state: lineage "11111111-2222-3333-4444-555555555555", serial 3`) {
+			t.Fatalf("missing destination lineage line: %s", output)
+		}
+		if strings.Contains(output, "unrelated lineages") {
+			t.Fatalf("unexpected lineage mismatch warning: %s", output)
+		}
+	})
+}
+
+func TestBackendMigrate_conflictStrategy(t *testing.T) {
+	newerJSON := `{"version":4,"terraform_version":"1.0.0","serial":7,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"newer","type":"string"}},"resources":[]}`
+	olderJSON := `{"version":4,"terraform_version":"1.0.0","serial":3,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"older","type":"string"}},"resources":[]}`
+
+	// migrate copies the default workspace with the given conflict strategy
+	// and returns the migration error, the UI output, and the final contents
+	// of the destination state file. Input stays disabled throughout, since
+	// the point of a conflict strategy is to never prompt.
+	migrate := func(t *testing.T, sourceJSON, destinationJSON, strategy string) (error, string, string) {
+		t.Helper()
+
+		source := &renameableBackend{dir: t.TempDir()}
+		if err := ioutil.WriteFile(filepath.Join(source.dir, "default.tfstate"), []byte(sourceJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		destination := &renameableBackend{dir: t.TempDir()}
+		if err := ioutil.WriteFile(filepath.Join(destination.dir, "default.tfstate"), []byte(destinationJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		m := testMetaBackend(t, nil)
+		ui := cli.NewMockUi()
+		m.Ui = ui
+
+		opts := &backendMigrateOpts{
+			SourceType:           "consul",
+			DestinationType:      "local",
+			Source:               source,
+			Destination:          destination,
+			ConflictStrategy:     strategy,
+			sourceWorkspace:      "default",
+			destinationWorkspace: "default",
+		}
+		err := m.backendMigrateState_s_s(context.Background(), opts)
+
+		data, readErr := ioutil.ReadFile(filepath.Join(destination.dir, "default.tfstate"))
+		if readErr != nil {
+			t.Fatalf("err: %s", readErr)
+		}
+		return err, ui.OutputWriter.String(), string(data)
+	}
+
+	t.Run("newest-serial copies a newer source", func(t *testing.T) {
+		err, _, final := migrate(t, newerJSON, olderJSON, "newest-serial")
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !strings.Contains(final, `"newer"`) {
+			t.Fatalf("destination was not overwritten by the newer source: %s", final)
+		}
+	})
+
+	t.Run("newest-serial skips an older source", func(t *testing.T) {
+		err, output, final := migrate(t, olderJSON, newerJSON, "newest-serial")
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !strings.Contains(final, `"newer"`) {
+			t.Fatalf("destination was overwritten by the older source: %s", final)
+		}
+		if !strings.Contains(output, "Skipped workspace") {
+			t.Fatalf("missing skip message: %s", output)
+		}
+	})
+
+	t.Run("source-wins always copies", func(t *testing.T) {
+		err, _, final := migrate(t, olderJSON, newerJSON, "source-wins")
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !strings.Contains(final, `"older"`) {
+			t.Fatalf("destination was not overwritten by the source: %s", final)
+		}
+	})
+
+	t.Run("abort fails the migration", func(t *testing.T) {
+		err, _, final := migrate(t, olderJSON, newerJSON, "abort")
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "conflict strategy is in effect") {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !strings.Contains(final, `"newer"`) {
+			t.Fatalf("destination was modified despite the abort: %s", final)
+		}
+	})
+}
+
+func TestBackendMigrate_destinationCollisions(t *testing.T) {
+	t.Run("unit", func(t *testing.T) {
+		err := checkMigrationDestinationCollisions(map[string]string{
+			"default": "app-prod",
+			"prod":    "app-prod",
+			"dev":     "app-dev",
+			"stage":   "app-dev",
+		})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "app-prod (from source workspaces default, prod)") {
+			t.Fatalf("error does not name the first collision: %s", err)
+		}
+		if !strings.Contains(err.Error(), "app-dev (from source workspaces dev, stage)") {
+			t.Fatalf("error does not name the second collision: %s", err)
+		}
+
+		if err := checkMigrationDestinationCollisions(map[string]string{
+			"default": "app-prod",
+			"dev":     "app-dev",
+		}); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	})
+
+	t.Run("interactive resolution", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+		m.input = true
+		defer testInputMap(t, map[string]string{
+			"new-state-name": "app-alt",
+		})()
+
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "cloud",
+		}
+		destinationNames := map[string]string{
+			"default": "app-prod",
+			"prod":    "app-prod",
+			"dev":     "app-dev",
+		}
+
+		// The prompted name replaces the second colliding workspace's
+		// destination; the first keeps the contested name.
+		if err := m.resolveMigrationDestinationCollisions(opts, destinationNames); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if destinationNames["default"] != "app-prod" {
+			t.Fatalf("first colliding workspace was renamed: %#v", destinationNames)
+		}
+		if destinationNames["prod"] != "app-alt" {
+			t.Fatalf("second colliding workspace was not renamed: %#v", destinationNames)
+		}
+		if destinationNames["dev"] != "app-dev" {
+			t.Fatalf("non-colliding workspace was renamed: %#v", destinationNames)
+		}
+	})
+
+	t.Run("prompted name still collides", func(t *testing.T) {
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+		m.input = true
+		defer testInputMap(t, map[string]string{
+			"new-state-name": "app-dev",
+		})()
+
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "cloud",
+		}
+		err := m.resolveMigrationDestinationCollisions(opts, map[string]string{
+			"default": "app-prod",
+			"prod":    "app-prod",
+			"dev":     "app-dev",
+		})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "app-dev (from source workspaces dev, prod)") {
+			t.Fatalf("error does not name the remaining collision: %s", err)
+		}
+	})
+
+	t.Run("pattern resolving to an unusable name", func(t *testing.T) {
+		stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+		source := &renameableBackend{dir: t.TempDir()}
+		if err := ioutil.WriteFile(filepath.Join(source.dir, "dev.tfstate"), []byte(stateJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		destination := &renameableBackend{dir: t.TempDir()}
+
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+
+		// The pattern pushes the resolved name past the destination's length
+		// limit, which must abort the migration before anything is copied.
+		opts := &backendMigrateOpts{
+			SourceType:       "consul",
+			DestinationType:  "cloud",
+			Source:           source,
+			Destination:      destination,
+			WorkspacePattern: strings.Repeat("x", backendMigrateMaxWorkspaceNameLength) + "-*",
+		}
+		err := m.backendMigrateState_S_TFC(context.Background(), opts, []string{"dev"})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), `Source workspace "dev"`) {
+			t.Fatalf("error does not name the source workspace: %s", err)
+		}
+		if !strings.Contains(err.Error(), "not a usable workspace name") {
+			t.Fatalf("wrong error: %s", err)
+		}
+
+		names, err := destination.Workspaces()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if len(names) != 0 {
+			t.Fatalf("workspaces were copied despite the bad name: %#v", names)
+		}
+	})
+
+	t.Run("multi-to-multi migration", func(t *testing.T) {
+		stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+		source := &renameableBackend{dir: t.TempDir()}
+		for _, name := range []string{"default", "prod"} {
+			if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(stateJSON), 0600); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}
+		destination := &renameableBackend{dir: t.TempDir()}
+
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+		m.input = false
+
+		// The explicit mapping for "default" collides with what the pattern
+		// resolves "prod" to; with input disabled there's no way to recover
+		// interactively, so the migration must abort before copying.
+		opts := &backendMigrateOpts{
+			SourceType:       "consul",
+			DestinationType:  "cloud",
+			Source:           source,
+			Destination:      destination,
+			WorkspacePattern: "app-*",
+			workspaceMap: map[string]string{
+				"default": "app-prod",
+			},
+		}
+		err := m.backendMigrateState_S_TFC(context.Background(), opts, []string{"default", "prod"})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "app-prod (from source workspaces default, prod)") {
+			t.Fatalf("error does not name the collision: %s", err)
+		}
+
+		names, err := destination.Workspaces()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if len(names) != 0 {
+			t.Fatalf("workspaces were copied despite the collision: %#v", names)
+		}
+	})
+}
+
+func TestBackendMigrate_destinationWorkspace(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	source := &renameableBackend{dir: t.TempDir()}
+	if err := ioutil.WriteFile(filepath.Join(source.dir, "default.tfstate"), []byte(stateJSON), 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	destination := &renameableBackend{dir: t.TempDir()}
+
+	m := testMetaBackend(t, nil)
+	m.Ui = cli.NewMockUi()
+	m.forceInitCopy = true
+
+	// Migrating into a named workspace reselects it, so put the selection
+	// back for the benefit of later tests.
+	defer func() {
+		if err := m.SetWorkspace(backend.DefaultStateName); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}()
+
+	opts := &backendMigrateOpts{
+		SourceType:           "consul",
+		DestinationType:      "local",
+		Source:               source,
+		Destination:          destination,
+		DestinationWorkspace: "app-prod",
+	}
+	if err := m.backendMigrateState(opts); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	names, err := destination.Workspaces()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if want := []string{"app-prod"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("wrong destination workspaces %#v; want %#v", names, want)
+	}
+
+	// The named workspace took over from the default one as the selection.
+	workspace, err := m.Workspace()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if workspace != "app-prod" {
+		t.Fatalf("wrong selected workspace %q; want %q", workspace, "app-prod")
+	}
+}
+
+// flakyStateMgr wraps a state manager whose PersistState fails a set number
+// of times before succeeding, to exercise the transient-error retry loop.
+type flakyStateMgr struct {
+	statemgr.Full
+	failures int
+	err      error
+	calls    int
+}
+
+func (f *flakyStateMgr) PersistState(schemas *terraform.Schemas) error {
+	f.calls++
+	if f.failures > 0 {
+		f.failures--
+		return f.err
+	}
+	return f.Full.PersistState(schemas)
+}
+
+func TestBackendMigrate_retries(t *testing.T) {
+	t.Run("transient classification", func(t *testing.T) {
+		transient := []string{
+			"read tcp 10.0.0.1:443: connection reset by peer",
+			"request failed: status code 503",
+			"Post \"https://app.terraform.io\": dial tcp: i/o timeout",
+		}
+		for _, message := range transient {
+			if !backendMigrateIsTransientErr(errors.New(message)) {
+				t.Errorf("%q should be transient", message)
+			}
+		}
+
+		permanent := []string{
+			"status code 401: unauthorized",
+			"workspace is locked: conflict",
+			"state snapshot was created by Terraform v9.9.9",
+		}
+		for _, message := range permanent {
+			if backendMigrateIsTransientErr(errors.New(message)) {
+				t.Errorf("%q should not be transient", message)
+			}
+		}
+		if backendMigrateIsTransientErr(nil) {
+			t.Error("nil error should not be transient")
+		}
+	})
+
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	migrate := func(t *testing.T, mgr *flakyStateMgr, retries int) error {
+		t.Helper()
+
+		source := &renameableBackend{dir: t.TempDir()}
+		if err := ioutil.WriteFile(filepath.Join(source.dir, "default.tfstate"), []byte(stateJSON), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		destination := &stateMgrBackend{mgrs: map[string]statemgr.Full{"default": mgr}}
+
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+
+		opts := &backendMigrateOpts{
+			SourceType:           "consul",
+			DestinationType:      "remote",
+			Source:               source,
+			Destination:          destination,
+			Retries:              retries,
+			force:                true,
+			sourceWorkspace:      "default",
+			destinationWorkspace: "default",
+		}
+		return m.backendMigrateState_s_s(context.Background(), opts)
+	}
+
+	t.Run("retries a transient persist failure", func(t *testing.T) {
+		mgr := &flakyStateMgr{
+			Full:     statemgr.NewFilesystem(filepath.Join(t.TempDir(), "default.tfstate")),
+			failures: 1,
+			err:      errors.New("connection reset by peer"),
+		}
+		if err := migrate(t, mgr, 3); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if mgr.calls != 2 {
+			t.Fatalf("wrong number of persist attempts %d; want 2", mgr.calls)
+		}
+	})
+
+	t.Run("gives up after the retry budget", func(t *testing.T) {
+		mgr := &flakyStateMgr{
+			Full:     statemgr.NewFilesystem(filepath.Join(t.TempDir(), "default.tfstate")),
+			failures: 5,
+			err:      errors.New("connection reset by peer"),
+		}
+		err := migrate(t, mgr, 1)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if mgr.calls != 2 {
+			t.Fatalf("wrong number of persist attempts %d; want 2", mgr.calls)
+		}
+	})
+
+	t.Run("never retries an auth failure", func(t *testing.T) {
+		mgr := &flakyStateMgr{
+			Full:     statemgr.NewFilesystem(filepath.Join(t.TempDir(), "default.tfstate")),
+			failures: 5,
+			err:      errors.New("status code 401: unauthorized"),
+		}
+		err := migrate(t, mgr, 3)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if mgr.calls != 1 {
+			t.Fatalf("wrong number of persist attempts %d; want 1", mgr.calls)
+		}
+	})
+}
+
+// taggingBackend wraps renameableBackend with workspace tag support, to
+// exercise the tag reporting in the TFC migration path.
+type taggingBackend struct {
+	*renameableBackend
+	tags   []string
+	err    error
+	tagged []string
+}
+
+func (b *taggingBackend) EnsureWorkspaceTags(name string) ([]string, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	b.tagged = append(b.tagged, name)
+	return b.tags, nil
+}
+
+func TestBackendMigrate_cloudTags(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+
+	migrate := func(t *testing.T, destination *taggingBackend) (*Meta, *cli.MockUi, *backendMigrateOpts, error) {
+		t.Helper()
+
+		source := &renameableBackend{dir: t.TempDir()}
+		for _, name := range []string{"default", "dev"} {
+			if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(stateJSON), 0600); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}
+
+		m := testMetaBackend(t, nil)
+		ui := cli.NewMockUi()
+		m.Ui = ui
+
+		// Migrating the current workspace reselects it under its new name,
+		// so put the selection back for the benefit of later tests.
+		t.Cleanup(func() {
+			if err := m.SetWorkspace(backend.DefaultStateName); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		})
+
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "cloud",
+			Source:          source,
+			Destination:     destination,
+			recorder:        &backendMigrateRecorder{},
+			workspaceMap: map[string]string{
+				"default": "app-prod",
+				"dev":     "app-dev",
+			},
+		}
+		err := m.backendMigrateState_S_TFC(context.Background(), opts, []string{"default", "dev"})
+		return m, ui, opts, err
+	}
+
+	t.Run("reports applied tags", func(t *testing.T) {
+		destination := &taggingBackend{
+			renameableBackend: &renameableBackend{dir: t.TempDir()},
+			tags:              []string{"env:migrated", "team-a"},
+		}
+		_, ui, opts, err := migrate(t, destination)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		// Tagging happens in source-workspace order: default, then dev.
+		if want := []string{"app-prod", "app-dev"}; !reflect.DeepEqual(destination.tagged, want) {
+			t.Fatalf("wrong workspaces tagged %#v; want %#v", destination.tagged, want)
+		}
+		output := ui.OutputWriter.String()
+		if !strings.Contains(output, "Tags attached to the migrated workspaces:") {
+			t.Fatalf("missing tag report: %s", output)
+		}
+		if !strings.Contains(output, "app-prod: env:migrated, team-a") {
+			t.Fatalf("missing per-workspace tag line: %s", output)
+		}
+
+		for _, record := range opts.recorder.records() {
+			if want := []string{"env:migrated", "team-a"}; !reflect.DeepEqual(record.Tags, want) {
+				t.Fatalf("wrong tags on record %q: %#v", record.DestinationName, record.Tags)
+			}
+		}
+	})
+
+	t.Run("tag failures do not abort the migration", func(t *testing.T) {
+		destination := &taggingBackend{
+			renameableBackend: &renameableBackend{dir: t.TempDir()},
+			err:               errors.New("error tagging workspace: boom"),
+		}
+		_, ui, _, err := migrate(t, destination)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		names, err := destination.Workspaces()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if want := []string{"app-dev", "app-prod"}; !reflect.DeepEqual(names, want) {
+			t.Fatalf("state was not copied despite tag failure: %#v", names)
+		}
+		output := ui.OutputWriter.String()
+		if !strings.Contains(output, "Failed to attach tags to some migrated workspaces:") {
+			t.Fatalf("missing tag failure report: %s", output)
+		}
+		if !strings.Contains(output, "app-dev: error tagging workspace: boom") {
+			t.Fatalf("missing per-workspace failure line: %s", output)
+		}
+	})
+}
+
+func TestBackendMigrate_alreadyMigrated(t *testing.T) {
+	stateJSON := `{"version":4,"terraform_version":"1.0.0","serial":3,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"a","type":"string"}},"resources":[]}`
+	otherJSON := `{"version":4,"terraform_version":"1.0.0","serial":1,"lineage":"99999999-8888-7777-6666-555555555555","outputs":{"name":{"value":"b","type":"string"}},"resources":[]}`
+
+	// Simulate resuming after a partial failure: workspace "a" already made
+	// it across, workspace "b" did not.
+	source := &renameableBackend{dir: t.TempDir()}
+	for _, name := range []string{"a", "b"} {
+		content := stateJSON
+		if name == "b" {
+			content = otherJSON
+		}
+		if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(content), 0600); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+	destination := &renameableBackend{dir: t.TempDir()}
+	if err := ioutil.WriteFile(filepath.Join(destination.dir, "a.tfstate"), []byte(stateJSON), 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	m := testMetaBackend(t, nil)
+	m.Ui = cli.NewMockUi()
+
+	opts := &backendMigrateOpts{
+		SourceType:      "consul",
+		DestinationType: "local",
+		Source:          source,
+		Destination:     destination,
+		recorder:        &backendMigrateRecorder{},
+		force:           true,
+	}
+	if err := m.backendMigrateState_S_S(context.Background(), opts); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	skipped := map[string]bool{}
+	for _, record := range opts.recorder.records() {
+		skipped[record.SourceName] = record.Skipped
+	}
+	if !skipped["a"] {
+		t.Fatalf("workspace \"a\" was re-copied instead of skipped: %#v", opts.recorder.records())
+	}
+	if skipped["b"] {
+		t.Fatalf("workspace \"b\" was skipped instead of copied: %#v", opts.recorder.records())
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(destination.dir, "b.tfstate"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !strings.Contains(string(data), `"b"`) {
+		t.Fatalf("workspace \"b\" was not copied: %s", data)
+	}
+}
+
+func TestBackendMigrate_migrateWorkspaceState(t *testing.T) {
+	sourceJSON := `{"version":4,"terraform_version":"1.0.0","serial":7,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"source","type":"string"}},"resources":[]}`
+	destinationJSON := `{"version":4,"terraform_version":"1.0.0","serial":3,"lineage":"99999999-8888-7777-6666-555555555555","outputs":{"name":{"value":"destination","type":"string"}},"resources":[]}`
+
+	// manager writes the given state JSON (if any) to a file in a fresh
+	// temporary directory and returns a refreshed filesystem manager for it.
+	manager := func(t *testing.T, stateJSON string) (*statemgr.Filesystem, string) {
+		t.Helper()
+
+		path := filepath.Join(t.TempDir(), "terraform.tfstate")
+		if stateJSON != "" {
+			if err := ioutil.WriteFile(path, []byte(stateJSON), 0600); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}
+		mgr := statemgr.NewFilesystem(path)
+		if err := mgr.RefreshState(); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		return mgr, path
+	}
+
+	t.Run("copies into an empty destination", func(t *testing.T) {
+		src, _ := manager(t, sourceJSON)
+		dst, dstPath := manager(t, "")
+
+		confirmed := false
+		err := MigrateWorkspaceState(dst, src, MigrateOptions{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Confirm: func(src, dst statemgr.Full) (bool, error) {
+				confirmed = true
+				return true, nil
+			},
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !confirmed {
+			t.Fatal("confirmation callback was never called")
+		}
+
+		data, err := ioutil.ReadFile(dstPath)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !strings.Contains(string(data), `"source"`) {
+			t.Fatalf("state was not copied: %s", data)
+		}
+		if !strings.Contains(string(data), `"11111111-2222-3333-4444-555555555555"`) {
+			t.Fatalf("lineage was not preserved: %s", data)
+		}
+	})
+
+	t.Run("declined copy leaves the destination alone", func(t *testing.T) {
+		src, _ := manager(t, sourceJSON)
+		dst, dstPath := manager(t, destinationJSON)
+
+		err := MigrateWorkspaceState(dst, src, MigrateOptions{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Confirm: func(src, dst statemgr.Full) (bool, error) {
+				return false, nil
+			},
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		data, err := ioutil.ReadFile(dstPath)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !strings.Contains(string(data), `"destination"`) {
+			t.Fatalf("destination state was overwritten: %s", data)
+		}
+	})
+
+	t.Run("equal states are a no-op", func(t *testing.T) {
+		src, _ := manager(t, sourceJSON)
+		dst, _ := manager(t, sourceJSON)
+
+		err := MigrateWorkspaceState(dst, src, MigrateOptions{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Confirm: func(src, dst statemgr.Full) (bool, error) {
+				t.Fatal("confirmation callback called for equal states")
+				return false, nil
+			},
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	})
+
+	t.Run("new lineage decouples the destination", func(t *testing.T) {
+		src, _ := manager(t, sourceJSON)
+		dst, dstPath := manager(t, "")
+
+		err := MigrateWorkspaceState(dst, src, MigrateOptions{
+			SourceType:      "consul",
+			DestinationType: "local",
+			NewLineage:      true,
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		data, err := ioutil.ReadFile(dstPath)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !strings.Contains(string(data), `"source"`) {
+			t.Fatalf("state was not copied: %s", data)
+		}
+		if strings.Contains(string(data), `"11111111-2222-3333-4444-555555555555"`) {
+			t.Fatalf("source lineage was inherited: %s", data)
+		}
+	})
+
+	t.Run("new lineage disables the equal-state no-op", func(t *testing.T) {
+		src, _ := manager(t, sourceJSON)
+		dst, _ := manager(t, sourceJSON)
+
+		confirmed := false
+		err := MigrateWorkspaceState(dst, src, MigrateOptions{
+			SourceType:      "consul",
+			DestinationType: "local",
+			NewLineage:      true,
+			Confirm: func(src, dst statemgr.Full) (bool, error) {
+				confirmed = true
+				return true, nil
+			},
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !confirmed {
+			t.Fatal("equal states were skipped despite the new-lineage request")
+		}
+	})
+
+	t.Run("lock and persist callbacks run around the copy", func(t *testing.T) {
+		src, _ := manager(t, sourceJSON)
+		dst, _ := manager(t, "")
+
+		var steps []string
+		err := MigrateWorkspaceState(dst, src, MigrateOptions{
+			SourceType:      "consul",
+			DestinationType: "local",
+			Lock: func(src, dst statemgr.Full) (func() error, error) {
+				steps = append(steps, "lock")
+				return func() error {
+					steps = append(steps, "unlock")
+					return nil
+				}, nil
+			},
+			PrePersist: func(dst statemgr.Full) error {
+				steps = append(steps, "pre-persist")
+				return nil
+			},
+			Persist: func(dst statemgr.Full) error {
+				steps = append(steps, "persist")
+				return dst.PersistState(nil)
+			},
+		})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		want := []string{"lock", "pre-persist", "persist", "unlock"}
+		if !reflect.DeepEqual(steps, want) {
+			t.Fatalf("wrong callback order %#v; want %#v", steps, want)
+		}
+	})
+}
+
+// versionCheckedBackend is a renameableBackend that also implements
+// BackendWithRemoteTerraformVersion, reporting a version conflict for the
+// workspaces named in bad and recording which workspaces were checked.
+type versionCheckedBackend struct {
+	*renameableBackend
+	bad     map[string]bool
+	checked []string
+}
+
+func (b *versionCheckedBackend) IgnoreVersionConflict() {}
+
+func (b *versionCheckedBackend) VerifyWorkspaceTerraformVersion(workspace string) tfdiags.Diagnostics {
+	b.checked = append(b.checked, workspace)
+	var diags tfdiags.Diagnostics
+	if b.bad[workspace] {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible Terraform version",
+			fmt.Sprintf("Remote workspace %q requires a newer Terraform version.", workspace),
+		))
+	}
+	return diags
+}
+
+func (b *versionCheckedBackend) IsLocalOperations() bool { return true }
+
+func TestBackendMigrate_ignoreRemoteVersionFor(t *testing.T) {
+	sourceJSON := `{"version":4,"terraform_version":"1.0.0","serial":7,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"source","type":"string"}},"resources":[]}`
+	destinationJSON := `{"version":4,"terraform_version":"1.0.0","serial":3,"lineage":"11111111-2222-3333-4444-555555555555","outputs":{"name":{"value":"destination","type":"string"}},"resources":[]}`
+
+	// backends builds a source with two populated workspaces and a
+	// destination that reports a version conflict for "legacy1".
+	backends := func(t *testing.T) (*renameableBackend, *versionCheckedBackend) {
+		t.Helper()
+
+		source := &renameableBackend{dir: t.TempDir()}
+		destination := &versionCheckedBackend{
+			renameableBackend: &renameableBackend{dir: t.TempDir()},
+			bad:               map[string]bool{"legacy1": true},
+		}
+		for _, name := range []string{"legacy1", "modern"} {
+			if err := ioutil.WriteFile(filepath.Join(source.dir, name+".tfstate"), []byte(sourceJSON), 0600); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			if err := ioutil.WriteFile(filepath.Join(destination.dir, name+".tfstate"), []byte(destinationJSON), 0600); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}
+		return source, destination
+	}
+
+	t.Run("enforced without an allowlist", func(t *testing.T) {
+		source, destination := backends(t)
+		m := testMetaBackend(t, nil)
+		m.Ui = cli.NewMockUi()
+		m.forceInitCopy = true
+
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "remote",
+			Source:          source,
+			Destination:     destination,
+		}
+		err := m.backendMigrateState(opts)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "newer Terraform version") {
+			t.Fatalf("error does not mention the version conflict: %s", err)
+		}
+	})
+
+	t.Run("allowlisted workspaces are bypassed and reported", func(t *testing.T) {
+		source, destination := backends(t)
+		m := testMetaBackend(t, nil)
+		ui := cli.NewMockUi()
+		m.Ui = ui
+		m.forceInitCopy = true
+		m.ignoreRemoteVersionFor = []string{"legacy1"}
+
+		opts := &backendMigrateOpts{
+			SourceType:      "consul",
+			DestinationType: "remote",
+			Source:          source,
+			Destination:     destination,
+		}
+		if err := m.backendMigrateState(opts); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		// Only the workspace outside the allowlist was checked.
+		if want := []string{"modern"}; !reflect.DeepEqual(destination.checked, want) {
+			t.Fatalf("wrong checked workspaces %#v; want %#v", destination.checked, want)
+		}
+
+		output := ui.OutputWriter.String()
+		if !strings.Contains(output, "Skipped the remote version check for 1 workspace(s):") ||
+			!strings.Contains(output, "legacy1") {
+			t.Fatalf("missing bypass report: %s", output)
+		}
+
+		// The bypassed workspace was still migrated.
+		data, err := ioutil.ReadFile(filepath.Join(destination.dir, "legacy1.tfstate"))
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !strings.Contains(string(data), `"source"`) {
+			t.Fatalf("workspace \"legacy1\" was not copied: %s", data)
+		}
+	})
 }
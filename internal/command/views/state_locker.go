@@ -16,6 +16,11 @@ import (
 type StateLocker interface {
 	Locking()
 	Unlocking()
+
+	// Waiting reports that the lock is currently held by someone else and
+	// that Terraform is waiting to retry, identifying the holder where that
+	// information is available.
+	Waiting(who, id string)
 }
 
 // NewStateLocker returns an initialized StateLocker implementation for the given ViewType.
@@ -47,6 +52,13 @@ func (v *StateLockerHuman) Unlocking() {
 	v.view.streams.Println("Releasing state lock. This may take a few moments...")
 }
 
+func (v *StateLockerHuman) Waiting(who, id string) {
+	if who == "" {
+		who = "another process"
+	}
+	v.view.streams.Printf("Waiting for state lock held by %s (ID: %s)...\n", who, id)
+}
+
 // StateLockerJSON is an implementation of StateLocker which prints the state lock status
 // to a terminal in machine-readable JSON form.
 type StateLockerJSON struct {
@@ -80,3 +92,21 @@ func (v *StateLockerJSON) Unlocking() {
 	lock_info_message, _ := json.Marshal(json_data)
 	v.view.streams.Println(string(lock_info_message))
 }
+
+func (v *StateLockerJSON) Waiting(who, id string) {
+	current_timestamp := time.Now().Format(time.RFC3339)
+
+	if who == "" {
+		who = "another process"
+	}
+
+	json_data := map[string]string{
+		"@level":     "info",
+		"@message":   fmt.Sprintf("Waiting for state lock held by %s (ID: %s)...", who, id),
+		"@module":    "terraform.ui",
+		"@timestamp": current_timestamp,
+		"type":       "state_lock_wait"}
+
+	lock_info_message, _ := json.Marshal(json_data)
+	v.view.streams.Println(string(lock_info_message))
+}
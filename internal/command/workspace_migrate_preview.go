@@ -0,0 +1,161 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform/internal/backend"
+	"github.com/hashicorp/terraform/internal/command/clistate"
+	"github.com/hashicorp/terraform/internal/tfdiags"
+	"github.com/posener/complete"
+)
+
+// WorkspaceMigratePreviewCommand reports, without migrating anything, which
+// destination workspaces a backend migration would overwrite and which it
+// would create, so that conflicts can be discovered before running the
+// "terraform init" that triggers the migration.
+type WorkspaceMigratePreviewCommand struct {
+	Meta
+	LegacyName bool
+}
+
+func (c *WorkspaceMigratePreviewCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	envCommandShowWarning(c.Ui, c.LegacyName)
+
+	cmdFlags := c.Meta.defaultFlagSet("workspace migrate-preview")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+
+	args = cmdFlags.Args()
+	configPath, err := ModulePath(args)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	var diags tfdiags.Diagnostics
+
+	backendConfig, backendDiags := c.loadBackendConfig(configPath)
+	diags = diags.Append(backendDiags)
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	if backendConfig == nil {
+		c.Ui.Error(strings.TrimSpace(errMigratePreviewNoConfig))
+		return 1
+	}
+
+	// The previously-initialized backend is described by the working
+	// directory's backend configuration state, same as during init.
+	statePath := filepath.Join(c.DataDir(), DefaultStateFilename)
+	sMgr := &clistate.LocalState{Path: statePath}
+	if err := sMgr.RefreshState(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to load state: %s", err))
+		return 1
+	}
+
+	s := sMgr.State()
+	if s == nil || s.Backend.Empty() {
+		c.Ui.Output(strings.TrimSpace(outputMigratePreviewNoSource))
+		return 0
+	}
+
+	source, sourceDiags := c.savedBackend(sMgr)
+	diags = diags.Append(sourceDiags)
+	if sourceDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	destination, _, destinationDiags := c.backendInitFromConfig(backendConfig)
+	diags = diags.Append(destinationDiags)
+	if destinationDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// This command will not write state
+	c.ignoreRemoteVersionConflict(source)
+	c.ignoreRemoteVersionConflict(destination)
+
+	preview, err := backendMigratePreview(source, destination)
+	if err == backend.ErrWorkspacesNotSupported {
+		c.Ui.Output(strings.TrimSpace(outputMigratePreviewSingle))
+		return 0
+	}
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	if len(preview.Overwrite) > 0 {
+		c.Ui.Output("Workspaces that would be overwritten (the destination workspace already has state):")
+		for _, name := range preview.Overwrite {
+			c.Ui.Output("  " + name)
+		}
+	} else {
+		c.Ui.Output("No destination workspaces would be overwritten.")
+	}
+
+	if len(preview.Create) > 0 {
+		c.Ui.Output("Workspaces that would be migrated without conflict:")
+		for _, name := range preview.Create {
+			c.Ui.Output("  " + name)
+		}
+	}
+
+	return 0
+}
+
+func (c *WorkspaceMigratePreviewCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictDirs("")
+}
+
+func (c *WorkspaceMigratePreviewCommand) AutocompleteFlags() complete.Flags {
+	return nil
+}
+
+func (c *WorkspaceMigratePreviewCommand) Help() string {
+	helpText := `
+Usage: terraform [global options] workspace migrate-preview
+
+  List which workspaces a backend migration would overwrite or create.
+
+  The preview compares the workspaces of the previously-initialized backend
+  against the backend currently described in the configuration, without
+  migrating or modifying any state. Run this before the "terraform init" that
+  performs the migration to discover collisions ahead of time.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *WorkspaceMigratePreviewCommand) Synopsis() string {
+	return "Preview workspace collisions before a state migration"
+}
+
+const errMigratePreviewNoConfig = `
+The configuration has no backend block, so "terraform init" would migrate any
+existing state to the default local backend. This command can only preview
+migrations between configured backends.
+`
+
+const outputMigratePreviewNoSource = `
+The working directory has no previously-initialized backend, so
+"terraform init" would not migrate any existing state.
+`
+
+const outputMigratePreviewSingle = `
+Either the source or the destination backend supports only a single
+workspace, so "terraform init" would migrate at most one state and there are
+no workspace collisions to preview.
+`
@@ -77,6 +77,16 @@ type Attribute struct {
 	// currently achieves this in a limited sense via other mechanisms.)
 	Sensitive bool
 
+	// WriteOnly, if set to true, indicates that the attribute's value is
+	// only ever written to the provider and is never retained or returned,
+	// and therefore cannot be referenced from elsewhere in the
+	// configuration.
+	//
+	// At present providers cannot yet populate this field over the plugin
+	// protocol, but validation honors it where it is set so that behavior
+	// is already in place once the protocol catches up.
+	WriteOnly bool
+
 	Deprecated bool
 }
 
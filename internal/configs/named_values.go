@@ -535,25 +535,43 @@ var outputBlockSchema = &hcl.BodySchema{
 func checkVariableValidationBlock(varName string, vv *CheckRule) hcl.Diagnostics {
 	var diags hcl.Diagnostics
 
-	if vv.Condition != nil {
-		// The validation condition must include a reference to the variable itself
-		for _, traversal := range vv.Condition.Variables() {
-			ref, moreDiags := addrs.ParseRef(traversal)
-			if !moreDiags.HasErrors() {
-				if addr, ok := ref.Subject.(addrs.InputVariable); ok {
-					if addr.Name == varName {
-						return nil
-					}
-				}
+	if vv.Condition == nil {
+		return nil
+	}
+
+	// The validation condition must include a reference to the variable
+	// itself; that is the one self-reference that's expected here. References
+	// to resources or data sources are flagged instead, since those can
+	// themselves depend on the variable's value and so would create an
+	// evaluation cycle.
+	foundSelf := false
+	for _, traversal := range vv.Condition.Variables() {
+		ref, moreDiags := addrs.ParseRef(traversal)
+		if moreDiags.HasErrors() {
+			continue
+		}
+		switch addr := ref.Subject.(type) {
+		case addrs.InputVariable:
+			if addr.Name == varName {
+				foundSelf = true
 			}
+		case addrs.Resource, addrs.ResourceInstance:
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid reference in variable validation",
+				Detail:   fmt.Sprintf("The condition for variable %q may refer to var.%s itself, but not to %s: resources can depend on variable values, so this reference can create an evaluation cycle.", varName, varName, ref.Subject.String()),
+				Subject:  traversal.SourceRange().Ptr(),
+			})
 		}
+	}
 
-		return diags.Append(&hcl.Diagnostic{
+	if !foundSelf {
+		diags = diags.Append(&hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Invalid variable validation condition",
 			Detail:   fmt.Sprintf("The condition for variable %q must refer to var.%s in order to test incoming values.", varName, varName),
 			Subject:  vv.Condition.Range().Ptr(),
 		})
 	}
-	return nil
+	return diags
 }
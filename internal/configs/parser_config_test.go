@@ -132,6 +132,11 @@ func TestParserLoadConfigFileFailureMessages(t *testing.T) {
 			hcl.DiagError,
 			"Unsuitable value type",
 		},
+		{
+			"invalid-files/variable-validation-condition-cycleref.tf",
+			hcl.DiagError,
+			"Invalid reference in variable validation",
+		},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,9 @@
+resource "test_instance" "foo" {
+}
+
+variable "validation" {
+  validation {
+    condition     = var.validation != test_instance.foo.id # ERROR: Invalid reference in variable validation
+    error_message = "Must not match the instance id."
+  }
+}
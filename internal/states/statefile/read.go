@@ -151,6 +151,14 @@ func readState(src []byte) (*File, error) {
 	return result, err
 }
 
+// SniffFormatVersion attempts to determine the state file format version of
+// the given source code without fully parsing or upgrading it. This is
+// useful for callers that need to report on the format a snapshot is
+// currently stored in, since Read always upgrades to the latest format.
+func SniffFormatVersion(src []byte) (uint64, tfdiags.Diagnostics) {
+	return sniffJSONStateVersion(src)
+}
+
 func sniffJSONStateVersion(src []byte) (uint64, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 
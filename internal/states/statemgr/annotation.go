@@ -0,0 +1,22 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package statemgr
+
+// Annotator is an optional interface implemented by state managers that can
+// persist arbitrary key/value metadata annotations alongside the state
+// itself.
+//
+// Annotations are auxiliary metadata, such as a record of where a state
+// snapshot was migrated from. They are not part of the state snapshot and
+// recording one must not affect the snapshot's serial or lineage.
+//
+// Callers must not assume that a particular state manager supports
+// annotations, and should degrade gracefully (e.g. by emitting a warning)
+// when a manager does not implement this interface.
+type Annotator interface {
+	// RecordAnnotation persists the given key/value pair in the metadata
+	// associated with the state, replacing any previous value recorded for
+	// the same key.
+	RecordAnnotation(key, value string) error
+}
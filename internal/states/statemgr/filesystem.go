@@ -64,9 +64,11 @@ type Filesystem struct {
 }
 
 var (
-	_ Full           = (*Filesystem)(nil)
-	_ PersistentMeta = (*Filesystem)(nil)
-	_ Migrator       = (*Filesystem)(nil)
+	_ Full            = (*Filesystem)(nil)
+	_ PersistentMeta  = (*Filesystem)(nil)
+	_ Migrator        = (*Filesystem)(nil)
+	_ Annotator       = (*Filesystem)(nil)
+	_ FormatVersioner = (*Filesystem)(nil)
 )
 
 // NewFilesystem creates a filesystem-based state manager that reads and writes
@@ -447,6 +449,65 @@ func (s *Filesystem) WriteStateForMigration(f *statefile.File, force bool) error
 	return nil
 }
 
+// StateFormatVersion is part of our implementation of FormatVersioner,
+// sniffing the format version directly from the snapshot file so that the
+// result reflects how the snapshot is actually stored, rather than the
+// version it would be upgraded to on read.
+func (s *Filesystem) StateFormatVersion() (uint64, error) {
+	defer s.mutex()()
+
+	src, err := ioutil.ReadFile(s.readPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, statefile.ErrNoState
+		}
+		return 0, err
+	}
+	if len(src) == 0 {
+		return 0, statefile.ErrNoState
+	}
+
+	version, diags := statefile.SniffFormatVersion(src)
+	if diags.HasErrors() {
+		return 0, diags.Err()
+	}
+	return version, nil
+}
+
+// RecordAnnotation is part of our implementation of Annotator.
+//
+// The state file format has no field for arbitrary metadata, so annotations
+// are stored in a JSON sidecar file alongside the state path.
+func (s *Filesystem) RecordAnnotation(key, value string) error {
+	defer s.mutex()()
+
+	path := s.annotationsPath()
+	annotations := map[string]string{}
+	if data, err := ioutil.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &annotations); err != nil {
+			return fmt.Errorf("could not unmarshal existing annotations from %q: %s", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	annotations[key] = value
+
+	data, err := json.MarshalIndent(annotations, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[TRACE] statemgr.Filesystem: writing annotation %q to %s", key, path)
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("could not write annotations for %q: %s", s.path, err)
+	}
+	return nil
+}
+
+func (s *Filesystem) annotationsPath() string {
+	return s.path + ".annotations.json"
+}
+
 // Open the state file, creating the directories and file as needed.
 func (s *Filesystem) createStateFiles() error {
 	log.Printf("[TRACE] statemgr.Filesystem: preparing to manage state snapshots at %s", s.path)
@@ -5,10 +5,12 @@ package statemgr
 
 import (
 	"context"
+	"encoding/json"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
@@ -334,6 +336,63 @@ func TestFilesystem_lockUnlockWithoutWrite(t *testing.T) {
 	}
 }
 
+func TestFilesystem_stateFormatVersion(t *testing.T) {
+	// A snapshot in an old format must report the version it is actually
+	// stored in, not the version it would be upgraded to on read.
+	path := filepath.Join(t.TempDir(), "terraform.tfstate")
+	if err := ioutil.WriteFile(path, []byte(`{"version": 3, "terraform_version": "0.11.14"}`), 0600); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ls := NewFilesystem(path)
+	got, err := ls.StateFormatVersion()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got != 3 {
+		t.Fatalf("wrong format version %d; want 3", got)
+	}
+
+	// A missing snapshot is reported as ErrNoState.
+	ls = NewFilesystem(filepath.Join(t.TempDir(), "nonexist.tfstate"))
+	if _, err := ls.StateFormatVersion(); err != statefile.ErrNoState {
+		t.Fatalf("wrong error %v; want statefile.ErrNoState", err)
+	}
+}
+
+func TestFilesystem_recordAnnotation(t *testing.T) {
+	ls := testFilesystem(t)
+	defer os.Remove(ls.annotationsPath())
+
+	if err := ls.RecordAnnotation("migration-provenance", "migrated from \"consul\""); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := ls.RecordAnnotation("example", "one"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	// Recording the same key again must replace the previous value.
+	if err := ls.RecordAnnotation("example", "two"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	data, err := ioutil.ReadFile(ls.annotationsPath())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	annotations := map[string]string{}
+	if err := json.Unmarshal(data, &annotations); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	want := map[string]string{
+		"migration-provenance": "migrated from \"consul\"",
+		"example":              "two",
+	}
+	if !reflect.DeepEqual(annotations, want) {
+		t.Fatalf("wrong annotations\ngot:  %#v\nwant: %#v", annotations, want)
+	}
+}
+
 func TestFilesystem_impl(t *testing.T) {
 	defer testOverrideVersion(t, "1.2.3")()
 	var _ Reader = new(Filesystem)
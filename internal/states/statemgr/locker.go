@@ -13,6 +13,7 @@ import (
 	"os"
 	"os/user"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
@@ -20,7 +21,14 @@ import (
 	"github.com/hashicorp/terraform/version"
 )
 
-var rngSource = rand.New(rand.NewSource(time.Now().UnixNano()))
+var (
+	rngSource = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	// rngMu guards rngSource, which is not safe for concurrent use; a
+	// parallel state migration acquires locks from several goroutines at
+	// once.
+	rngMu sync.Mutex
+)
 
 // Locker is the interface for state managers that are able to manage
 // mutual-exclusion locks for state.
@@ -73,6 +81,14 @@ var postLockHook func()
 // This method has a built-in retry/backoff behavior up to the context's
 // timeout.
 func LockWithContext(ctx context.Context, s Locker, info *LockInfo) (string, error) {
+	return LockWithContextProgress(ctx, s, info, nil)
+}
+
+// LockWithContextProgress is like LockWithContext, except that the given
+// progress callback, if non-nil, is called with the current lock error each
+// time an attempt fails and a retry is pending. Callers can use this to tell
+// the user who is holding the lock they are waiting on.
+func LockWithContextProgress(ctx context.Context, s Locker, info *LockInfo, progress func(*LockError)) (string, error) {
 	delay := time.Second
 	maxDelay := 16 * time.Second
 	for {
@@ -97,6 +113,10 @@ func LockWithContext(ctx context.Context, s Locker, info *LockInfo) (string, err
 			postLockHook()
 		}
 
+		if progress != nil {
+			progress(le)
+		}
+
 		// there's an existing lock, wait and try again
 		select {
 		case <-ctx.Done():
@@ -147,7 +167,9 @@ func NewLockInfo() *LockInfo {
 	// Using math/rand alleviates the need to check handle the read error.
 	// Use a uuid format to match other IDs used throughout Terraform.
 	buf := make([]byte, 16)
+	rngMu.Lock()
 	rngSource.Read(buf)
+	rngMu.Unlock()
 
 	id, err := uuid.FormatUUID(buf)
 	if err != nil {
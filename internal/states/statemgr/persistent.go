@@ -101,6 +101,21 @@ type PersistentMeta interface {
 	StateSnapshotMeta() SnapshotMeta
 }
 
+// FormatVersioner is an optional extension to Persistent for managers that
+// can report the state file format version their most recent persistent
+// snapshot is stored in, before any upgrading that happens at read time.
+//
+// Not all storage can cheaply access the raw snapshot, so callers must not
+// assume that a particular state manager implements this interface.
+type FormatVersioner interface {
+	// StateFormatVersion returns the raw state file format version of the
+	// current persistent snapshot.
+	//
+	// If there is no persistent snapshot yet, the error is
+	// statefile.ErrNoState.
+	StateFormatVersion() (uint64, error)
+}
+
 // SnapshotMeta contains metadata about a persisted state snapshot.
 //
 // This metadata is usually (but not necessarily) included as part of the
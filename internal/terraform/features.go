@@ -8,3 +8,8 @@ import "os"
 // This file holds feature flags for the next release
 
 var flagWarnOutputErrors = os.Getenv("TF_WARN_OUTPUT_ERRORS") != ""
+
+// flagWarnSelfRefs downgrades self-reference diagnostics from errors to
+// warnings, so that a configuration which is intentionally self-referential
+// mid-refactor doesn't block the rest of validation.
+var flagWarnSelfRefs = os.Getenv("TF_WARN_SELF_REFERENCES") != ""
@@ -224,6 +224,11 @@ func (b *PlanGraphBuilder) Steps() []GraphTransformer {
 		// analyze the configuration to find references.
 		&AttachSchemaTransformer{Plugins: b.Plugins, Config: b.Config},
 
+		// With providers resolved and schemas attached, catch provider
+		// configurations that refer to their own managed resources, which
+		// would otherwise only fail as an opaque dependency cycle.
+		&providerSelfRefTransformer{},
+
 		// Create expansion nodes for all of the module calls. This must
 		// come after all other transformers that create nodes representing
 		// objects that can belong to modules.
@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform/internal/addrs"
 	"github.com/hashicorp/terraform/internal/configs"
 	"github.com/hashicorp/terraform/internal/configs/configschema"
+	"github.com/hashicorp/terraform/internal/tfdiags"
 
 	"github.com/hashicorp/terraform/internal/dag"
 )
@@ -26,6 +27,11 @@ type NodeAbstractProvider struct {
 
 	Config *configs.Provider
 	Schema *configschema.Block
+
+	// ManagedResources are the resources that have been resolved to use this
+	// provider configuration, recorded by the provider transformer so that
+	// validation can cross-check the provider configuration against them.
+	ManagedResources []addrs.ConfigResource
 }
 
 var (
@@ -34,6 +40,7 @@ var (
 	_ GraphNodeProvider                   = (*NodeAbstractProvider)(nil)
 	_ GraphNodeAttachProvider             = (*NodeAbstractProvider)(nil)
 	_ GraphNodeAttachProviderConfigSchema = (*NodeAbstractProvider)(nil)
+	_ GraphNodeRecordsManagedResources    = (*NodeAbstractProvider)(nil)
 	_ dag.GraphNodeDotter                 = (*NodeAbstractProvider)(nil)
 )
 
@@ -86,6 +93,27 @@ func (n *NodeAbstractProvider) AttachProviderConfigSchema(schema *configschema.B
 	n.Schema = schema
 }
 
+// GraphNodeRecordsManagedResources
+func (n *NodeAbstractProvider) RecordManagedResource(addr addrs.ConfigResource) {
+	for _, existing := range n.ManagedResources {
+		if existing.Equal(addr) {
+			return
+		}
+	}
+	n.ManagedResources = append(n.ManagedResources, addr)
+}
+
+// checkManagedResourceSelfRefs returns diagnostics for any references from
+// this provider's configuration to resources recorded as managed by this
+// same provider configuration. It requires both the configuration and the
+// schema to have been attached; without them there is nothing to check.
+func (n *NodeAbstractProvider) checkManagedResourceSelfRefs() tfdiags.Diagnostics {
+	if n.Config == nil || n.Schema == nil || len(n.ManagedResources) == 0 {
+		return nil
+	}
+	return validateProviderSelfRef(n.Addr, n.Config.Config, n.Schema, n.ManagedResources)
+}
+
 // GraphNodeDotter impl.
 func (n *NodeAbstractProvider) DotNode(name string, opts *dag.DotOpts) *dag.DotNode {
 	return &dag.DotNode{
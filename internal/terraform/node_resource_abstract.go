@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/hashicorp/hcl/v2"
+
 	"github.com/hashicorp/terraform/internal/addrs"
 	"github.com/hashicorp/terraform/internal/configs"
 	"github.com/hashicorp/terraform/internal/configs/configschema"
@@ -64,6 +66,17 @@ type NodeAbstractResource struct {
 	// ProviderMetas is the provider_meta configs for the module this resource belongs to
 	ProviderMetas map[addrs.Provider]*configs.ProviderMeta
 
+	// moduleLocals is the local value configs for the module this resource
+	// belongs to, used to see through one level of local-value indirection
+	// when checking for self-references.
+	moduleLocals map[string]*configs.Local
+
+	// moduleCallOutputs maps "call.output" keys to the caller-side
+	// expressions behind passthrough module outputs in this resource's
+	// module, used to catch self-references made through a module output
+	// round-trip.
+	moduleCallOutputs map[string]hcl.Expression
+
 	ProvisionerSchemas map[string]*configschema.Block
 
 	// Set from GraphNodeTargetable
@@ -99,6 +112,8 @@ var (
 	_ GraphNodeAttachResourceSchema        = (*NodeAbstractResource)(nil)
 	_ GraphNodeAttachProvisionerSchema     = (*NodeAbstractResource)(nil)
 	_ GraphNodeAttachProviderMetaConfigs   = (*NodeAbstractResource)(nil)
+	_ GraphNodeAttachModuleLocals          = (*NodeAbstractResource)(nil)
+	_ GraphNodeAttachModuleCallOutputs     = (*NodeAbstractResource)(nil)
 	_ GraphNodeTargetable                  = (*NodeAbstractResource)(nil)
 	_ graphNodeAttachDataResourceDependsOn = (*NodeAbstractResource)(nil)
 	_ dag.GraphNodeDotter                  = (*NodeAbstractResource)(nil)
@@ -379,6 +394,16 @@ func (n *NodeAbstractResource) AttachProviderMetaConfigs(c map[addrs.Provider]*c
 	n.ProviderMetas = c
 }
 
+// GraphNodeAttachModuleLocals impl
+func (n *NodeAbstractResource) AttachModuleLocals(locals map[string]*configs.Local) {
+	n.moduleLocals = locals
+}
+
+// GraphNodeAttachModuleCallOutputs impl
+func (n *NodeAbstractResource) AttachModuleCallOutputs(outputs map[string]hcl.Expression) {
+	n.moduleCallOutputs = outputs
+}
+
 // GraphNodeDotter impl.
 func (n *NodeAbstractResource) DotNode(name string, opts *dag.DotOpts) *dag.DotNode {
 	return &dag.DotNode{
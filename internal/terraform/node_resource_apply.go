@@ -4,6 +4,8 @@
 package terraform
 
 import (
+	"log"
+
 	"github.com/hashicorp/terraform/internal/addrs"
 	"github.com/hashicorp/terraform/internal/tfdiags"
 )
@@ -41,7 +43,11 @@ func (n *nodeExpandApplyableResource) References() []*addrs.Reference {
 	// filter them out for all resource node types, because the only method we
 	// have for catching certain invalid configurations are the cycles that
 	// result from these inter-instance references.
-	return filterSelfRefs(n.Addr.Resource, refs)
+	kept, filtered := filterSelfRefs(n.Addr.Resource, refs)
+	for _, ref := range filtered {
+		log.Printf("[TRACE] nodeExpandApplyableResource: dropping implied self-dependency of %s on %s at %s to prevent a cycle", n.Addr, ref.Subject, ref.SourceRange.StartString())
+	}
+	return kept
 }
 
 func (n *nodeExpandApplyableResource) Name() string {
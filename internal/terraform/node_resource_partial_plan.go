@@ -52,10 +52,12 @@ func (n *nodeExpandPlannableResource) dynamicExpandPartial(ctx EvalContext, know
 
 		// And add a node to the graph for this resource.
 		g.Add(&nodePlannablePartialExpandedResource{
-			addr:             resourceAddr,
-			config:           n.Config,
-			resolvedProvider: n.ResolvedProvider,
-			skipPlanChanges:  n.skipPlanChanges,
+			addr:              resourceAddr,
+			config:            n.Config,
+			moduleLocals:      n.moduleLocals,
+			moduleCallOutputs: n.moduleCallOutputs,
+			resolvedProvider:  n.ResolvedProvider,
+			skipPlanChanges:   n.skipPlanChanges,
 		})
 	}
 
@@ -289,10 +291,12 @@ func (n *nodeExpandPlannableResource) knownModuleSubgraph(ctx EvalContext, addr
 			// We'll add a node if there are unknown instance keys.
 			if haveUnknownKeys {
 				graph.Add(&nodePlannablePartialExpandedResource{
-					addr:             addr.Module.UnexpandedResource(addr.Resource),
-					config:           n.Config,
-					resolvedProvider: n.ResolvedProvider,
-					skipPlanChanges:  n.skipPlanChanges,
+					addr:              addr.Module.UnexpandedResource(addr.Resource),
+					config:            n.Config,
+					moduleLocals:      n.moduleLocals,
+					moduleCallOutputs: n.moduleCallOutputs,
+					resolvedProvider:  n.ResolvedProvider,
+					skipPlanChanges:   n.skipPlanChanges,
 				})
 			}
 			return nil
@@ -100,11 +100,12 @@ func (n *nodeExpandPlannableResource) DynamicExpand(ctx EvalContext) (*Graph, tf
 	var diags tfdiags.Diagnostics
 
 	// First, make sure the count and the foreach don't refer to the same
-	// resource. The config maybe nil if we are generating configuration, or
-	// deleting a resource.
+	// resource, directly or through one level of local-value indirection. The
+	// config maybe nil if we are generating configuration, or deleting a
+	// resource.
 	if n.Config != nil {
-		diags = diags.Append(validateMetaSelfRef(n.Addr.Resource, n.Config.Count))
-		diags = diags.Append(validateMetaSelfRef(n.Addr.Resource, n.Config.ForEach))
+		diags = diags.Append(validateMetaSelfRefWithLocals(n.Addr.Resource, n.Config.Count, n.moduleLocals))
+		diags = diags.Append(validateMetaSelfRefWithLocals(n.Addr.Resource, n.Config.ForEach, n.moduleLocals))
 		if diags.HasErrors() {
 			return nil, diags
 		}
@@ -93,7 +93,8 @@ func (n *NodePlannableResourceInstance) dataResourceExecute(ctx EvalContext) (di
 		return diags
 	}
 
-	diags = diags.Append(validateSelfRef(addr.Resource, config.Config, providerSchema))
+	diags = diags.Append(validateSelfRefAbsWithLocals(addr, config.Config, providerSchema, n.moduleLocals))
+	diags = diags.Append(validateSelfRefThroughOutputs(addr.Resource, config.Config, providerSchema, n.moduleCallOutputs))
 	if diags.HasErrors() {
 		return diags
 	}
@@ -166,7 +167,13 @@ func (n *NodePlannableResourceInstance) managedResourceExecute(ctx EvalContext)
 	}
 
 	if config != nil {
-		diags = diags.Append(validateSelfRef(addr.Resource, config.Config, providerSchema))
+		diags = diags.Append(validateSelfRefAbsWithLocals(addr, config.Config, providerSchema, n.moduleLocals))
+		diags = diags.Append(validateSelfRefThroughOutputs(addr.Resource, config.Config, providerSchema, n.moduleCallOutputs))
+		// replace_triggered_by is decoded out of the body before it reaches
+		// the checks above, and follows relaxed rules of its own.
+		for _, expr := range config.TriggersReplacement {
+			diags = diags.Append(validateLifecycleSelfRef(addr.Resource, expr))
+		}
 		if diags.HasErrors() {
 			return diags
 		}
@@ -5,6 +5,7 @@ package terraform
 
 import (
 	"fmt"
+	"github.com/hashicorp/hcl/v2"
 	"log"
 	"strings"
 
@@ -30,10 +31,12 @@ import (
 //
 // This is the partial-expanded equivalent of NodePlannableResourceInstance.
 type nodePlannablePartialExpandedResource struct {
-	addr             addrs.PartialExpandedResource
-	config           *configs.Resource
-	resolvedProvider addrs.AbsProviderConfig
-	skipPlanChanges  bool
+	addr              addrs.PartialExpandedResource
+	config            *configs.Resource
+	moduleLocals      map[string]*configs.Local
+	moduleCallOutputs map[string]hcl.Expression
+	resolvedProvider  addrs.AbsProviderConfig
+	skipPlanChanges   bool
 }
 
 var (
@@ -139,7 +142,8 @@ func (n *nodePlannablePartialExpandedResource) managedResourceExecute(ctx EvalCo
 		return &change, diags
 	}
 
-	diags = diags.Append(validateSelfRef(n.addr.Resource(), n.config.Config, providerSchema))
+	diags = diags.Append(validateSelfRefWithLocals(n.addr.Resource(), n.config.Config, providerSchema, n.moduleLocals))
+	diags = diags.Append(validateSelfRefThroughOutputs(n.addr.Resource(), n.config.Config, providerSchema, n.moduleCallOutputs))
 	if diags.HasErrors() {
 		return &change, diags
 	}
@@ -309,7 +313,8 @@ func (n *nodePlannablePartialExpandedResource) dataResourceExecute(ctx EvalConte
 		return &change, diags
 	}
 
-	diags = diags.Append(validateSelfRef(n.addr.Resource(), n.config.Config, providerSchema))
+	diags = diags.Append(validateSelfRefWithLocals(n.addr.Resource(), n.config.Config, providerSchema, n.moduleLocals))
+	diags = diags.Append(validateSelfRefThroughOutputs(n.addr.Resource(), n.config.Config, providerSchema, n.moduleCallOutputs))
 	if diags.HasErrors() {
 		return &change, diags
 	}
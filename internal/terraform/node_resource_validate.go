@@ -124,11 +124,12 @@ func (n *NodeValidatableResource) validateProvisioner(ctx EvalContext, p *config
 
 		_, _, connDiags := n.evaluateBlock(ctx, cfg, connectionBlockSupersetSchema)
 		diags = diags.Append(connDiags)
+		diags = diags.Append(validateProvisionerConnSelfRef(n.Addr.Resource, cfg, n.Schema))
 	} else if baseConn != nil {
 		// Just validate the baseConn directly.
 		_, _, connDiags := n.evaluateBlock(ctx, baseConn.Config, connectionBlockSupersetSchema)
 		diags = diags.Append(connDiags)
-
+		diags = diags.Append(validateProvisionerConnSelfRef(n.Addr.Resource, baseConn.Config, n.Schema))
 	}
 	return diags
 }
@@ -532,7 +533,22 @@ func (n *NodeValidatableResource) validateCheckRules(ctx EvalContext, config *co
 
 	keyData, selfAddr := n.stubRepetitionData(n.Config.Count != nil, n.Config.ForEach != nil)
 
+	// A data source's conditions may not refer to the data source's own
+	// result by address, since the data source can't be read until its
+	// conditions allow it; "self" is the supported way to test the result
+	// in a postcondition. We catch this here with a tailored diagnostic
+	// rather than letting it surface later as an inscrutable dependency
+	// cycle.
+	checkDataSelfRef := func(expr hcl.Expression) {
+		if config.Mode == addrs.DataResourceMode {
+			diags = diags.Append(validateDataConditionSelfRef(n.ResourceAddr().Resource, expr))
+		}
+	}
+
 	for _, cr := range config.Preconditions {
+		checkDataSelfRef(cr.Condition)
+		checkDataSelfRef(cr.ErrorMessage)
+
 		_, conditionDiags := n.evaluateExpr(ctx, cr.Condition, cty.Bool, nil, keyData)
 		diags = diags.Append(conditionDiags)
 
@@ -541,6 +557,9 @@ func (n *NodeValidatableResource) validateCheckRules(ctx EvalContext, config *co
 	}
 
 	for _, cr := range config.Postconditions {
+		checkDataSelfRef(cr.Condition)
+		checkDataSelfRef(cr.ErrorMessage)
+
 		_, conditionDiags := n.evaluateExpr(ctx, cr.Condition, cty.Bool, selfAddr, keyData)
 		diags = diags.Append(conditionDiags)
 
@@ -0,0 +1,13 @@
+variable "in" {
+}
+
+variable "literal" {
+}
+
+output "result" {
+  value = var.in
+}
+
+output "constant" {
+  value = "fixed"
+}
@@ -0,0 +1,10 @@
+resource "aws_instance" "foo" {
+  foo = module.m.result
+}
+
+module "m" {
+  source = "./child"
+
+  in      = aws_instance.foo.id
+  literal = "hello"
+}
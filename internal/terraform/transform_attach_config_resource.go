@@ -6,9 +6,12 @@ package terraform
 import (
 	"log"
 
+	"github.com/hashicorp/hcl/v2"
+
 	"github.com/hashicorp/terraform/internal/addrs"
 	"github.com/hashicorp/terraform/internal/configs"
 	"github.com/hashicorp/terraform/internal/dag"
+	"github.com/hashicorp/terraform/internal/lang/langrefs"
 )
 
 // GraphNodeAttachResourceConfig is an interface that must be implemented by nodes
@@ -23,6 +26,26 @@ type GraphNodeAttachResourceConfig interface {
 	AttachResourceConfig(*configs.Resource, *configs.Removed)
 }
 
+// GraphNodeAttachModuleLocals is an interface for nodes that also want the
+// local value configurations of their module attached, so that validation can
+// see through one level of local-value indirection when checking for
+// self-references.
+type GraphNodeAttachModuleLocals interface {
+	GraphNodeConfigResource
+
+	AttachModuleLocals(map[string]*configs.Local)
+}
+
+// GraphNodeAttachModuleCallOutputs is an interface for nodes that also want
+// the passthrough module call outputs of their module attached, so that
+// validation can catch a self-reference made through a module output
+// round-trip.
+type GraphNodeAttachModuleCallOutputs interface {
+	GraphNodeConfigResource
+
+	AttachModuleCallOutputs(map[string]hcl.Expression)
+}
+
 // AttachResourceConfigTransformer goes through the graph and attaches
 // resource configuration structures to nodes that implement
 // GraphNodeAttachManagedResourceConfig or GraphNodeAttachDataResourceConfig.
@@ -35,6 +58,10 @@ type AttachResourceConfigTransformer struct {
 
 func (t *AttachResourceConfigTransformer) Transform(g *Graph) error {
 
+	// Passthrough output maps are built at most once per module, since many
+	// resource nodes typically share a module.
+	passthroughs := make(map[string]map[string]hcl.Expression)
+
 	// Go through and find GraphNodeAttachResource
 	for _, v := range g.Vertices() {
 		// Only care about GraphNodeAttachResource implementations
@@ -64,6 +91,20 @@ func (t *AttachResourceConfigTransformer) Transform(g *Graph) error {
 					log.Printf("[TRACE] AttachResourceConfigTransformer: no provider meta configs available to attach to %s", dag.VertexName(v))
 				}
 			}
+			if gnaml, ok := v.(GraphNodeAttachModuleLocals); ok {
+				log.Printf("[TRACE] AttachResourceConfigTransformer: attaching module locals to %s", dag.VertexName(v))
+				gnaml.AttachModuleLocals(config.Module.Locals)
+			}
+			if gnamco, ok := v.(GraphNodeAttachModuleCallOutputs); ok {
+				key := addr.Module.String()
+				outputs, ok := passthroughs[key]
+				if !ok {
+					outputs = moduleCallOutputPassthroughs(config)
+					passthroughs[key] = outputs
+				}
+				log.Printf("[TRACE] AttachResourceConfigTransformer: attaching module call outputs to %s", dag.VertexName(v))
+				gnamco.AttachModuleCallOutputs(outputs)
+			}
 		}
 
 		for _, r := range config.Module.Removed {
@@ -90,3 +131,46 @@ func (t *AttachResourceConfigTransformer) Transform(g *Graph) error {
 
 	return nil
 }
+
+// moduleCallOutputPassthroughs maps "call.output" keys to the expression in
+// the calling module that each output effectively returns, for outputs of the
+// given module's calls that are simple passthroughs of a single input
+// variable. Such an output round-trips a caller-side value unchanged, so a
+// resource referring to it is really referring to whatever the call passed
+// in; the returned expressions let validation compare that against the
+// resource itself. Outputs with any other shape are omitted, since their
+// values genuinely originate in the child module.
+func moduleCallOutputPassthroughs(cfg *configs.Config) map[string]hcl.Expression {
+	var ret map[string]hcl.Expression
+	for name, child := range cfg.Children {
+		call := cfg.Module.ModuleCalls[name]
+		if call == nil {
+			continue
+		}
+
+		// The module call arguments, for substituting into passthrough
+		// outputs. Any non-attribute content just produces errors we can
+		// ignore, since arguments are always attributes.
+		attrs, _ := call.Config.JustAttributes()
+
+		for outName, out := range child.Module.Outputs {
+			refs, _ := langrefs.ReferencesInExpr(addrs.ParseRef, out.Expr)
+			if len(refs) != 1 {
+				continue
+			}
+			varAddr, ok := refs[0].Subject.(addrs.InputVariable)
+			if !ok {
+				continue
+			}
+			attr, ok := attrs[varAddr.Name]
+			if !ok {
+				continue
+			}
+			if ret == nil {
+				ret = make(map[string]hcl.Expression)
+			}
+			ret[name+"."+outName] = attr.Expr
+		}
+	}
+	return ret
+}
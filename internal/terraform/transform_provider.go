@@ -87,6 +87,14 @@ type GraphNodeProviderConsumer interface {
 	SetProvider(addrs.AbsProviderConfig)
 }
 
+// GraphNodeRecordsManagedResources is an interface for provider nodes that
+// want to be told which resources have been resolved to use them, so that
+// provider validation can cross-check the provider configuration against
+// those resources.
+type GraphNodeRecordsManagedResources interface {
+	RecordManagedResource(addrs.ConfigResource)
+}
+
 // ProviderTransformer is a GraphTransformer that maps resources to providers
 // within the graph. This will error if there are any resources that don't map
 // to proper resources.
@@ -241,6 +249,11 @@ func (t *ProviderTransformer) Transform(g *Graph) error {
 			if pv, ok := v.(GraphNodeProviderConsumer); ok {
 				pv.SetProvider(target.ProviderAddr())
 			}
+			if rec, ok := target.(GraphNodeRecordsManagedResources); ok {
+				if rn, ok := v.(GraphNodeConfigResource); ok {
+					rec.RecordManagedResource(rn.ResourceAddr())
+				}
+			}
 			g.Connect(dag.BasicEdge(v, target))
 		}
 	}
@@ -248,6 +261,28 @@ func (t *ProviderTransformer) Transform(g *Graph) error {
 	return diags.Err()
 }
 
+// providerSelfRefTransformer checks each configured provider in the graph
+// for references to resources that were resolved to use that same provider
+// configuration. It must run after providers have been resolved and their
+// schemas attached, but before reference edges are added, because such a
+// reference would otherwise only surface as an opaque dependency cycle error
+// from the graph builder rather than an actionable diagnostic.
+type providerSelfRefTransformer struct{}
+
+type graphNodeProviderSelfRefCheck interface {
+	checkManagedResourceSelfRefs() tfdiags.Diagnostics
+}
+
+func (t *providerSelfRefTransformer) Transform(g *Graph) error {
+	var diags tfdiags.Diagnostics
+	for _, v := range g.Vertices() {
+		if p, ok := v.(graphNodeProviderSelfRefCheck); ok {
+			diags = diags.Append(p.checkManagedResourceSelfRefs())
+		}
+	}
+	return diags.Err()
+}
+
 // CloseProviderTransformer is a GraphTransformer that adds nodes to the
 // graph that will close open provider connections that aren't needed anymore.
 // A provider connection is not needed anymore once all depended resources
@@ -5,19 +5,217 @@ package terraform
 
 import (
 	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
 
 	"github.com/hashicorp/terraform/internal/addrs"
+	"github.com/hashicorp/terraform/internal/configs"
 	"github.com/hashicorp/terraform/internal/configs/configschema"
 	"github.com/hashicorp/terraform/internal/lang/langrefs"
 	"github.com/hashicorp/terraform/internal/providers"
 	"github.com/hashicorp/terraform/internal/tfdiags"
 )
 
+// SelfRefRule describes one of the self-reference rules enforced by the
+// validate functions in this file, as structured data for consumption by
+// documentation generators and other tooling.
+type SelfRefRule struct {
+	// Name is a stable identifier for the rule, suitable for use as an
+	// anchor or key in generated documentation.
+	Name string
+
+	// Context describes where in the configuration the rule applies.
+	Context string
+
+	// Description is an English-language summary of what the rule forbids.
+	Description string
+
+	// Summary is the diagnostic summary emitted when the rule is violated.
+	Summary string
+}
+
+// SelfRefRules returns the full set of self-reference rules that validation
+// enforces. The result is freshly allocated on each call, so callers may
+// modify it freely.
+//
+// This must be kept in agreement with the validate functions below; each
+// distinct diagnostic those functions can produce has a corresponding rule
+// here.
+func SelfRefRules() []SelfRefRule {
+	return []SelfRefRule{
+		{
+			Name:        "resource-config-self-reference",
+			Context:     "resource and data block configuration",
+			Description: "Expressions within a resource block may not refer to that same resource, and expressions within a resource instance may not refer to that instance or its containing resource. A reference indexed so as to provably select a different instance, such as with count.index - 1, names a sibling instance and is permitted.",
+			Summary:     "Self-referential block",
+		},
+		{
+			Name:        "write-only-self-reference",
+			Context:     "resource and data block configuration",
+			Description: "A self-reference that selects a write-only attribute is reported specially, since the value of such an attribute is never retained and so cannot be referenced at all.",
+			Summary:     "Reference to write-only attribute",
+		},
+		{
+			Name:        "local-value-self-reference",
+			Context:     "resource and data block configuration",
+			Description: "Expressions within a resource block may not refer to a local value whose own definition refers back to that resource, since that is a self-reference through one level of indirection.",
+			Summary:     "Self-referential local value",
+		},
+		{
+			Name:        "module-output-self-reference",
+			Context:     "resource and data block configuration",
+			Description: "Expressions within a resource block may not refer to a module output that simply passes back a value derived from that resource, since that is a self-reference through the module call.",
+			Summary:     "Self-referential module output",
+		},
+		{
+			Name:        "provisioner-connection-self-reference",
+			Context:     "provisioner connection blocks",
+			Description: "A connection block may refer to the configured arguments of the resource it is attached to, but not to its computed attributes, whose values are only known once the resource has been fully applied; the self object is the supported way to use those.",
+			Summary:     "Self-referential connection configuration",
+		},
+		{
+			Name:        "lifecycle-replace-triggered-by-self-reference",
+			Context:     "lifecycle replace_triggered_by argument",
+			Description: "A replace_triggered_by expression may refer to sibling instances of its own resource, but not to the exact instance it is attached to, which would trigger a replacement from the instance's own change.",
+			Summary:     "Self-referential replace_triggered_by",
+		},
+		{
+			Name:        "provider-config-self-reference",
+			Context:     "provider block configuration",
+			Description: "A provider configuration may not refer to a resource that is managed by that same provider configuration, since the provider must be configured before it can manage any resources.",
+			Summary:     "Self-referential provider configuration",
+		},
+		{
+			Name:        "meta-argument-self-reference",
+			Context:     "count and for_each meta-arguments",
+			Description: "The count and for_each expressions of a resource may not refer to the resource they are attached to, including any of its instances.",
+			Summary:     "Self-referential block",
+		},
+		{
+			Name:        "data-condition-self-reference",
+			Context:     "data source precondition and postcondition blocks",
+			Description: "The precondition and postcondition expressions of a data source may not refer to that data source's own result, including any of its instances; the self object is the supported way to test the result in a postcondition.",
+			Summary:     "Self-referential data resource condition",
+		},
+		{
+			Name:        "import-id-self-reference",
+			Context:     "import block id argument",
+			Description: "The id expression of an import block may not refer to the resource being imported, including any of its instances.",
+			Summary:     "Invalid import id argument",
+		},
+	}
+}
+
 // validateSelfRef checks to ensure that expressions within a particular
-// referencable block do not reference that same block.
+// referencable block do not reference that same block. The reference walk
+// descends into dynamic block content and for_each expressions too, with the
+// iterator variable scoped out, so a self-reference hidden inside a dynamic
+// block is reported at the expression that makes it.
+//
+// A missing schema for the given address is treated as a bug in Terraform;
+// use validateSelfRefLenient instead where schemas are legitimately allowed
+// to be absent.
 func validateSelfRef(addr addrs.Referenceable, config hcl.Body, providerSchema providers.ProviderSchema) tfdiags.Diagnostics {
+	return validateSelfRefInner(addr, config, providerSchema, false, nil)
+}
+
+// resourceToValidate pairs a resource address with the configuration body to
+// check it against, as input to validateSelfRefs.
+type resourceToValidate struct {
+	Addr   addrs.Referenceable
+	Config hcl.Body
+}
+
+// validateSelfRefs runs the validateSelfRef check for each given resource,
+// fanning the per-resource work out across a bounded number of goroutines.
+// The per-resource check is pure given its inputs, so running them
+// concurrently is safe; what needs care is the output, which is merged and
+// then ordered by source range so that the combined diagnostics are stable
+// regardless of goroutine scheduling. Diagnostics without a source range
+// sort first, keeping their relative order.
+func validateSelfRefs(resources []resourceToValidate, providerSchema providers.ProviderSchema) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if len(resources) == 0 {
+		return diags
+	}
+
+	results := make([]tfdiags.Diagnostics, len(resources))
+	sem := make(chan struct{}, runtime.NumCPU())
+	var wg sync.WaitGroup
+	for i := range resources {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = validateSelfRef(resources[i].Addr, resources[i].Config, providerSchema)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, result := range results {
+		diags = diags.Append(result)
+	}
+	sort.SliceStable(diags, func(i, j int) bool {
+		return diagSourceRangeLess(diags[i], diags[j])
+	})
+	return diags
+}
+
+// diagSourceRangeLess orders diagnostics by the position of their subject
+// range: filename first, then start line and column. Diagnostics without a
+// subject range sort before those with one.
+func diagSourceRangeLess(a, b tfdiags.Diagnostic) bool {
+	aSubject, bSubject := a.Source().Subject, b.Source().Subject
+	switch {
+	case aSubject == nil && bSubject == nil:
+		return false
+	case aSubject == nil:
+		return true
+	case bSubject == nil:
+		return false
+	}
+	if aSubject.Filename != bSubject.Filename {
+		return aSubject.Filename < bSubject.Filename
+	}
+	if aSubject.Start.Line != bSubject.Start.Line {
+		return aSubject.Start.Line < bSubject.Start.Line
+	}
+	return aSubject.Start.Column < bSubject.Start.Column
+}
+
+// validateSelfRefWithLocals is like validateSelfRef but additionally resolves
+// one level of local-value indirection using the given local value
+// configurations, so that a resource referring to itself through a local
+// (e.g. local.x where local.x is derived from the resource) is caught here
+// with a direct explanation rather than surfacing later as a confusing
+// dependency cycle. Locals that refer to other resources are unaffected.
+func validateSelfRefWithLocals(addr addrs.Referenceable, config hcl.Body, providerSchema providers.ProviderSchema, locals map[string]*configs.Local) tfdiags.Diagnostics {
+	return validateSelfRefInner(addr, config, providerSchema, false, locals)
+}
+
+// validateSelfRefLenient is like validateSelfRef except that a missing schema
+// for the given address produces a warning and skips the self-reference
+// check, rather than reporting an internal bug. This is for operations where
+// provider schemas may be only partially available, so that an absent schema
+// is expected rather than a sign of something wrong.
+func validateSelfRefLenient(addr addrs.Referenceable, config hcl.Body, providerSchema providers.ProviderSchema) tfdiags.Diagnostics {
+	return validateSelfRefInner(addr, config, providerSchema, true, nil)
+}
+
+// validateSelfRefInner is the common implementation of validateSelfRef and
+// its variants; the lenient flag selects how a missing schema is reported,
+// and locals, when non-nil, enables the one-level local-value indirection
+// check.
+func validateSelfRefInner(addr addrs.Referenceable, config hcl.Body, providerSchema providers.ProviderSchema, lenient bool, locals map[string]*configs.Local) tfdiags.Diagnostics {
 	var diags tfdiags.Diagnostics
 
 	addrStrs := make([]string, 0, 1)
@@ -28,6 +226,9 @@ func validateSelfRef(addr addrs.Referenceable, config hcl.Body, providerSchema p
 		addrStrs = append(addrStrs, tAddr.ContainingResource().String())
 	}
 
+	// SchemaForResourceAddr resolves by the address's mode, so a data
+	// resource address finds the data source schema here and gets the same
+	// diagnostics as a managed resource rather than a missing-schema error.
 	var schema *configschema.Block
 	switch tAddr := addr.(type) {
 	case addrs.Resource:
@@ -36,19 +237,293 @@ func validateSelfRef(addr addrs.Referenceable, config hcl.Body, providerSchema p
 		schema, _ = providerSchema.SchemaForResourceAddr(tAddr.ContainingResource())
 	}
 
+	if schema == nil {
+		if lenient {
+			log.Printf("[WARN] validateSelfRef: no schema available for %s, so only its top-level arguments can be checked for self-references", addr)
+			diags = diags.Append(tfdiags.SimpleWarning(fmt.Sprintf("No schema is available for %s, so Terraform cannot fully check whether its configuration refers to itself; only its top-level arguments were checked", addr)))
+			// Even without a schema we can still parse the block's immediate
+			// attributes directly, so a self-reference in one of those is
+			// reported rather than masked by the schema being unavailable.
+			diags = diags.Append(selfRefsInRawBody(addrStrs, config))
+			return diags
+		}
+		diags = diags.Append(fmt.Errorf("no schema available for %s to validate for self-references; this is a bug in Terraform and should be reported", addr))
+		return diags
+	}
+
+	refs, _ := langrefs.ReferencesInBlock(addrs.ParseRef, config, schema)
+
+	// A reference that is immediately indexed by an expression provably
+	// selecting a different instance, such as aws_instance.foo[count.index -
+	// 1], names a sibling of the current instance rather than the instance
+	// itself, so it is not reported as a self-reference.
+	exempt := selfIndexExemptRanges(addrStrs, config)
+
+	for _, addrStr := range addrStrs {
+		// Plain self-references are consolidated into one diagnostic per
+		// address rather than one per reference, so that a block with many
+		// self-referencing attributes doesn't flood the output. Write-only
+		// references keep their own diagnostic, since those carry an extra
+		// explanation of their own.
+		var plain []*addrs.Reference
+		for _, ref := range refs {
+			if ref.Subject.String() != addrStr {
+				continue
+			}
+			if selfRefAllowedByAnnotation(ref) {
+				continue
+			}
+			if _, ok := exempt[ref.SourceRange.ToHCL()]; ok {
+				continue
+			}
+			if attrName := firstAttrStep(ref.Remaining); attrName != "" {
+				if attr, ok := schema.Attributes[attrName]; ok && attr.WriteOnly {
+					diags = diags.Append(selfRefDiagnostic(addrStr, ref, schema))
+					continue
+				}
+			}
+			plain = append(plain, ref)
+		}
+		if diag := groupedSelfRefDiagnostic(addrStr, plain, schema); diag != nil {
+			diags = diags.Append(diag)
+		}
+	}
+
+	// With the module's local values in hand we can also catch a
+	// self-reference hiding one level behind a local value, which would
+	// otherwise only surface later as a dependency cycle.
+	for _, ref := range refs {
+		localAddr, ok := ref.Subject.(addrs.LocalValue)
+		if !ok {
+			continue
+		}
+		local := locals[localAddr.Name]
+		if local == nil {
+			continue
+		}
+		localRefs, _ := langrefs.ReferencesInExpr(addrs.ParseRef, local.Expr)
+	Local:
+		for _, localRef := range localRefs {
+			for _, addrStr := range addrStrs {
+				if localRef.Subject.String() != addrStr {
+					continue
+				}
+				diags = diags.Append(localSelfRefDiagnostic(addrStr, localAddr.Name, ref))
+				break Local
+			}
+		}
+	}
+
+	return diags
+}
+
+// validateSelfRefAbs is like validateSelfRef but for callers that know the
+// full module instance address of the resource instance being validated, such
+// as a resource inside a module expanded with count or for_each. References
+// in configuration are always module-relative, so each candidate reference is
+// qualified with the resource's own module instance before comparing against
+// the resource's full address; this keeps the instance-key comparison correct
+// when both the module and the resource have instance keys, and reports the
+// full address in the diagnostics.
+func validateSelfRefAbs(addr addrs.AbsResourceInstance, config hcl.Body, providerSchema providers.ProviderSchema) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	// A resource instance may not refer to its containing resource either.
+	addrStrs := []string{
+		addr.String(),
+		addr.ContainingResource().String(),
+	}
+
+	schema, _ := providerSchema.SchemaForResourceAddr(addr.Resource.ContainingResource())
 	if schema == nil {
 		diags = diags.Append(fmt.Errorf("no schema available for %s to validate for self-references; this is a bug in Terraform and should be reported", addr))
 		return diags
 	}
 
 	refs, _ := langrefs.ReferencesInBlock(addrs.ParseRef, config, schema)
+
+	// References in configuration are module-relative, so the sibling-index
+	// exemption compares against the resource's module-relative address.
+	exempt := selfIndexExemptRanges([]string{addr.Resource.ContainingResource().String()}, config)
+
 	for _, ref := range refs {
+		var refAbsStr string
+		switch subject := ref.Subject.(type) {
+		case addrs.Resource:
+			refAbsStr = subject.Absolute(addr.Module).String()
+		case addrs.ResourceInstance:
+			refAbsStr = subject.Absolute(addr.Module).String()
+		default:
+			// Anything else cannot be a self-reference.
+			continue
+		}
+
 		for _, addrStr := range addrStrs {
-			if ref.Subject.String() == addrStr {
+			if refAbsStr != addrStr {
+				continue
+			}
+			if selfRefAllowedByAnnotation(ref) {
+				continue
+			}
+			if _, ok := exempt[ref.SourceRange.ToHCL()]; ok {
+				continue
+			}
+			diags = diags.Append(selfRefDiagnostic(addrStr, ref, schema))
+		}
+	}
+
+	return diags
+}
+
+// validateSelfRefAbsWithLocals is like validateSelfRefAbs but additionally
+// resolves one level of local-value indirection using the given local value
+// configurations, in the same way as validateSelfRefWithLocals. Each resource
+// reference found in a local's definition is qualified with the resource's own
+// module instance before comparing, as in validateSelfRefAbs.
+func validateSelfRefAbsWithLocals(addr addrs.AbsResourceInstance, config hcl.Body, providerSchema providers.ProviderSchema, locals map[string]*configs.Local) tfdiags.Diagnostics {
+	diags := validateSelfRefAbs(addr, config, providerSchema)
+	if len(locals) == 0 {
+		return diags
+	}
+
+	schema, _ := providerSchema.SchemaForResourceAddr(addr.Resource.ContainingResource())
+	if schema == nil {
+		// validateSelfRefAbs already reported this.
+		return diags
+	}
+
+	addrStrs := []string{
+		addr.String(),
+		addr.ContainingResource().String(),
+	}
+
+	refs, _ := langrefs.ReferencesInBlock(addrs.ParseRef, config, schema)
+	for _, ref := range refs {
+		localAddr, ok := ref.Subject.(addrs.LocalValue)
+		if !ok {
+			continue
+		}
+		local := locals[localAddr.Name]
+		if local == nil {
+			continue
+		}
+		localRefs, _ := langrefs.ReferencesInExpr(addrs.ParseRef, local.Expr)
+	Local:
+		for _, localRef := range localRefs {
+			var refAbsStr string
+			switch subject := localRef.Subject.(type) {
+			case addrs.Resource:
+				refAbsStr = subject.Absolute(addr.Module).String()
+			case addrs.ResourceInstance:
+				refAbsStr = subject.Absolute(addr.Module).String()
+			default:
+				// Anything else cannot be a self-reference.
+				continue
+			}
+
+			for _, addrStr := range addrStrs {
+				if refAbsStr != addrStr {
+					continue
+				}
+				diags = diags.Append(localSelfRefDiagnostic(addrStr, localAddr.Name, ref))
+				break Local
+			}
+		}
+	}
+
+	return diags
+}
+
+// validateSelfRefThroughOutputs checks for self-references made through a
+// module output round-trip: the resource refers to a module call output whose
+// value is just a passthrough of something derived from that same resource.
+// outputs maps "call.output" keys to the calling-module-relative expression
+// each output effectively returns, as built by moduleCallOutputPassthroughs;
+// outputs that are not simple passthroughs are not represented there and so
+// are never flagged, since their values genuinely originate in the child
+// module.
+func validateSelfRefThroughOutputs(addr addrs.Referenceable, config hcl.Body, providerSchema providers.ProviderSchema, outputs map[string]hcl.Expression) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if len(outputs) == 0 {
+		return diags
+	}
+
+	addrStrs := make([]string, 0, 1)
+	addrStrs = append(addrStrs, addr.String())
+	switch tAddr := addr.(type) {
+	case addrs.ResourceInstance:
+		// A resource instance may not refer to its containing resource either.
+		addrStrs = append(addrStrs, tAddr.ContainingResource().String())
+	}
+
+	var schema *configschema.Block
+	switch tAddr := addr.(type) {
+	case addrs.Resource:
+		schema, _ = providerSchema.SchemaForResourceAddr(tAddr)
+	case addrs.ResourceInstance:
+		schema, _ = providerSchema.SchemaForResourceAddr(tAddr.ContainingResource())
+	}
+	if schema == nil {
+		// The schema-aware checks report a missing schema; there's nothing
+		// further to add here.
+		return diags
+	}
+
+	refs, _ := langrefs.ReferencesInBlock(addrs.ParseRef, config, schema)
+	for _, ref := range refs {
+		outputAddr, ok := ref.Subject.(addrs.ModuleCallInstanceOutput)
+		if !ok {
+			continue
+		}
+		expr, ok := outputs[outputAddr.Call.Call.Name+"."+outputAddr.Name]
+		if !ok {
+			continue
+		}
+		outputRefs, _ := langrefs.ReferencesInExpr(addrs.ParseRef, expr)
+	Output:
+		for _, outputRef := range outputRefs {
+			for _, addrStr := range addrStrs {
+				if outputRef.Subject.String() != addrStr {
+					continue
+				}
 				diags = diags.Append(&hcl.Diagnostic{
 					Severity: hcl.DiagError,
+					Summary:  "Self-referential module output",
+					Detail:   fmt.Sprintf("Configuration for %s may not refer to itself. The output %q of module %q passes back a value derived from %s, so referring to it here is an indirect self-reference.", addrStr, outputAddr.Name, outputAddr.Call.Call.Name, addrStr),
+					Subject:  ref.SourceRange.ToHCL().Ptr(),
+				})
+				break Output
+			}
+		}
+	}
+
+	return diags
+}
+
+// selfRefsInRawBody is a best-effort self-reference check for when no schema
+// is available to walk the configuration body with: the body's immediate
+// attributes are parsed directly and compared against the given self
+// addresses. Without a schema there is no way to descend into nested blocks
+// and no write-only attribute reporting, so this catches less than the
+// schema-aware check, but a self-reference it does find is just as real.
+func selfRefsInRawBody(addrStrs []string, config hcl.Body) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	// Any blocks in the body make JustAttributes return errors alongside the
+	// attributes it could extract; those errors are dropped here since this
+	// check is best-effort by design.
+	attrs, _ := config.JustAttributes()
+	for _, attr := range attrs {
+		refs, _ := langrefs.ReferencesInExpr(addrs.ParseRef, attr.Expr)
+		for _, ref := range refs {
+			for _, addrStr := range addrStrs {
+				if ref.Subject.String() != addrStr {
+					continue
+				}
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: selfRefSeverity(),
 					Summary:  "Self-referential block",
-					Detail:   fmt.Sprintf("Configuration for %s may not refer to itself.", addrStr),
+					Detail:   fmt.Sprintf("Configuration for %s may not refer to itself. The reference to %s here is a self-reference.", addrStr, ref.DisplayString()),
 					Subject:  ref.SourceRange.ToHCL().Ptr(),
 				})
 			}
@@ -58,6 +533,284 @@ func validateSelfRef(addr addrs.Referenceable, config hcl.Body, providerSchema p
 	return diags
 }
 
+// localSelfRefDiagnostic returns the diagnostic for a reference to a local
+// value whose own definition refers back to the block with the given display
+// address. The diagnostic points at the reference inside the block rather
+// than at the local value's definition, since the block is where the cycle is
+// introduced.
+func localSelfRefDiagnostic(displayAddr, localName string, ref *addrs.Reference) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Self-referential local value",
+		Detail:   fmt.Sprintf("Configuration for %s may not refer to itself: local.%s is derived from %s, so referring to it here is an indirect self-reference.", displayAddr, localName, displayAddr),
+		Subject:  ref.SourceRange.ToHCL().Ptr(),
+	}
+}
+
+// groupedSelfRefDiagnostic returns a single diagnostic covering every plain
+// self-reference to the block with the given display address. One reference
+// gets the usual per-reference diagnostic; several are consolidated into one
+// diagnostic pointing at the first reference and counting the rest, so that
+// a block with many self-referencing attributes doesn't produce a wall of
+// identical diagnostics. Returns nil when there are no references to report.
+func groupedSelfRefDiagnostic(displayAddr string, refs []*addrs.Reference, schema *configschema.Block) *hcl.Diagnostic {
+	switch len(refs) {
+	case 0:
+		return nil
+	case 1:
+		return selfRefDiagnostic(displayAddr, refs[0], schema)
+	}
+
+	return &hcl.Diagnostic{
+		Severity: selfRefSeverity(),
+		Summary:  "Self-referential block",
+		Detail:   fmt.Sprintf("Configuration for %s may not refer to itself. The reference to %s here is a self-reference, and %d more references elsewhere in this block also refer to %s.", displayAddr, refs[0].DisplayString(), len(refs)-1, displayAddr),
+		Subject:  refs[0].SourceRange.ToHCL().Ptr(),
+	}
+}
+
+// selfRefDiagnostic returns the diagnostic for a self-reference to the block
+// with the given display address. A self-reference to a write-only attribute
+// is doubly invalid: even without the self-reference, the attribute's value
+// can never be read back, so we explain that rather than only complaining
+// about the self-reference.
+func selfRefDiagnostic(displayAddr string, ref *addrs.Reference, schema *configschema.Block) *hcl.Diagnostic {
+	if attrName := firstAttrStep(ref.Remaining); attrName != "" {
+		if attr, ok := schema.Attributes[attrName]; ok && attr.WriteOnly {
+			return &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Reference to write-only attribute",
+				Detail:   fmt.Sprintf("Configuration for %s may not refer to itself, and %q is a write-only attribute whose value is never retained, so it cannot be referenced at all.", displayAddr, attrName),
+				Subject:  ref.SourceRange.ToHCL().Ptr(),
+			}
+		}
+	}
+
+	return &hcl.Diagnostic{
+		Severity: selfRefSeverity(),
+		Summary:  "Self-referential block",
+		Detail:   fmt.Sprintf("Configuration for %s may not refer to itself. The reference to %s here is a self-reference.", displayAddr, ref.DisplayString()),
+		Subject:  ref.SourceRange.ToHCL().Ptr(),
+	}
+}
+
+// validateProviderSelfRef checks the given provider configuration body for
+// references to resources that are themselves managed by that provider
+// configuration. Such a reference is a configuration-time cycle: the provider
+// must be configured before it can manage any resources, so its configuration
+// can never depend on one of them. A common form of this mistake is wiring a
+// provider-managed IAM role's ARN into that same provider's assume_role
+// block. The managed set is recorded on the provider node while resources are
+// being resolved to their providers.
+func validateProviderSelfRef(providerAddr addrs.AbsProviderConfig, config hcl.Body, schema *configschema.Block, managed []addrs.ConfigResource) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	if len(managed) == 0 {
+		return diags
+	}
+
+	managedStrs := make(map[string]struct{}, len(managed))
+	for _, rAddr := range managed {
+		managedStrs[rAddr.String()] = struct{}{}
+	}
+
+	refs, _ := langrefs.ReferencesInBlock(addrs.ParseRef, config, schema)
+	for _, ref := range refs {
+		var rAddr addrs.Resource
+		switch subject := ref.Subject.(type) {
+		case addrs.Resource:
+			rAddr = subject
+		case addrs.ResourceInstance:
+			rAddr = subject.ContainingResource()
+		default:
+			continue
+		}
+
+		// References in a provider block can only name resources in the
+		// provider's own module, so qualifying with the provider's module
+		// path gives the config address to compare against the managed set.
+		cAddr := rAddr.InModule(providerAddr.Module)
+		if _, ok := managedStrs[cAddr.String()]; !ok {
+			continue
+		}
+
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Self-referential provider configuration",
+			Detail:   fmt.Sprintf("The configuration for %s refers to %s, which is managed by that same provider configuration. The provider must be configured before it can manage resources, so this reference is a self-reference through the provider.", providerAddr, rAddr),
+			Subject:  ref.SourceRange.ToHCL().Ptr(),
+		})
+	}
+
+	return diags
+}
+
+// selfRefAllowComment is the annotation that suppresses self-reference
+// diagnostics for a specific reference when it appears in a comment on the
+// reference's own source line or the line directly above it.
+const selfRefAllowComment = "terraform:allow-self-ref"
+
+// selfRefAllowedByAnnotation reports whether the given reference is covered
+// by a terraform:allow-self-ref comment, as an escape hatch for
+// meta-programming patterns that intentionally reference a resource's own
+// address in ways the graph can resolve. The check re-reads the reference's
+// source file and looks for the annotation after a comment marker on the
+// reference's line or the line immediately above it, so it suppresses only
+// the annotated reference rather than weakening the check globally.
+// References with no resolvable source file (including generated
+// configuration) are never suppressed. The scan is textual and best-effort:
+// a marker inside a string literal would be mistaken for a comment, which is
+// acceptable for an opt-in annotation that only ever relaxes a diagnostic
+// the user has decided to accept.
+func selfRefAllowedByAnnotation(ref *addrs.Reference) bool {
+	rng := ref.SourceRange
+	if rng.Filename == "" {
+		return false
+	}
+	src, err := os.ReadFile(rng.Filename)
+	if err != nil {
+		return false
+	}
+
+	lines := strings.Split(string(src), "\n")
+	for _, lineNum := range []int{rng.Start.Line, rng.Start.Line - 1} {
+		if lineNum < 1 || lineNum > len(lines) {
+			continue
+		}
+		line := lines[lineNum-1]
+		for _, marker := range []string{"#", "//"} {
+			if idx := strings.Index(line, marker); idx >= 0 && strings.Contains(line[idx:], selfRefAllowComment) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// selfIndexExemptRanges finds references to the containing resource that are
+// immediately indexed by an expression provably selecting a different
+// instance, such as aws_instance.foo[count.index - 1], and returns the
+// source ranges of those references so that the self-reference checks can
+// skip them: such a reference names a sibling instance, not the instance it
+// appears in. Only native syntax bodies can be walked for their index
+// expressions; JSON configuration gets no exemption, which fails safe by
+// keeping the check strict there.
+func selfIndexExemptRanges(addrStrs []string, config hcl.Body) map[hcl.Range]struct{} {
+	body, ok := config.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+
+	ranges := make(map[hcl.Range]struct{})
+	collect := hclsyntax.VisitFunc(func(node hclsyntax.Node) hcl.Diagnostics {
+		indexExpr, ok := node.(*hclsyntax.IndexExpr)
+		if !ok {
+			return nil
+		}
+		collection, ok := indexExpr.Collection.(*hclsyntax.ScopeTraversalExpr)
+		if !ok {
+			return nil
+		}
+		ref, parseDiags := addrs.ParseRef(collection.Traversal)
+		if parseDiags.HasErrors() {
+			return nil
+		}
+		subject := ref.Subject.String()
+		for _, addrStr := range addrStrs {
+			if subject == addrStr && indexKeyOffsetsInstance(indexExpr.Key) {
+				ranges[collection.Traversal.SourceRange()] = struct{}{}
+				break
+			}
+		}
+		return nil
+	})
+
+	var walkBody func(b *hclsyntax.Body)
+	walkBody = func(b *hclsyntax.Body) {
+		for _, attr := range b.Attributes {
+			hclsyntax.VisitAll(attr.Expr, collect)
+		}
+		for _, block := range b.Blocks {
+			walkBody(block.Body)
+		}
+	}
+	walkBody(body)
+
+	if len(ranges) == 0 {
+		return nil
+	}
+	return ranges
+}
+
+// indexKeyOffsetsInstance reports whether an index expression provably
+// selects a different instance than the one being evaluated: count.index
+// plus or minus a nonzero constant. A bare count.index selects the instance
+// itself, and anything cleverer (modulo arithmetic, conditionals) can't be
+// shown statically to differ, so both stay subject to the self-reference
+// check.
+func indexKeyOffsetsInstance(key hclsyntax.Expression) bool {
+	binary, ok := key.(*hclsyntax.BinaryOpExpr)
+	if !ok {
+		return false
+	}
+	if binary.Op != hclsyntax.OpAdd && binary.Op != hclsyntax.OpSubtract {
+		return false
+	}
+
+	isCountIndex := func(e hclsyntax.Expression) bool {
+		traversal, ok := e.(*hclsyntax.ScopeTraversalExpr)
+		if !ok {
+			return false
+		}
+		ref, parseDiags := addrs.ParseRef(traversal.Traversal)
+		if parseDiags.HasErrors() {
+			return false
+		}
+		countAttr, ok := ref.Subject.(addrs.CountAttr)
+		return ok && countAttr.Name == "index"
+	}
+	isNonZeroNumber := func(e hclsyntax.Expression) bool {
+		lit, ok := e.(*hclsyntax.LiteralValueExpr)
+		if !ok {
+			return false
+		}
+		v := lit.Val
+		if v.IsNull() || !v.IsKnown() || v.Type() != cty.Number {
+			return false
+		}
+		return v.AsBigFloat().Sign() != 0
+	}
+
+	if isCountIndex(binary.LHS) && isNonZeroNumber(binary.RHS) {
+		return true
+	}
+	// Addition commutes, so 1 + count.index counts too; subtraction with
+	// count.index on the right selects an unrelated index, not an offset.
+	return binary.Op == hclsyntax.OpAdd && isNonZeroNumber(binary.LHS) && isCountIndex(binary.RHS)
+}
+
+// selfRefSeverity returns the severity for "Self-referential block"
+// diagnostics. These are normally errors, but TF_WARN_SELF_REFERENCES
+// downgrades them to warnings; the detection itself is unaffected.
+func selfRefSeverity() hcl.DiagnosticSeverity {
+	if flagWarnSelfRefs {
+		return hcl.DiagWarning
+	}
+	return hcl.DiagError
+}
+
+// firstAttrStep returns the name of the first attribute traversal step in
+// the given remaining traversal, or an empty string if the traversal doesn't
+// select an attribute.
+func firstAttrStep(remain hcl.Traversal) string {
+	for _, step := range remain {
+		if attr, ok := step.(hcl.TraverseAttr); ok {
+			return attr.Name
+		}
+	}
+	return ""
+}
+
 // validateMetaSelfRef checks to ensure that a specific meta expression (count /
 // for_each) does not reference the resource it is attached to. The behaviour
 // is slightly different from validateSelfRef in that this function is only ever
@@ -73,7 +826,7 @@ func validateSelfRef(addr addrs.Referenceable, config hcl.Body, providerSchema p
 func validateMetaSelfRef(addr addrs.Resource, expr hcl.Expression) tfdiags.Diagnostics {
 	return validateSelfRefFromExprInner(addr, expr, func(ref *addrs.Reference) *hcl.Diagnostic {
 		return &hcl.Diagnostic{
-			Severity: hcl.DiagError,
+			Severity: selfRefSeverity(),
 			Summary:  "Self-referential block",
 			Detail:   fmt.Sprintf("Configuration for %s may not refer to itself.", addr.String()),
 			Subject:  ref.SourceRange.ToHCL().Ptr(),
@@ -81,6 +834,58 @@ func validateMetaSelfRef(addr addrs.Resource, expr hcl.Expression) tfdiags.Diagn
 	})
 }
 
+// validateMetaSelfRefWithLocals is like validateMetaSelfRef but additionally
+// resolves one level of local-value indirection using the given local value
+// configurations. A count or for_each expression built from a local whose own
+// definition is derived from the resource feeds the resource's attributes
+// back into its own expansion, which would otherwise only surface as a
+// dependency cycle; each reference in the body then carries that cycle via
+// each.value or count.index without naming the resource at all. The
+// diagnostic points at the offending reference within the meta-argument
+// expression.
+func validateMetaSelfRefWithLocals(addr addrs.Resource, expr hcl.Expression, locals map[string]*configs.Local) tfdiags.Diagnostics {
+	diags := validateMetaSelfRef(addr, expr)
+	if expr == nil || len(locals) == 0 {
+		return diags
+	}
+
+	refs, _ := langrefs.ReferencesInExpr(addrs.ParseRef, expr)
+Local:
+	for _, ref := range refs {
+		localAddr, ok := ref.Subject.(addrs.LocalValue)
+		if !ok {
+			continue
+		}
+		local := locals[localAddr.Name]
+		if local == nil {
+			continue
+		}
+
+		localRefs, _ := langrefs.ReferencesInExpr(addrs.ParseRef, local.Expr)
+		for _, localRef := range localRefs {
+			var target addrs.Resource
+			switch t := localRef.Subject.(type) {
+			case addrs.ResourceInstance:
+				// Instance references resolve to their containing resource,
+				// since we're only comparing against the static resource.
+				target = t.Resource
+			case addrs.Resource:
+				target = t
+			default:
+				// Anything else cannot be a self-reference.
+				continue
+			}
+
+			if target.Equal(addr) {
+				diags = diags.Append(localSelfRefDiagnostic(addr.String(), localAddr.Name, ref))
+				continue Local
+			}
+		}
+	}
+
+	return diags
+}
+
 // validateImportSelfRef is similar to validateMetaSelfRef except it
 // tweaks the error message slightly to reflect the self-reference is coming
 // from an import block instead of directly from the resource. All the same
@@ -96,11 +901,130 @@ func validateImportSelfRef(addr addrs.Resource, expr hcl.Expression) tfdiags.Dia
 	})
 }
 
+// validateLifecycleSelfRef checks a replace_triggered_by expression for
+// references back to the resource instance it is attached to. The rules here
+// are deliberately more relaxed than validateSelfRef's: replace_triggered_by
+// legitimately may refer to sibling instances of the same resource (for
+// example via count.index-relative addressing), so a reference to the whole
+// resource is only an error when the instance has no key and the reference
+// therefore can only mean the instance itself. A reference that statically
+// names the exact same instance is always an error. References whose instance
+// key is only known dynamically are left for evaluation to resolve.
+func validateLifecycleSelfRef(addr addrs.ResourceInstance, expr hcl.Expression) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	refs, _ := langrefs.ReferencesInExpr(addrs.ParseRef, expr)
+	for _, ref := range refs {
+		self := false
+		switch subject := ref.Subject.(type) {
+		case addrs.ResourceInstance:
+			// Statically naming the exact same instance is always an error;
+			// a sibling instance of the same resource is fine.
+			self = subject.Equal(addr)
+		case addrs.Resource:
+			// A whole-resource reference covers every instance, but when the
+			// instance has a key we can't tell statically whether the intent
+			// is a sibling, so only the unkeyed case is certainly circular.
+			self = subject.Equal(addr.ContainingResource()) && addr.Key == addrs.NoKey
+		default:
+			// Anything else cannot be a self-reference.
+			continue
+		}
+
+		if self {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Self-referential replace_triggered_by",
+				Detail:   fmt.Sprintf("The replace_triggered_by expression for %s may not refer to that same instance: its own changes already replace it, so the reference can only create a cycle. Sibling instances of the same resource may be referenced.", addr.String()),
+				Subject:  ref.SourceRange.ToHCL().Ptr(),
+			})
+		}
+	}
+
+	return diags
+}
+
+// validateDataConditionSelfRef checks that a precondition or postcondition
+// expression of a data source does not refer to the data source's own result,
+// which would make the data source depend on itself. References through the
+// "self" object are unaffected, since those have their own address type and
+// are the supported way to test the data source's result in a postcondition.
+// All the same instance-unpacking caveats apply as validateMetaSelfRef.
+func validateDataConditionSelfRef(addr addrs.Resource, expr hcl.Expression) tfdiags.Diagnostics {
+	return validateSelfRefFromExprInner(addr, expr, func(ref *addrs.Reference) *hcl.Diagnostic {
+		return &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Self-referential data resource condition",
+			Detail:   fmt.Sprintf("The condition for %s refers to the data source's own result, so Terraform would need to read the data source before deciding whether to read it. To check the data source's own result, write a postcondition that refers to it through the \"self\" object instead.", addr.String()),
+			Subject:  ref.SourceRange.ToHCL().Ptr(),
+		}
+	})
+}
+
+// validateProvisionerConnSelfRef checks a provisioner connection block for
+// references back to the resource the provisioner is attached to. Referring
+// to the resource's own configured arguments there is legitimate, since
+// those values come straight from the configuration, but a reference that
+// selects a computed attribute can only be resolved once the resource has
+// been fully applied, and the connection is needed while applying it; those
+// references are reported with that ordering problem spelled out. The self
+// object is unaffected, since it is resolved from the instance being
+// provisioned after it has been created. All the same instance-unpacking
+// caveats apply as validateMetaSelfRef.
+func validateProvisionerConnSelfRef(addr addrs.Resource, config hcl.Body, resourceSchema *configschema.Block) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if config == nil || resourceSchema == nil {
+		return diags
+	}
+
+	refs, _ := langrefs.ReferencesInBlock(addrs.ParseRef, config, connectionBlockSupersetSchema)
+	for _, ref := range refs {
+		var target addrs.Resource
+		switch t := ref.Subject.(type) {
+		case addrs.ResourceInstance:
+			target = t.Resource
+		case addrs.Resource:
+			target = t
+		default:
+			// Anything else cannot be a self-reference.
+			continue
+		}
+		if !target.Equal(addr) {
+			continue
+		}
+
+		attrName := firstAttrStep(ref.Remaining)
+		if attrName != "" {
+			attr, ok := resourceSchema.Attributes[attrName]
+			if !ok {
+				// An unknown attribute is reported by evaluation; there's no
+				// way to tell here whether it would have been computed.
+				continue
+			}
+			if !attr.Computed {
+				// A configured argument's value is known from the
+				// configuration itself, so connecting with it doesn't depend
+				// on the apply having finished.
+				continue
+			}
+		}
+
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Self-referential connection configuration",
+			Detail:   fmt.Sprintf("The connection configuration for %s refers to %s, whose value is only known once %s has been fully applied. The connection is needed while applying the resource, so it cannot wait for the resource's own apply to finish. To use an attribute of the instance being provisioned, refer to it through the \"self\" object instead.", addr, ref.DisplayString(), addr),
+			Subject:  ref.SourceRange.ToHCL().Ptr(),
+		})
+	}
+
+	return diags
+}
+
 // validateSelfRefFromExprInner is a helper function that takes an address and
 // an expression and returns diagnostics for self-references in the expression.
 //
-// This should only be called via validateMetaSelfRef and validateImportSelfRef,
-// do not access this function directly.
+// This should only be called via validateMetaSelfRef, validateImportSelfRef
+// and validateDataConditionSelfRef, do not access this function directly.
 func validateSelfRefFromExprInner(addr addrs.Resource, expr hcl.Expression, diag func(ref *addrs.Reference) *hcl.Diagnostic) tfdiags.Diagnostics {
 	var diags tfdiags.Diagnostics
 
@@ -120,6 +1044,9 @@ func validateSelfRefFromExprInner(addr addrs.Resource, expr hcl.Expression, diag
 		}
 
 		if target.Equal(addr) {
+			if selfRefAllowedByAnnotation(ref) {
+				continue
+			}
 			diags = diags.Append(diag(ref))
 		}
 	}
@@ -130,10 +1057,17 @@ func validateSelfRefFromExprInner(addr addrs.Resource, expr hcl.Expression, diag
 // Legacy provisioner configurations may refer to single instances using the
 // resource address. We need to filter these out from the reported references
 // to prevent cycles.
-func filterSelfRefs(self addrs.Resource, refs []*addrs.Reference) []*addrs.Reference {
-	for i := 0; i < len(refs); i++ {
-		ref := refs[i]
-
+//
+// The references that were dropped are returned alongside the kept ones so
+// that callers can log them; silently losing an implied dependency makes
+// debugging difficult otherwise. Both result slices preserve the relative
+// order of the input, so downstream consumers that depend on reference order
+// for deterministic graph construction or diagnostics see stable results.
+// The kept slice reuses the backing array of refs, so the input must not be
+// used again after calling this.
+func filterSelfRefs(self addrs.Resource, refs []*addrs.Reference) (kept, filtered []*addrs.Reference) {
+	kept = refs[:0]
+	for _, ref := range refs {
 		var subject addrs.Resource
 		switch subj := ref.Subject.(type) {
 		case addrs.Resource:
@@ -141,15 +1075,15 @@ func filterSelfRefs(self addrs.Resource, refs []*addrs.Reference) []*addrs.Refer
 		case addrs.ResourceInstance:
 			subject = subj.ContainingResource()
 		default:
+			kept = append(kept, ref)
 			continue
 		}
 
 		if self.Equal(subject) {
-			tail := len(refs) - 1
-
-			refs[i], refs[tail] = refs[tail], refs[i]
-			refs = refs[:tail]
+			filtered = append(filtered, ref)
+			continue
 		}
+		kept = append(kept, ref)
 	}
-	return refs
+	return kept, filtered
 }
@@ -5,16 +5,24 @@ package terraform
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform/internal/configs"
 	"github.com/hashicorp/terraform/internal/configs/configschema"
 	"github.com/hashicorp/terraform/internal/providers"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hcltest"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/hashicorp/terraform/internal/addrs"
+	"github.com/hashicorp/terraform/internal/lang/langrefs"
+	"github.com/hashicorp/terraform/internal/tfdiags"
 )
 
 func TestValidateSelfRef(t *testing.T) {
@@ -23,10 +31,1258 @@ func TestValidateSelfRef(t *testing.T) {
 		Type: "aws_instance",
 		Name: "foo",
 	}
+	dAddr := addrs.Resource{
+		Mode: addrs.DataResourceMode,
+		Type: "aws_ami",
+		Name: "foo",
+	}
+
+	tests := []struct {
+		Name string
+		Addr addrs.Referenceable
+		Expr hcl.Expression
+		Err  bool
+	}{
+		{
+			"no references at all",
+			rAddr,
+			hcltest.MockExprLiteral(cty.StringVal("bar")),
+			false,
+		},
+
+		{
+			"non self reference",
+			rAddr,
+			hcltest.MockExprTraversalSrc("aws_instance.bar.id"),
+			false,
+		},
+
+		{
+			"self reference",
+			rAddr,
+			hcltest.MockExprTraversalSrc("aws_instance.foo.id"),
+			true,
+		},
+
+		{
+			"self reference other index",
+			rAddr,
+			hcltest.MockExprTraversalSrc("aws_instance.foo[4].id"),
+			false,
+		},
+
+		{
+			"self reference same index",
+			rAddr.Instance(addrs.IntKey(4)),
+			hcltest.MockExprTraversalSrc("aws_instance.foo[4].id"),
+			true,
+		},
+
+		{
+			"self reference whole",
+			rAddr.Instance(addrs.IntKey(4)),
+			hcltest.MockExprTraversalSrc("aws_instance.foo"),
+			true,
+		},
+
+		{
+			"data source non self reference",
+			dAddr,
+			hcltest.MockExprTraversalSrc("data.aws_ami.bar.id"),
+			false,
+		},
+
+		{
+			"data source self reference",
+			dAddr,
+			hcltest.MockExprTraversalSrc("data.aws_ami.foo.id"),
+			true,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d-%s", i, test.Name), func(t *testing.T) {
+			body := hcltest.MockBody(&hcl.BodyContent{
+				Attributes: hcl.Attributes{
+					"foo": {
+						Name: "foo",
+						Expr: test.Expr,
+					},
+				},
+			})
+
+			block := &configschema.Block{
+				Attributes: map[string]*configschema.Attribute{
+					"foo": {
+						Type:     cty.String,
+						Required: true,
+					},
+				},
+			}
+			ps := providers.ProviderSchema{
+				ResourceTypes: map[string]providers.Schema{
+					"aws_instance": {Block: block},
+				},
+				DataSources: map[string]providers.Schema{
+					"aws_ami": {Block: block},
+				},
+			}
+
+			// First test the expression within the context of the configuration
+			// body.
+			diags := validateSelfRef(test.Addr, body, ps)
+			if diags.HasErrors() != test.Err {
+				if test.Err {
+					t.Errorf("unexpected success; want error")
+				} else {
+					t.Errorf("unexpected error\n\n%s", diags.Err())
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSelfRefs(t *testing.T) {
+	block := &configschema.Block{
+		Attributes: map[string]*configschema.Attribute{
+			"foo": {
+				Type:     cty.String,
+				Required: true,
+			},
+		},
+	}
+	ps := providers.ProviderSchema{
+		ResourceTypes: map[string]providers.Schema{
+			"aws_instance": {Block: block},
+		},
+	}
+
+	// Each resource's body lives in its own file, so the merged diagnostics
+	// can be checked for source-range ordering. The inputs are deliberately
+	// out of order.
+	parse := func(name, src string) hcl.Body {
+		file, hclDiags := hclsyntax.ParseConfig([]byte(src), name, hcl.InitialPos)
+		if hclDiags.HasErrors() {
+			t.Fatalf("parse error: %s", hclDiags.Error())
+		}
+		return file.Body
+	}
+	resources := []resourceToValidate{
+		{
+			Addr:   addrs.Resource{Mode: addrs.ManagedResourceMode, Type: "aws_instance", Name: "c"},
+			Config: parse("c.tf", `foo = aws_instance.c.id`+"\n"),
+		},
+		{
+			Addr:   addrs.Resource{Mode: addrs.ManagedResourceMode, Type: "aws_instance", Name: "a"},
+			Config: parse("a.tf", `foo = aws_instance.a.id`+"\n"),
+		},
+		{
+			Addr:   addrs.Resource{Mode: addrs.ManagedResourceMode, Type: "aws_instance", Name: "ok"},
+			Config: parse("ok.tf", `foo = aws_instance.other.id`+"\n"),
+		},
+		{
+			Addr:   addrs.Resource{Mode: addrs.ManagedResourceMode, Type: "aws_instance", Name: "b"},
+			Config: parse("b.tf", `foo = aws_instance.b.id`+"\n"),
+		},
+	}
+
+	diags := validateSelfRefs(resources, ps)
+	if !diags.HasErrors() {
+		t.Fatal("unexpected success; want errors")
+	}
+	if got := len(diags); got != 3 {
+		t.Fatalf("wrong number of diagnostics %d; want 3\n%s", got, diags.Err())
+	}
+	var files []string
+	for _, diag := range diags {
+		subject := diag.Source().Subject
+		if subject == nil {
+			t.Fatalf("diagnostic has no subject range: %s", diag.Description().Detail)
+		}
+		files = append(files, subject.Filename)
+	}
+	if !reflect.DeepEqual(files, []string{"a.tf", "b.tf", "c.tf"}) {
+		t.Errorf("diagnostics are not ordered by source range: %v", files)
+	}
+
+	if got := validateSelfRefs(nil, ps); len(got) != 0 {
+		t.Errorf("unexpected diagnostics for empty input: %s", got.Err())
+	}
+}
+
+func TestValidateSelfRefDynamicBlocks(t *testing.T) {
+	rAddr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "aws_instance",
+		Name: "foo",
+	}
+	ps := providers.ProviderSchema{
+		ResourceTypes: map[string]providers.Schema{
+			"aws_instance": {
+				Block: &configschema.Block{
+					BlockTypes: map[string]*configschema.NestedBlock{
+						"ingress": {
+							Nesting: configschema.NestingList,
+							Block: configschema.Block{
+								Attributes: map[string]*configschema.Attribute{
+									"port": {
+										Type:     cty.String,
+										Optional: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		Name string
+		Src  string
+		Err  bool
+	}{
+		{
+			"self reference in dynamic content",
+			`dynamic "ingress" {
+  for_each = ["a"]
+  content {
+    port = aws_instance.foo.id
+  }
+}
+`,
+			true,
+		},
+		{
+			"self reference in dynamic for_each",
+			`dynamic "ingress" {
+  for_each = aws_instance.foo.ports
+  content {
+    port = "x"
+  }
+}
+`,
+			true,
+		},
+		{
+			"iterator variable is not a self reference",
+			`dynamic "ingress" {
+  for_each = ["a"]
+  iterator = it
+  content {
+    port = it.value
+  }
+}
+`,
+			false,
+		},
+		{
+			"other resource in dynamic content",
+			`dynamic "ingress" {
+  for_each = ["a"]
+  content {
+    port = aws_instance.bar.id
+  }
+}
+`,
+			false,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d-%s", i, test.Name), func(t *testing.T) {
+			file, hclDiags := hclsyntax.ParseConfig([]byte(test.Src), "main.tf", hcl.InitialPos)
+			if hclDiags.HasErrors() {
+				t.Fatalf("parse error: %s", hclDiags.Error())
+			}
+
+			diags := validateSelfRef(rAddr, file.Body, ps)
+			if diags.HasErrors() != test.Err {
+				if test.Err {
+					t.Errorf("unexpected success; want error")
+				} else {
+					t.Errorf("unexpected error\n\n%s", diags.Err())
+				}
+				return
+			}
+			if test.Err {
+				// The diagnostic must point into the dynamic block rather
+				// than at the block as a whole.
+				subject := diags[0].Source().Subject
+				if subject == nil {
+					t.Fatal("diagnostic has no subject range")
+				}
+				if !strings.Contains(test.Src, "aws_instance.foo") {
+					t.Fatal("test source does not contain the self reference")
+				}
+				wantLine := 1 + strings.Count(strings.Split(test.Src, "aws_instance.foo")[0], "\n")
+				if subject.Start.Line != wantLine {
+					t.Errorf("diagnostic points at line %d; want %d", subject.Start.Line, wantLine)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSelfRefCountIndexOffset(t *testing.T) {
+	rAddr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "aws_instance",
+		Name: "foo",
+	}
+	ps := providers.ProviderSchema{
+		ResourceTypes: map[string]providers.Schema{
+			"aws_instance": {
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"peer": {
+							Type:     cty.String,
+							Optional: true,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		Name string
+		Src  string
+		Err  bool
+	}{
+		{
+			"offset behind",
+			`peer = aws_instance.foo[count.index - 1].id` + "\n",
+			false,
+		},
+		{
+			"offset ahead",
+			`peer = aws_instance.foo[count.index + 1].id` + "\n",
+			false,
+		},
+		{
+			"offset ahead commuted",
+			`peer = aws_instance.foo[1 + count.index].id` + "\n",
+			false,
+		},
+		{
+			"bare count.index is the same instance",
+			`peer = aws_instance.foo[count.index].id` + "\n",
+			true,
+		},
+		{
+			"zero offset is the same instance",
+			`peer = aws_instance.foo[count.index - 0].id` + "\n",
+			true,
+		},
+		{
+			"arithmetic that can't be shown to differ",
+			`peer = aws_instance.foo[count.index % 2].id` + "\n",
+			true,
+		},
+		{
+			"offset index on another resource's count",
+			`peer = aws_instance.bar[count.index - 1].id` + "\n",
+			false,
+		},
+		{
+			"unindexed reference alongside an offset one",
+			`peer = "${aws_instance.foo[count.index - 1].id}-${aws_instance.foo.id}"` + "\n",
+			true,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d-%s", i, test.Name), func(t *testing.T) {
+			file, hclDiags := hclsyntax.ParseConfig([]byte(test.Src), "main.tf", hcl.InitialPos)
+			if hclDiags.HasErrors() {
+				t.Fatalf("parse error: %s", hclDiags.Error())
+			}
+
+			diags := validateSelfRef(rAddr, file.Body, ps)
+			if diags.HasErrors() != test.Err {
+				if test.Err {
+					t.Errorf("unexpected success; want error")
+				} else {
+					t.Errorf("unexpected error\n\n%s", diags.Err())
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSelfRefGrouped(t *testing.T) {
+	rAddr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "aws_instance",
+		Name: "foo",
+	}
+
+	body := hcltest.MockBody(&hcl.BodyContent{
+		Attributes: hcl.Attributes{
+			"one": {
+				Name: "one",
+				Expr: hcltest.MockExprTraversalSrc("aws_instance.foo.id"),
+			},
+			"two": {
+				Name: "two",
+				Expr: hcltest.MockExprTraversalSrc("aws_instance.foo.arn"),
+			},
+			"three": {
+				Name: "three",
+				Expr: hcltest.MockExprTraversalSrc("aws_instance.foo.id"),
+			},
+		},
+	})
+
+	attrs := map[string]*configschema.Attribute{}
+	for _, name := range []string{"one", "two", "three"} {
+		attrs[name] = &configschema.Attribute{
+			Type:     cty.String,
+			Required: true,
+		}
+	}
+	ps := providers.ProviderSchema{
+		ResourceTypes: map[string]providers.Schema{
+			"aws_instance": {Block: &configschema.Block{Attributes: attrs}},
+		},
+	}
+
+	// Three self-referencing attributes produce one consolidated diagnostic
+	// counting the other two, rather than three identical diagnostics.
+	diags := validateSelfRef(rAddr, body, ps)
+	if !diags.HasErrors() {
+		t.Fatal("unexpected success; want error")
+	}
+	if got := len(diags); got != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", got, diags.Err())
+	}
+	if got := diags[0].Description().Detail; !strings.Contains(got, "2 more references") {
+		t.Errorf("diagnostic does not count the other references: %s", got)
+	}
+}
+
+func TestValidateSelfRefWriteOnly(t *testing.T) {
+	rAddr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "aws_instance",
+		Name: "foo",
+	}
+
+	ps := providers.ProviderSchema{
+		ResourceTypes: map[string]providers.Schema{
+			"aws_instance": {
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"foo": {
+							Type:     cty.String,
+							Required: true,
+						},
+						"token": {
+							Type:      cty.String,
+							Optional:  true,
+							WriteOnly: true,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		Name       string
+		Expr       hcl.Expression
+		WantDetail string
+	}{
+		{
+			"self reference to ordinary attribute",
+			hcltest.MockExprTraversalSrc("aws_instance.foo.foo"),
+			"may not refer to itself.",
+		},
+		{
+			"self reference to write-only attribute",
+			hcltest.MockExprTraversalSrc("aws_instance.foo.token"),
+			"write-only attribute",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d-%s", i, test.Name), func(t *testing.T) {
+			body := hcltest.MockBody(&hcl.BodyContent{
+				Attributes: hcl.Attributes{
+					"foo": {
+						Name: "foo",
+						Expr: test.Expr,
+					},
+				},
+			})
+
+			diags := validateSelfRef(rAddr, body, ps)
+			if !diags.HasErrors() {
+				t.Fatal("unexpected success; want error")
+			}
+			if got := diags.Err().Error(); !strings.Contains(got, test.WantDetail) {
+				t.Errorf("wrong error\ngot: %s\nwant substring: %s", got, test.WantDetail)
+			}
+		})
+	}
+}
+
+func TestValidateSelfRefAbs(t *testing.T) {
+	// The resource lives inside nested expanded modules, so both the module
+	// path and the resource itself carry instance keys.
+	moduleAddr := addrs.RootModuleInstance.
+		Child("parent", addrs.IntKey(1)).
+		Child("child", addrs.StringKey("a"))
+	rAddr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "aws_instance",
+		Name: "foo",
+	}
+
+	tests := []struct {
+		Name string
+		Addr addrs.AbsResourceInstance
+		Expr hcl.Expression
+		Err  bool
+	}{
+		{
+			"no references at all",
+			rAddr.Instance(addrs.NoKey).Absolute(moduleAddr),
+			hcltest.MockExprLiteral(cty.StringVal("bar")),
+			false,
+		},
+
+		{
+			"non self reference",
+			rAddr.Instance(addrs.NoKey).Absolute(moduleAddr),
+			hcltest.MockExprTraversalSrc("aws_instance.bar.id"),
+			false,
+		},
+
+		{
+			"self reference",
+			rAddr.Instance(addrs.NoKey).Absolute(moduleAddr),
+			hcltest.MockExprTraversalSrc("aws_instance.foo.id"),
+			true,
+		},
+
+		{
+			"self reference other index",
+			rAddr.Instance(addrs.IntKey(4)).Absolute(moduleAddr),
+			hcltest.MockExprTraversalSrc("aws_instance.foo[2].id"),
+			false,
+		},
+
+		{
+			"self reference same index",
+			rAddr.Instance(addrs.IntKey(4)).Absolute(moduleAddr),
+			hcltest.MockExprTraversalSrc("aws_instance.foo[4].id"),
+			true,
+		},
+
+		{
+			"self reference whole",
+			rAddr.Instance(addrs.IntKey(4)).Absolute(moduleAddr),
+			hcltest.MockExprTraversalSrc("aws_instance.foo"),
+			true,
+		},
+
+		{
+			"self reference in the root module",
+			rAddr.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+			hcltest.MockExprTraversalSrc("aws_instance.foo.id"),
+			true,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d-%s", i, test.Name), func(t *testing.T) {
+			body := hcltest.MockBody(&hcl.BodyContent{
+				Attributes: hcl.Attributes{
+					"foo": {
+						Name: "foo",
+						Expr: test.Expr,
+					},
+				},
+			})
+
+			ps := providers.ProviderSchema{
+				ResourceTypes: map[string]providers.Schema{
+					"aws_instance": {
+						Block: &configschema.Block{
+							Attributes: map[string]*configschema.Attribute{
+								"foo": {
+									Type:     cty.String,
+									Required: true,
+								},
+							},
+						},
+					},
+				},
+			}
+
+			diags := validateSelfRefAbs(test.Addr, body, ps)
+			if diags.HasErrors() != test.Err {
+				if test.Err {
+					t.Errorf("unexpected success; want error")
+				} else {
+					t.Errorf("unexpected error\n\n%s", diags.Err())
+				}
+			}
+			if test.Err {
+				// The diagnostic must name the resource by its full address,
+				// including the module instance keys.
+				if got := diags.Err().Error(); !strings.Contains(got, moduleAddr.String()) && test.Addr.Module.String() == moduleAddr.String() {
+					t.Errorf("diagnostic does not name the full address:\n%s", got)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSelfRefMissingSchema(t *testing.T) {
+	rAddr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "aws_instance",
+		Name: "foo",
+	}
+	body := hcltest.MockBody(&hcl.BodyContent{
+		Attributes: hcl.Attributes{
+			"foo": {
+				Name: "foo",
+				Expr: hcltest.MockExprTraversalSrc("aws_instance.foo.id"),
+			},
+		},
+	})
+	emptySchema := providers.ProviderSchema{}
+
+	// The strict variant treats a missing schema as a bug in Terraform.
+	diags := validateSelfRef(rAddr, body, emptySchema)
+	if !diags.HasErrors() {
+		t.Fatal("unexpected success; want error")
+	}
+	if got := diags.Err().Error(); !strings.Contains(got, "bug in Terraform") {
+		t.Errorf("wrong error: %s", got)
+	}
+
+	// The lenient variant warns about the missing schema, but still checks
+	// the top-level attributes it can parse without one, so the
+	// self-reference above is reported rather than masked.
+	diags = validateSelfRefLenient(rAddr, body, emptySchema)
+	if !diags.HasErrors() {
+		t.Fatal("unexpected success; want error for the self-reference")
+	}
+	foundWarning := false
+	foundSelfRef := false
+	for _, diag := range diags {
+		switch diag.Severity() {
+		case tfdiags.Warning:
+			if strings.Contains(diag.Description().Summary, "only its top-level arguments were checked") {
+				foundWarning = true
+			}
+		case tfdiags.Error:
+			if strings.Contains(diag.Description().Detail, "may not refer to itself") {
+				foundSelfRef = true
+			}
+		}
+	}
+	if !foundWarning {
+		t.Errorf("missing schema warning in diagnostics: %s", diags.Err())
+	}
+	if !foundSelfRef {
+		t.Errorf("missing self-reference error in diagnostics: %s", diags.Err())
+	}
+
+	// Without a self-reference the lenient variant produces only the warning.
+	benignBody := hcltest.MockBody(&hcl.BodyContent{
+		Attributes: hcl.Attributes{
+			"foo": {
+				Name: "foo",
+				Expr: hcltest.MockExprTraversalSrc("aws_instance.bar.id"),
+			},
+		},
+	})
+	diags = validateSelfRefLenient(rAddr, benignBody, emptySchema)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected error\n\n%s", diags.Err())
+	}
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1 warning", len(diags))
+	}
+}
+
+func TestSelfRefRules(t *testing.T) {
+	rules := SelfRefRules()
+	if len(rules) == 0 {
+		t.Fatal("no self-reference rules returned")
+	}
+
+	names := map[string]bool{}
+	for _, rule := range rules {
+		if rule.Name == "" || rule.Context == "" || rule.Description == "" || rule.Summary == "" {
+			t.Errorf("rule has empty fields: %#v", rule)
+		}
+		if names[rule.Name] {
+			t.Errorf("duplicate rule name %q", rule.Name)
+		}
+		names[rule.Name] = true
+	}
+
+	// The summaries must stay in agreement with the diagnostics the validate
+	// functions actually emit; these are the distinct summaries used in this
+	// file.
+	for _, want := range []string{"Self-referential block", "Reference to write-only attribute", "Invalid import id argument", "Self-referential data resource condition", "Self-referential local value", "Self-referential replace_triggered_by", "Self-referential provider configuration", "Self-referential connection configuration"} {
+		found := false
+		for _, rule := range rules {
+			if rule.Summary == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("no rule with summary %q", want)
+		}
+	}
+}
+
+func TestValidateDataConditionSelfRef(t *testing.T) {
+	dAddr := addrs.Resource{
+		Mode: addrs.DataResourceMode,
+		Type: "aws_ami",
+		Name: "foo",
+	}
+
+	tests := []struct {
+		Name string
+		Expr hcl.Expression
+		Err  bool
+	}{
+		{
+			"no references at all",
+			hcltest.MockExprLiteral(cty.StringVal("bar")),
+			false,
+		},
+
+		{
+			"reference to another data source",
+			hcltest.MockExprTraversalSrc("data.aws_ami.bar.id"),
+			false,
+		},
+
+		{
+			"self attribute reference",
+			hcltest.MockExprTraversalSrc("self.id"),
+			false,
+		},
+
+		{
+			"reference to own result",
+			hcltest.MockExprTraversalSrc("data.aws_ami.foo.id"),
+			true,
+		},
+
+		{
+			"reference to an instance of own result",
+			hcltest.MockExprTraversalSrc("data.aws_ami.foo[0].id"),
+			true,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d-%s", i, test.Name), func(t *testing.T) {
+			diags := validateDataConditionSelfRef(dAddr, test.Expr)
+			if diags.HasErrors() != test.Err {
+				if test.Err {
+					t.Errorf("unexpected success; want error")
+				} else {
+					t.Errorf("unexpected error\n\n%s", diags.Err())
+				}
+			}
+			if test.Err {
+				if got := diags.Err().Error(); !strings.Contains(got, "Self-referential data resource condition") {
+					t.Errorf("wrong error: %s", got)
+				}
+			}
+		})
+	}
+}
+
+func TestFilterSelfRefs(t *testing.T) {
+	self := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "aws_instance",
+		Name: "foo",
+	}
+
+	var refs []*addrs.Reference
+	for _, src := range []string{"aws_instance.foo.id", "aws_instance.bar.id", "aws_instance.foo[0].id", "var.x"} {
+		parsed, parseDiags := langrefs.ReferencesInExpr(addrs.ParseRef, hcltest.MockExprTraversalSrc(src))
+		if parseDiags.HasErrors() {
+			t.Fatal(parseDiags.Err())
+		}
+		refs = append(refs, parsed...)
+	}
+
+	kept, filtered := filterSelfRefs(self, refs)
+
+	var keptStrs, filteredStrs []string
+	for _, ref := range kept {
+		keptStrs = append(keptStrs, ref.Subject.String())
+	}
+	for _, ref := range filtered {
+		filteredStrs = append(filteredStrs, ref.Subject.String())
+	}
+
+	wantKept := []string{"aws_instance.bar", "var.x"}
+	wantFiltered := []string{"aws_instance.foo", "aws_instance.foo[0]"}
+	if !reflect.DeepEqual(keptStrs, wantKept) {
+		t.Errorf("wrong kept references %#v; want %#v", keptStrs, wantKept)
+	}
+	if !reflect.DeepEqual(filteredStrs, wantFiltered) {
+		t.Errorf("wrong filtered references %#v; want %#v", filteredStrs, wantFiltered)
+	}
+}
+
+func TestFilterSelfRefsOrderStable(t *testing.T) {
+	self := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "aws_instance",
+		Name: "foo",
+	}
+
+	// Self-references interleaved throughout the input must not disturb the
+	// relative order of the surviving references.
+	srcs := []string{
+		"aws_instance.foo.id",
+		"var.a",
+		"aws_instance.foo[0].id",
+		"var.b",
+		"aws_instance.bar.id",
+		"aws_instance.foo[1].id",
+		"var.c",
+		"aws_instance.foo.arn",
+		"local.d",
+	}
+	var refs []*addrs.Reference
+	for _, src := range srcs {
+		parsed, parseDiags := langrefs.ReferencesInExpr(addrs.ParseRef, hcltest.MockExprTraversalSrc(src))
+		if parseDiags.HasErrors() {
+			t.Fatal(parseDiags.Err())
+		}
+		refs = append(refs, parsed...)
+	}
+
+	kept, filtered := filterSelfRefs(self, refs)
+
+	var keptStrs, filteredStrs []string
+	for _, ref := range kept {
+		keptStrs = append(keptStrs, ref.Subject.String())
+	}
+	for _, ref := range filtered {
+		filteredStrs = append(filteredStrs, ref.Subject.String())
+	}
+
+	wantKept := []string{"var.a", "var.b", "aws_instance.bar", "var.c", "local.d"}
+	wantFiltered := []string{"aws_instance.foo", "aws_instance.foo[0]", "aws_instance.foo[1]", "aws_instance.foo"}
+	if !reflect.DeepEqual(keptStrs, wantKept) {
+		t.Errorf("kept references are not in input order %#v; want %#v", keptStrs, wantKept)
+	}
+	if !reflect.DeepEqual(filteredStrs, wantFiltered) {
+		t.Errorf("filtered references are not in input order %#v; want %#v", filteredStrs, wantFiltered)
+	}
+}
+
+func TestValidateProviderSelfRef(t *testing.T) {
+	providerAddr := addrs.AbsProviderConfig{
+		Module:   addrs.RootModule,
+		Provider: addrs.NewDefaultProvider("aws"),
+	}
+	managed := []addrs.ConfigResource{
+		addrs.Resource{
+			Mode: addrs.ManagedResourceMode,
+			Type: "aws_iam_role",
+			Name: "x",
+		}.InModule(addrs.RootModule),
+	}
+	schema := &configschema.Block{
+		Attributes: map[string]*configschema.Attribute{
+			"role_arn": {
+				Type:     cty.String,
+				Optional: true,
+			},
+		},
+	}
+
+	tests := []struct {
+		Name string
+		Expr hcl.Expression
+		Err  bool
+	}{
+		{
+			"no references at all",
+			hcltest.MockExprLiteral(cty.StringVal("arn:aws:iam::123456789012:role/x")),
+			false,
+		},
+
+		{
+			"reference to an unmanaged resource",
+			hcltest.MockExprTraversalSrc("aws_iam_role.other.arn"),
+			false,
+		},
+
+		{
+			"reference to a managed resource",
+			hcltest.MockExprTraversalSrc("aws_iam_role.x.arn"),
+			true,
+		},
+
+		{
+			"reference to an instance of a managed resource",
+			hcltest.MockExprTraversalSrc("aws_iam_role.x[0].arn"),
+			true,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d-%s", i, test.Name), func(t *testing.T) {
+			body := hcltest.MockBody(&hcl.BodyContent{
+				Attributes: hcl.Attributes{
+					"role_arn": {
+						Name: "role_arn",
+						Expr: test.Expr,
+					},
+				},
+			})
+
+			diags := validateProviderSelfRef(providerAddr, body, schema, managed)
+			if diags.HasErrors() != test.Err {
+				if test.Err {
+					t.Errorf("unexpected success; want error")
+				} else {
+					t.Errorf("unexpected error\n\n%s", diags.Err())
+				}
+			}
+			if test.Err {
+				if got := diags.Err().Error(); !strings.Contains(got, "Self-referential provider configuration") {
+					t.Errorf("wrong error: %s", got)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateProvisionerConnSelfRef(t *testing.T) {
+	addr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "aws_instance",
+		Name: "foo",
+	}
+
+	schema := &configschema.Block{
+		Attributes: map[string]*configschema.Attribute{
+			"ami": {
+				Type:     cty.String,
+				Required: true,
+			},
+			"public_ip": {
+				Type:     cty.String,
+				Computed: true,
+			},
+		},
+	}
+
+	tests := []struct {
+		Name string
+		Expr hcl.Expression
+		Err  bool
+	}{
+		{
+			"no references at all",
+			hcltest.MockExprLiteral(cty.StringVal("10.0.0.1")),
+			false,
+		},
+
+		{
+			"reference to another resource",
+			hcltest.MockExprTraversalSrc("aws_instance.bar.public_ip"),
+			false,
+		},
+
+		{
+			"self reference to a configured argument",
+			hcltest.MockExprTraversalSrc("aws_instance.foo.ami"),
+			false,
+		},
+
+		{
+			"self reference to a computed attribute",
+			hcltest.MockExprTraversalSrc("aws_instance.foo.public_ip"),
+			true,
+		},
+
+		{
+			"self instance reference to a computed attribute",
+			hcltest.MockExprTraversalSrc("aws_instance.foo[2].public_ip"),
+			true,
+		},
+
+		{
+			"bare self reference",
+			hcltest.MockExprTraversalSrc("aws_instance.foo"),
+			true,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d-%s", i, test.Name), func(t *testing.T) {
+			body := hcltest.MockBody(&hcl.BodyContent{
+				Attributes: hcl.Attributes{
+					"host": {
+						Name: "host",
+						Expr: test.Expr,
+					},
+				},
+			})
+
+			diags := validateProvisionerConnSelfRef(addr, body, schema)
+			if diags.HasErrors() != test.Err {
+				if test.Err {
+					t.Errorf("unexpected success; want error")
+				} else {
+					t.Errorf("unexpected error\n\n%s", diags.Err())
+				}
+				return
+			}
+			if test.Err {
+				desc := diags[0].Description()
+				if !strings.Contains(desc.Detail, "fully applied") {
+					t.Errorf("diagnostic does not explain the apply ordering: %s", desc.Detail)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSelfRefAllowAnnotation(t *testing.T) {
+	rAddr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "aws_instance",
+		Name: "foo",
+	}
+	ps := providers.ProviderSchema{
+		ResourceTypes: map[string]providers.Schema{
+			"aws_instance": {
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"foo": {
+							Type:     cty.String,
+							Required: true,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		Name string
+		Src  string
+		Err  bool
+	}{
+		{
+			"no annotation",
+			`foo = aws_instance.foo.id
+`,
+			true,
+		},
+		{
+			"annotation on the same line",
+			`foo = aws_instance.foo.id # terraform:allow-self-ref
+`,
+			false,
+		},
+		{
+			"annotation on the line above",
+			`# terraform:allow-self-ref
+foo = aws_instance.foo.id
+`,
+			false,
+		},
+		{
+			"annotation in a double-slash comment",
+			`foo = aws_instance.foo.id // terraform:allow-self-ref
+`,
+			false,
+		},
+		{
+			"annotation too far away",
+			`# terraform:allow-self-ref
+
+foo = aws_instance.foo.id
+`,
+			true,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d-%s", i, test.Name), func(t *testing.T) {
+			// The annotation is read back from the source file named in the
+			// reference's range, so the configuration must really exist on
+			// disk.
+			filename := filepath.Join(t.TempDir(), "main.tf")
+			if err := os.WriteFile(filename, []byte(test.Src), 0600); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			file, hclDiags := hclsyntax.ParseConfig([]byte(test.Src), filename, hcl.InitialPos)
+			if hclDiags.HasErrors() {
+				t.Fatalf("parse error: %s", hclDiags.Error())
+			}
+
+			diags := validateSelfRef(rAddr, file.Body, ps)
+			if diags.HasErrors() != test.Err {
+				if test.Err {
+					t.Errorf("unexpected success; want error")
+				} else {
+					t.Errorf("unexpected error\n\n%s", diags.Err())
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSelfRefWarningsOnly(t *testing.T) {
+	// TF_WARN_SELF_REFERENCES downgrades self-reference errors to warnings
+	// so the rest of validation can proceed mid-refactor.
+	flagWarnSelfRefs = true
+	defer func() { flagWarnSelfRefs = false }()
+
+	rAddr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "aws_instance",
+		Name: "foo",
+	}
+	body := hcltest.MockBody(&hcl.BodyContent{
+		Attributes: hcl.Attributes{
+			"foo": {
+				Name: "foo",
+				Expr: hcltest.MockExprTraversalSrc("aws_instance.foo.id"),
+			},
+		},
+	})
+	ps := providers.ProviderSchema{
+		ResourceTypes: map[string]providers.Schema{
+			"aws_instance": {
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"foo": {
+							Type:     cty.String,
+							Required: true,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	diags := validateSelfRef(rAddr, body, ps)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1", len(diags))
+	}
+	if got, want := diags[0].Severity(), tfdiags.Warning; got != want {
+		t.Errorf("wrong severity %#v; want %#v", got, want)
+	}
+	if got, want := diags[0].Description().Summary, "Self-referential block"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+}
+
+func TestValidateLifecycleSelfRef(t *testing.T) {
+	keyedAddr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "aws_instance",
+		Name: "foo",
+	}.Instance(addrs.IntKey(1))
+	noKeyAddr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "aws_instance",
+		Name: "foo",
+	}.Instance(addrs.NoKey)
+
+	tests := []struct {
+		Name string
+		Addr addrs.ResourceInstance
+		Expr hcl.Expression
+		Err  bool
+	}{
+		{
+			"reference to another resource",
+			keyedAddr,
+			hcltest.MockExprTraversalSrc("aws_instance.bar.id"),
+			false,
+		},
+
+		{
+			"reference to a sibling instance",
+			keyedAddr,
+			hcltest.MockExprTraversalSrc("aws_instance.foo[0].id"),
+			false,
+		},
+
+		{
+			"whole-resource reference from a keyed instance",
+			keyedAddr,
+			hcltest.MockExprTraversalSrc("aws_instance.foo"),
+			false,
+		},
+
+		{
+			"reference to the exact same instance",
+			keyedAddr,
+			hcltest.MockExprTraversalSrc("aws_instance.foo[1].id"),
+			true,
+		},
+
+		{
+			"whole-resource reference from an unkeyed instance",
+			noKeyAddr,
+			hcltest.MockExprTraversalSrc("aws_instance.foo.id"),
+			true,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d-%s", i, test.Name), func(t *testing.T) {
+			diags := validateLifecycleSelfRef(test.Addr, test.Expr)
+			if diags.HasErrors() != test.Err {
+				if test.Err {
+					t.Errorf("unexpected success; want error")
+				} else {
+					t.Errorf("unexpected error\n\n%s", diags.Err())
+				}
+			}
+			if test.Err {
+				if got := diags.Err().Error(); !strings.Contains(got, "Self-referential replace_triggered_by") {
+					t.Errorf("wrong error: %s", got)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSelfInExpr(t *testing.T) {
+	rAddr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "aws_instance",
+		Name: "foo",
+	}
 
 	tests := []struct {
 		Name string
-		Addr addrs.Referenceable
+		Addr addrs.Resource
 		Expr hcl.Expression
 		Err  bool
 	}{
@@ -55,20 +1311,91 @@ func TestValidateSelfRef(t *testing.T) {
 			"self reference other index",
 			rAddr,
 			hcltest.MockExprTraversalSrc("aws_instance.foo[4].id"),
+			true,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d-%s", i, test.Name), func(t *testing.T) {
+			diags := validateMetaSelfRef(test.Addr, test.Expr)
+			if diags.HasErrors() != test.Err {
+				if test.Err {
+					t.Errorf("unexpected success; want error")
+				} else {
+					t.Errorf("unexpected error\n\n%s", diags.Err())
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSelfRefThroughLocals(t *testing.T) {
+	rAddr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "aws_instance",
+		Name: "foo",
+	}
+
+	locals := map[string]*configs.Local{
+		"self_derived": {
+			Name: "self_derived",
+			Expr: hcltest.MockExprTraversalSrc("aws_instance.foo.id"),
+		},
+		"other_derived": {
+			Name: "other_derived",
+			Expr: hcltest.MockExprTraversalSrc("aws_instance.bar.id"),
+		},
+		"instance_derived": {
+			Name: "instance_derived",
+			Expr: hcltest.MockExprTraversalSrc("aws_instance.foo[4].id"),
+		},
+	}
+
+	tests := []struct {
+		Name string
+		Addr addrs.Referenceable
+		Expr hcl.Expression
+		Err  bool
+	}{
+		{
+			"local derived from the resource itself",
+			rAddr,
+			hcltest.MockExprTraversalSrc("local.self_derived"),
+			true,
+		},
+
+		{
+			"local derived from another resource",
+			rAddr,
+			hcltest.MockExprTraversalSrc("local.other_derived"),
 			false,
 		},
 
 		{
-			"self reference same index",
+			"local with no definition available",
+			rAddr,
+			hcltest.MockExprTraversalSrc("local.undeclared"),
+			false,
+		},
+
+		{
+			"local derived from the same instance",
 			rAddr.Instance(addrs.IntKey(4)),
-			hcltest.MockExprTraversalSrc("aws_instance.foo[4].id"),
+			hcltest.MockExprTraversalSrc("local.instance_derived"),
 			true,
 		},
 
 		{
-			"self reference whole",
-			rAddr.Instance(addrs.IntKey(4)),
-			hcltest.MockExprTraversalSrc("aws_instance.foo"),
+			"local derived from another instance",
+			rAddr.Instance(addrs.IntKey(2)),
+			hcltest.MockExprTraversalSrc("local.instance_derived"),
+			false,
+		},
+
+		{
+			"direct self reference still caught",
+			rAddr,
+			hcltest.MockExprTraversalSrc("aws_instance.foo.id"),
 			true,
 		},
 	}
@@ -99,9 +1426,7 @@ func TestValidateSelfRef(t *testing.T) {
 				},
 			}
 
-			// First test the expression within the context of the configuration
-			// body.
-			diags := validateSelfRef(test.Addr, body, ps)
+			diags := validateSelfRefWithLocals(test.Addr, body, ps, locals)
 			if diags.HasErrors() != test.Err {
 				if test.Err {
 					t.Errorf("unexpected success; want error")
@@ -109,55 +1434,287 @@ func TestValidateSelfRef(t *testing.T) {
 					t.Errorf("unexpected error\n\n%s", diags.Err())
 				}
 			}
+			if test.Err && strings.HasPrefix(test.Name, "local") {
+				if got := diags.Err().Error(); !strings.Contains(got, "indirect self-reference") {
+					t.Errorf("diagnostic does not explain the indirection:\n%s", got)
+				}
+			}
 		})
 	}
 }
 
-func TestValidateSelfInExpr(t *testing.T) {
+func TestValidateMetaSelfRefWithLocals(t *testing.T) {
 	rAddr := addrs.Resource{
 		Mode: addrs.ManagedResourceMode,
 		Type: "aws_instance",
 		Name: "foo",
 	}
 
+	locals := map[string]*configs.Local{
+		"self_derived": {
+			Name: "self_derived",
+			Expr: hcltest.MockExprTraversalSrc("aws_instance.foo.id"),
+		},
+		"instance_derived": {
+			Name: "instance_derived",
+			Expr: hcltest.MockExprTraversalSrc("aws_instance.foo[4].id"),
+		},
+		"other_derived": {
+			Name: "other_derived",
+			Expr: hcltest.MockExprTraversalSrc("aws_instance.bar.id"),
+		},
+	}
+
 	tests := []struct {
 		Name string
-		Addr addrs.Resource
 		Expr hcl.Expression
 		Err  bool
 	}{
 		{
-			"no references at all",
-			rAddr,
-			hcltest.MockExprLiteral(cty.StringVal("bar")),
+			"no expression at all",
+			nil,
 			false,
 		},
 
 		{
-			"non self reference",
-			rAddr,
-			hcltest.MockExprTraversalSrc("aws_instance.bar.id"),
+			"direct self reference",
+			hcltest.MockExprTraversalSrc("aws_instance.foo.id"),
+			true,
+		},
+
+		{
+			"local derived from the resource itself",
+			hcltest.MockExprTraversalSrc("local.self_derived"),
+			true,
+		},
+
+		{
+			"local derived from an instance of the resource",
+			hcltest.MockExprTraversalSrc("local.instance_derived"),
+			true,
+		},
+
+		{
+			"local derived from another resource",
+			hcltest.MockExprTraversalSrc("local.other_derived"),
 			false,
 		},
 
 		{
-			"self reference",
-			rAddr,
-			hcltest.MockExprTraversalSrc("aws_instance.foo.id"),
+			"local with no definition available",
+			hcltest.MockExprTraversalSrc("local.undeclared"),
+			false,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d-%s", i, test.Name), func(t *testing.T) {
+			diags := validateMetaSelfRefWithLocals(rAddr, test.Expr, locals)
+			if diags.HasErrors() != test.Err {
+				if test.Err {
+					t.Errorf("unexpected success; want error")
+				} else {
+					t.Errorf("unexpected error\n\n%s", diags.Err())
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSelfRefAbsThroughLocals(t *testing.T) {
+	moduleAddr := addrs.RootModuleInstance.
+		Child("parent", addrs.IntKey(1)).
+		Child("child", addrs.StringKey("a"))
+	rAddr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "aws_instance",
+		Name: "foo",
+	}
+
+	locals := map[string]*configs.Local{
+		"self_derived": {
+			Name: "self_derived",
+			Expr: hcltest.MockExprTraversalSrc("aws_instance.foo.id"),
+		},
+		"other_derived": {
+			Name: "other_derived",
+			Expr: hcltest.MockExprTraversalSrc("aws_instance.bar.id"),
+		},
+	}
+
+	tests := []struct {
+		Name string
+		Addr addrs.AbsResourceInstance
+		Expr hcl.Expression
+		Err  bool
+	}{
+		{
+			"local derived from the resource itself",
+			rAddr.Instance(addrs.NoKey).Absolute(moduleAddr),
+			hcltest.MockExprTraversalSrc("local.self_derived"),
 			true,
 		},
 
 		{
-			"self reference other index",
-			rAddr,
-			hcltest.MockExprTraversalSrc("aws_instance.foo[4].id"),
+			"local derived from another resource",
+			rAddr.Instance(addrs.NoKey).Absolute(moduleAddr),
+			hcltest.MockExprTraversalSrc("local.other_derived"),
+			false,
+		},
+
+		{
+			"local derived from the resource in the root module",
+			rAddr.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+			hcltest.MockExprTraversalSrc("local.self_derived"),
 			true,
 		},
 	}
 
 	for i, test := range tests {
 		t.Run(fmt.Sprintf("%d-%s", i, test.Name), func(t *testing.T) {
-			diags := validateMetaSelfRef(test.Addr, test.Expr)
+			body := hcltest.MockBody(&hcl.BodyContent{
+				Attributes: hcl.Attributes{
+					"foo": {
+						Name: "foo",
+						Expr: test.Expr,
+					},
+				},
+			})
+
+			ps := providers.ProviderSchema{
+				ResourceTypes: map[string]providers.Schema{
+					"aws_instance": {
+						Block: &configschema.Block{
+							Attributes: map[string]*configschema.Attribute{
+								"foo": {
+									Type:     cty.String,
+									Required: true,
+								},
+							},
+						},
+					},
+				},
+			}
+
+			diags := validateSelfRefAbsWithLocals(test.Addr, body, ps, locals)
+			if diags.HasErrors() != test.Err {
+				if test.Err {
+					t.Errorf("unexpected success; want error")
+				} else {
+					t.Errorf("unexpected error\n\n%s", diags.Err())
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSelfRefNamesReference(t *testing.T) {
+	rAddr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "aws_instance",
+		Name: "foo",
+	}
+
+	body := hcltest.MockBody(&hcl.BodyContent{
+		Attributes: hcl.Attributes{
+			"foo": {
+				Name: "foo",
+				Expr: hcltest.MockExprTraversalSrc("aws_instance.foo.arn"),
+			},
+		},
+	})
+
+	ps := providers.ProviderSchema{
+		ResourceTypes: map[string]providers.Schema{
+			"aws_instance": {
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"foo": {
+							Type:     cty.String,
+							Required: true,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	diags := validateSelfRef(rAddr, body, ps)
+	if !diags.HasErrors() {
+		t.Fatal("unexpected success; want error")
+	}
+	// The detail must name the reference that tripped the check, so the
+	// offending expression is easy to find in a large block.
+	if got := diags.Err().Error(); !strings.Contains(got, "aws_instance.foo.arn") {
+		t.Errorf("diagnostic does not name the reference:\n%s", got)
+	}
+}
+
+func TestValidateSelfRefThroughOutputs(t *testing.T) {
+	rAddr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "aws_instance",
+		Name: "foo",
+	}
+
+	// The effective caller-side expressions behind passthrough outputs, as
+	// moduleCallOutputPassthroughs would build them.
+	outputs := map[string]hcl.Expression{
+		"m.self_derived":  hcltest.MockExprTraversalSrc("aws_instance.foo.id"),
+		"m.other_derived": hcltest.MockExprTraversalSrc("aws_instance.bar.id"),
+	}
+
+	tests := []struct {
+		Name string
+		Expr hcl.Expression
+		Err  bool
+	}{
+		{
+			"output round-tripping the resource itself",
+			hcltest.MockExprTraversalSrc("module.m.self_derived"),
+			true,
+		},
+
+		{
+			"output round-tripping another resource",
+			hcltest.MockExprTraversalSrc("module.m.other_derived"),
+			false,
+		},
+
+		{
+			"output that is not a passthrough",
+			hcltest.MockExprTraversalSrc("module.m.opaque"),
+			false,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d-%s", i, test.Name), func(t *testing.T) {
+			body := hcltest.MockBody(&hcl.BodyContent{
+				Attributes: hcl.Attributes{
+					"foo": {
+						Name: "foo",
+						Expr: test.Expr,
+					},
+				},
+			})
+
+			ps := providers.ProviderSchema{
+				ResourceTypes: map[string]providers.Schema{
+					"aws_instance": {
+						Block: &configschema.Block{
+							Attributes: map[string]*configschema.Attribute{
+								"foo": {
+									Type:     cty.String,
+									Required: true,
+								},
+							},
+						},
+					},
+				},
+			}
+
+			diags := validateSelfRefThroughOutputs(rAddr, body, ps, outputs)
 			if diags.HasErrors() != test.Err {
 				if test.Err {
 					t.Errorf("unexpected success; want error")
@@ -165,6 +1722,32 @@ func TestValidateSelfInExpr(t *testing.T) {
 					t.Errorf("unexpected error\n\n%s", diags.Err())
 				}
 			}
+			if test.Err {
+				if got := diags.Err().Error(); !strings.Contains(got, "indirect self-reference") {
+					t.Errorf("diagnostic does not explain the indirection:\n%s", got)
+				}
+			}
 		})
 	}
 }
+
+func TestModuleCallOutputPassthroughs(t *testing.T) {
+	cfg := testModule(t, "transform-module-output-passthrough")
+
+	outputs := moduleCallOutputPassthroughs(cfg)
+
+	// The passthrough output resolves to the caller-side argument expression.
+	expr, ok := outputs["m.result"]
+	if !ok {
+		t.Fatalf("no entry for passthrough output \"m.result\": %#v", outputs)
+	}
+	refs, _ := langrefs.ReferencesInExpr(addrs.ParseRef, expr)
+	if len(refs) != 1 || refs[0].Subject.String() != "aws_instance.foo" {
+		t.Fatalf("wrong references for \"m.result\": %#v", refs)
+	}
+
+	// An output with no input-variable reference is not a passthrough.
+	if _, ok := outputs["m.constant"]; ok {
+		t.Fatal("unexpected entry for constant output \"m.constant\"")
+	}
+}